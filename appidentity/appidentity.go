@@ -0,0 +1,261 @@
+// Package appidentity loads the application identity, .fulmen/app.yaml: the
+// binary name, vendor, environment variable prefix and config name an
+// application derives its paths, variables and telemetry from.
+//
+// See: docs/standards/library/modules/app-identity.md
+package appidentity
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fulmenhq/crucible"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaPath locates the app identity schema for crucible.ValidateSchemaData.
+const SchemaPath = "config/repository/app-identity/v1.0.0/app-identity.schema.json"
+
+// FileName is where an identity lives, relative to the repository root.
+const FileName = ".fulmen/app.yaml"
+
+// EnvPath is the environment variable naming an identity file, overriding
+// discovery.
+const EnvPath = "FULMEN_APP_IDENTITY_PATH"
+
+// MaxDepth bounds how many directories Discover searches.
+const MaxDepth = 20
+
+// DefaultEnvPrefix is the prefix EnvVar uses when no identity can be loaded.
+const DefaultEnvPrefix = "FULMEN_APP_"
+
+// Identity is an application identity.
+// See: schemas/config/repository/app-identity/v1.0.0/app-identity.schema.json
+type Identity struct {
+	BinaryName  string   `yaml:"binary_name"`
+	Vendor      string   `yaml:"vendor"`
+	EnvPrefix   string   `yaml:"env_prefix"` // Ends with "_", e.g. PERCHERON_
+	ConfigName  string   `yaml:"config_name"`
+	Description string   `yaml:"description"`
+	Metadata    Metadata `yaml:"metadata"`
+
+	Source string `yaml:"-"` // File the identity was loaded from, or "embedded"
+}
+
+// Metadata is the optional identity metadata.
+type Metadata struct {
+	ProjectURL         string              `yaml:"project_url,omitempty"`
+	SupportEmail       string              `yaml:"support_email,omitempty"`
+	License            string              `yaml:"license,omitempty"`
+	RepositoryCategory string              `yaml:"repository_category,omitempty"`
+	RegistryID         string              `yaml:"registry_id,omitempty"`
+	TelemetryNamespace string              `yaml:"telemetry_namespace,omitempty"`
+	Python             *PythonMetadata     `yaml:"python,omitempty"`
+	TypeScript         *TypeScriptMetadata `yaml:"typescript,omitempty"`
+
+	// Extra holds fields the schema does not define, which it allows for
+	// extensibility.
+	Extra map[string]any `yaml:",inline"`
+}
+
+// PythonMetadata is the Python packaging metadata.
+type PythonMetadata struct {
+	DistributionName string          `yaml:"distribution_name,omitempty"`
+	PackageName      string          `yaml:"package_name,omitempty"`
+	ConsoleScripts   []ConsoleScript `yaml:"console_scripts,omitempty"`
+}
+
+// TypeScriptMetadata is the npm packaging metadata.
+type TypeScriptMetadata struct {
+	PackageName    string          `yaml:"package_name,omitempty"`
+	ConsoleScripts []ConsoleScript `yaml:"console_scripts,omitempty"`
+}
+
+// ConsoleScript is a CLI entry point a package installs.
+type ConsoleScript struct {
+	Name       string `yaml:"name"`
+	EntryPoint string `yaml:"entry_point"`
+}
+
+// EnvVar returns the environment variable for name under the identity's
+// prefix: PERCHERON_PORT for PORT.
+func (id *Identity) EnvVar(name string) string {
+	return id.EnvPrefix + name
+}
+
+// TelemetryNamespace returns metadata.telemetry_namespace, defaulting to the
+// binary name.
+func (id *Identity) TelemetryNamespace() string {
+	if id.Metadata.TelemetryNamespace != "" {
+		return id.Metadata.TelemetryNamespace
+	}
+	return id.BinaryName
+}
+
+// ErrNotFound matches the error Discover and Load return when no identity
+// file exists.
+var ErrNotFound = errors.New("app identity not found")
+
+// NotFoundError lists the paths Discover searched.
+type NotFoundError struct {
+	Searched []string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%v (searched %s)", ErrNotFound, strings.Join(e.Searched, ", "))
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// Parse parses a YAML or JSON identity document and validates it against the
+// schema.
+func Parse(data []byte) (*Identity, error) {
+	if err := crucible.ValidateSchemaData(SchemaPath, data); err != nil {
+		return nil, fmt.Errorf("invalid app identity: %w", err)
+	}
+	var doc struct {
+		App      *Identity `yaml:"app"`
+		Metadata Metadata  `yaml:"metadata"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse app identity: %w", err)
+	}
+	doc.App.Metadata = doc.Metadata
+	return doc.App, nil
+}
+
+// LoadFrom loads the identity file at path, bypassing discovery and the
+// cache.
+func LoadFrom(path string) (*Identity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read app identity: %w", err)
+	}
+	id, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	id.Source = path
+	return id, nil
+}
+
+// Discover returns the nearest .fulmen/app.yaml in start or its ancestors,
+// searching at most MaxDepth directories. When there is none the error is a
+// *NotFoundError matching ErrNotFound.
+func Discover(start string) (string, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+	var searched []string
+	for range MaxDepth {
+		path := filepath.Join(dir, FileName)
+		searched = append(searched, path)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return "", &NotFoundError{Searched: searched}
+}
+
+var (
+	mu       sync.Mutex
+	cached   *Identity
+	embedded []byte
+	override *Identity
+)
+
+// Load returns the application identity, loaded once per process and cached.
+//
+// The identity is read from the file EnvPath names when set, else discovered
+// from the working directory, else parsed from the document registered with
+// Embed. Failures are not cached, so a later call tries again.
+//
+// Callers must not modify the returned identity.
+func Load() (*Identity, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if override != nil {
+		return override, nil
+	}
+	if cached != nil {
+		return cached, nil
+	}
+	id, err := load()
+	if err != nil {
+		return nil, err
+	}
+	cached = id
+	return id, nil
+}
+
+func load() (*Identity, error) {
+	if path := os.Getenv(EnvPath); path != "" {
+		return LoadFrom(path)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	path, err := Discover(wd)
+	if err == nil {
+		return LoadFrom(path)
+	}
+	if !errors.Is(err, ErrNotFound) || embedded == nil {
+		return nil, err
+	}
+	id, perr := Parse(embedded)
+	if perr != nil {
+		return nil, fmt.Errorf("embedded: %w", perr)
+	}
+	id.Source = "embedded"
+	return id, nil
+}
+
+// Embed registers the identity document built into the binary, which Load
+// falls back to when discovery finds no file, so distributed binaries know
+// their identity outside the repository. Call it before the first Load,
+// typically from an init function with a //go:embed copy of .fulmen/app.yaml.
+func Embed(data []byte) {
+	mu.Lock()
+	defer mu.Unlock()
+	embedded = data
+}
+
+// Override makes Load return id, without touching the cache, until the
+// returned function restores the previous state. It is intended for tests:
+//
+//	t.Cleanup(appidentity.Override(&appidentity.Identity{BinaryName: "testapp", EnvPrefix: "TESTAPP_"}))
+func Override(id *Identity) (restore func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	prev := override
+	override = id
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		override = prev
+	}
+}
+
+// EnvVar returns the environment variable for name under the loaded
+// identity's prefix, such as PERCHERON_PORT for PORT, or under
+// DefaultEnvPrefix when no identity can be loaded.
+func EnvVar(name string) string {
+	id, err := Load()
+	if err != nil {
+		return DefaultEnvPrefix + name
+	}
+	return id.EnvVar(name)
+}
@@ -0,0 +1,242 @@
+package appidentity
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fulmenhq/crucible"
+	"gopkg.in/yaml.v3"
+)
+
+const fixtureDir = "repository/app-identity/"
+
+func TestParitySnapshot(t *testing.T) {
+	data, err := crucible.GetConfig(fixtureDir + "parity-snapshot.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snapshot struct {
+		TestCases struct {
+			Valid map[string]struct {
+				InputFile      string         `json:"input_file"`
+				ExpectedOutput map[string]any `json:"expected_output"`
+			} `json:"valid"`
+			Invalid map[string]struct {
+				InputFile     string `json:"input_file"`
+				ExpectedError struct {
+					Type  string `json:"type"`
+					Field string `json:"field"`
+				} `json:"expected_error"`
+			} `json:"invalid"`
+		} `json:"test_cases"`
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range snapshot.TestCases.Valid {
+		t.Run(name, func(t *testing.T) {
+			id, err := Parse(fixture(t, tc.InputFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := toMap(t, id); !reflect.DeepEqual(got, tc.ExpectedOutput) {
+				t.Errorf("got %v\nwant %v", got, tc.ExpectedOutput)
+			}
+		})
+	}
+
+	for name, tc := range snapshot.TestCases.Invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(fixture(t, tc.InputFile))
+			var verr *crucible.SchemaValidationError
+			switch {
+			case err == nil:
+				t.Fatal("expected an error")
+			case tc.ExpectedError.Type == "ParseError":
+				if errors.As(err, &verr) {
+					t.Errorf("expected a parse error, got %v", err)
+				}
+			case !errors.As(err, &verr):
+				t.Errorf("expected a validation error, got %v", err)
+			default:
+				// A missing field is reported on its parent.
+				field := "/" + strings.ReplaceAll(tc.ExpectedError.Field, ".", "/")
+				for _, d := range verr.Diagnostics {
+					if strings.HasPrefix(field, d.Pointer) && (d.Pointer == field || strings.Contains(d.Message, filepath.Base(field))) {
+						return
+					}
+				}
+				t.Errorf("no diagnostic for %s: %v", tc.ExpectedError.Field, err)
+			}
+		})
+	}
+}
+
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := crucible.GetConfig(fixtureDir + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// toMap renders id in the parity snapshot's shape.
+func toMap(t *testing.T, id *Identity) map[string]any {
+	t.Helper()
+	data, err := yaml.Marshal(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]any
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = json.Marshal(m)
+	m = nil
+	json.Unmarshal(data, &m)
+	return m
+}
+
+func TestIdentityHelpers(t *testing.T) {
+	id, err := Parse(fixture(t, "fixtures/valid/complete.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := id.EnvVar("PORT"); got != "PERCHERON_PORT" {
+		t.Errorf("EnvVar = %q", got)
+	}
+	if id.Metadata.Python.ConsoleScripts[0].EntryPoint != "percheron.cli:main" || id.Metadata.Extra["custom_field"] != "allowed for extensibility" {
+		t.Errorf("metadata %+v", id.Metadata)
+	}
+	if got := id.TelemetryNamespace(); got != "percheron" {
+		t.Errorf("TelemetryNamespace = %q", got)
+	}
+	if got := (&Identity{BinaryName: "myapp"}).TelemetryNamespace(); got != "myapp" {
+		t.Errorf("default TelemetryNamespace = %q", got)
+	}
+}
+
+// writeIdentity writes the fixture name as dir/.fulmen/app.yaml.
+func writeIdentity(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, FileName)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, fixture(t, name), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// reset clears the cache and embedded identity for a test.
+func reset(t *testing.T) {
+	t.Helper()
+	mu.Lock()
+	cached, embedded = nil, nil
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		cached, embedded = nil, nil
+		mu.Unlock()
+	})
+}
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	want := writeIdentity(t, root, "fixtures/valid/minimal.yaml")
+	nested := filepath.Join(root, "cmd", "myapp")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Discover(nested); err != nil || got != want {
+		t.Errorf("Discover = %q, %v, want %q", got, err, want)
+	}
+
+	_, err := Discover(t.TempDir())
+	var nf *NotFoundError
+	if !errors.As(err, &nf) || !errors.Is(err, ErrNotFound) || len(nf.Searched) == 0 || !strings.HasSuffix(nf.Searched[0], FileName) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	reset(t)
+	root := t.TempDir()
+	path := writeIdentity(t, root, "fixtures/valid/complete.yaml")
+	nested := filepath.Join(root, "src")
+	if err := os.Mkdir(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(nested)
+	t.Setenv(EnvPath, "")
+
+	id, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.BinaryName != "percheron" || id.Source != path || EnvVar("PORT") != "PERCHERON_PORT" {
+		t.Errorf("identity %+v", id)
+	}
+
+	// Cached: the file is not read again.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := Load(); err != nil || again != id {
+		t.Errorf("second Load = %p, %v, want the cached %p", again, err, id)
+	}
+
+	restore := Override(&Identity{BinaryName: "testapp", EnvPrefix: "TESTAPP_"})
+	if got := EnvVar("PORT"); got != "TESTAPP_PORT" {
+		t.Errorf("overridden EnvVar = %q", got)
+	}
+	restore()
+	if again, _ := Load(); again != id {
+		t.Error("Override changed the cache")
+	}
+}
+
+func TestLoadSources(t *testing.T) {
+	reset(t)
+	t.Chdir(t.TempDir())
+	t.Setenv(EnvPath, "")
+
+	if _, err := Load(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if got := EnvVar("PORT"); got != "FULMEN_APP_PORT" {
+		t.Errorf("fallback EnvVar = %q", got)
+	}
+
+	t.Setenv(EnvPath, writeIdentity(t, t.TempDir(), "fixtures/invalid/invalid-env-prefix.yaml"))
+	var verr *crucible.SchemaValidationError
+	if _, err := Load(); !errors.As(err, &verr) {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+
+	t.Setenv(EnvPath, "")
+	Embed(fixture(t, "fixtures/valid/typescript-package.yaml"))
+	id, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.BinaryName != "tsfulmen" || id.Source != "embedded" || id.Metadata.TypeScript.PackageName != "@fulmenhq/tsfulmen" {
+		t.Errorf("embedded identity %+v", id)
+	}
+
+	// The embedded identity is a fallback: a file on disk wins.
+	reset(t)
+	Embed(fixture(t, "fixtures/valid/typescript-package.yaml"))
+	t.Setenv(EnvPath, writeIdentity(t, t.TempDir(), "fixtures/valid/monorepo-worker.yaml"))
+	if id, err := Load(); err != nil || id.BinaryName != "myproject-worker" {
+		t.Errorf("Load = %+v, %v", id, err)
+	}
+}
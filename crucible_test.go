@@ -72,6 +72,22 @@ func TestGetTerminalConfig(t *testing.T) {
 	t.Logf("iTerm2 emoji width: %d", config.Overrides.EmojiWidth)
 }
 
+func TestDetectTerminal(t *testing.T) {
+	t.Setenv("TERM_PROGRAM", "ghostty")
+	config, err := DetectTerminal()
+	if err != nil {
+		t.Fatalf("Failed to detect ghostty: %v", err)
+	}
+	if config.Name != "Ghostty" || !config.Capabilities.Color {
+		t.Errorf("Expected Ghostty with color, got %+v", config)
+	}
+
+	t.Setenv("TERM_PROGRAM", "unknown-terminal")
+	if _, err := DetectTerminal(); err == nil {
+		t.Error("Expected an error for an unknown terminal")
+	}
+}
+
 func TestPathfinderSchemas(t *testing.T) {
 	v1, err := SchemaRegistry.Pathfinder().V1_0_0()
 	if err != nil {
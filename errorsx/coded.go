@@ -0,0 +1,60 @@
+package errorsx
+
+// Coded is the core of the fulencode, fulpack, pathfinder and
+// server/management error types: a typed code, a message, details and the
+// underlying cause. Each package embeds it, adding its own fields, exit
+// codes and JSON form; Wrap turns any of them into an envelope that keeps
+// the exit code.
+type Coded[C ~string] struct {
+	Code    C
+	Message string
+	Details map[string]any // Additional details
+	Err     error          // Underlying cause, if any
+}
+
+// codedError is implemented by every type embedding a Coded[C].
+type codedError[C ~string] interface {
+	coded() *Coded[C]
+}
+
+func (c *Coded[C]) coded() *Coded[C] {
+	return c
+}
+
+func (c *Coded[C]) Unwrap() error {
+	return c.Err
+}
+
+// Is reports whether target embeds a Coded with the same code type and
+// code, so a package's sentinels match any of its errors with their code.
+func (c *Coded[C]) Is(target error) bool {
+	t, ok := target.(codedError[C])
+	return ok && t.coded().Code == c.Code
+}
+
+// SetDetail sets a detail field.
+func (c *Coded[C]) SetDetail(key string, value any) {
+	if c.Details == nil {
+		c.Details = map[string]any{}
+	}
+	c.Details[key] = value
+}
+
+// Text formats the error as "prefix: CODE: message note: cause", leaving
+// out empty parts. Embedding types return it from their Error method.
+func (c *Coded[C]) Text(prefix, note string) string {
+	msg := string(c.Code)
+	if prefix != "" {
+		msg = prefix + ": " + msg
+	}
+	if c.Message != "" {
+		msg += ": " + c.Message
+	}
+	if note != "" {
+		msg += " " + note
+	}
+	if c.Err != nil {
+		msg += ": " + c.Err.Error()
+	}
+	return msg
+}
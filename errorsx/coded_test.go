@@ -0,0 +1,69 @@
+package errorsx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/fulmenhq/crucible/foundry"
+)
+
+type testCode string
+
+// testError is a package error type built on Coded, as pathfinder's is.
+type testError struct {
+	Coded[testCode]
+	Path string
+}
+
+var errTestDenied = &testError{Coded: Coded[testCode]{Code: "PERMISSION_DENIED"}}
+
+func (e *testError) Error() string {
+	return e.Text("test", fmt.Sprintf("(path %q)", e.Path))
+}
+
+func (e *testError) ExitCode() int {
+	return foundry.ExitPermissionDenied
+}
+
+func (e *testError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"code": e.Code, "message": e.Message, "path": e.Path})
+}
+
+type otherError struct {
+	Coded[string]
+}
+
+func (e *otherError) Error() string {
+	return e.Text("", "")
+}
+
+func TestCoded(t *testing.T) {
+	e := &testError{Coded: Coded[testCode]{Code: "PERMISSION_DENIED", Message: "cannot read", Err: fs.ErrPermission}, Path: "/etc/app.yaml"}
+	e.SetDetail("attempts", 2)
+	wrapped := fmt.Errorf("loading: %w", e)
+
+	if !errors.Is(wrapped, errTestDenied) {
+		t.Error("error does not match the sentinel with its code")
+	}
+	if errors.Is(wrapped, &testError{Coded: Coded[testCode]{Code: "NOT_FOUND"}}) {
+		t.Error("error matches a sentinel with another code")
+	}
+	if errors.Is(wrapped, &otherError{Coded[string]{Code: "PERMISSION_DENIED"}}) {
+		t.Error("error matches another package's code type")
+	}
+	if !errors.Is(wrapped, fs.ErrPermission) {
+		t.Error("error does not unwrap to its cause")
+	}
+	if e.Details["attempts"] != 2 {
+		t.Errorf("details = %v", e.Details)
+	}
+	if got, want := e.Error(), `test: PERMISSION_DENIED: cannot read (path "/etc/app.yaml"): permission denied`; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if got := (&otherError{Coded[string]{Code: "X"}}).Error(); got != "X" {
+		t.Errorf("Error() = %q, want X", got)
+	}
+}
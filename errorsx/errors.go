@@ -0,0 +1,278 @@
+// Package errorsx implements the Fulmen error envelope: the pathfinder
+// error response extended with severity, correlation and exit code fields.
+//
+// See: docs/standards/library/modules/error-handling-propagation.md
+package errorsx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/fulmenhq/crucible"
+	"github.com/fulmenhq/crucible/foundry"
+	"github.com/fulmenhq/crucible/observability/metrics"
+)
+
+// SchemaPath locates the error envelope schema for crucible.ValidateSchemaData.
+const SchemaPath = "error-handling/v1.0.0/error-response.schema.json"
+
+// Severity is an assessment severity name.
+// See: schemas/assessment/v1.0.0/severity-definitions.schema.json
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// Level returns the numeric severity level (info=0 … critical=4), or -1 for
+// an unknown severity.
+func (s Severity) Level() int {
+	switch s {
+	case SeverityInfo:
+		return 0
+	case SeverityLow:
+		return 1
+	case SeverityMedium:
+		return 2
+	case SeverityHigh:
+		return 3
+	case SeverityCritical:
+		return 4
+	default:
+		return -1
+	}
+}
+
+// Error is the Go form of the Fulmen error envelope.
+// See: schemas/error-handling/v1.0.0/error-response.schema.json
+type Error struct {
+	Code          string
+	Message       string
+	Details       map[string]any
+	Path          string
+	Timestamp     time.Time
+	Severity      Severity
+	CorrelationID string
+	TraceID       string
+	Context       map[string]any // Non-sensitive context, accumulated across wraps
+	Err           error          // Wrapped error, serialized as original
+
+	exitCode int
+}
+
+// Option configures an Error built by Wrap.
+type Option func(*Error)
+
+// WithMessage replaces the message, which defaults to the wrapped error's.
+func WithMessage(msg string) Option {
+	return func(e *Error) { e.Message = msg }
+}
+
+// WithSeverity sets the severity.
+func WithSeverity(s Severity) Option {
+	return func(e *Error) { e.Severity = s }
+}
+
+// WithExitCode sets the exit code. Codes missing from the Foundry exit code
+// catalog are ignored.
+func WithExitCode(code int) Option {
+	return func(e *Error) {
+		if foundry.GetExitCodeInfo(code) != nil {
+			e.exitCode = code
+		}
+	}
+}
+
+// WithCorrelationID sets the correlation identifier.
+func WithCorrelationID(id string) Option {
+	return func(e *Error) { e.CorrelationID = id }
+}
+
+// WithTraceID sets the tracing identifier.
+func WithTraceID(id string) Option {
+	return func(e *Error) { e.TraceID = id }
+}
+
+// WithPath sets the path that caused the error.
+func WithPath(path string) Option {
+	return func(e *Error) { e.Path = path }
+}
+
+// WithDetail sets a detail field.
+func WithDetail(key string, value any) Option {
+	return func(e *Error) {
+		if e.Details == nil {
+			e.Details = map[string]any{}
+		}
+		e.Details[key] = value
+	}
+}
+
+// WithContext sets a context field. Values other than strings, numbers,
+// booleans and string slices are stored as JSON text.
+func WithContext(key string, value any) Option {
+	return func(e *Error) {
+		if e.Context == nil {
+			e.Context = map[string]any{}
+		}
+		e.Context[key] = contextValue(value)
+	}
+}
+
+// WithTimestamp sets when the error occurred, which defaults to the time of
+// the Wrap call.
+func WithTimestamp(t time.Time) Option {
+	return func(e *Error) { e.Timestamp = t }
+}
+
+// Wrap builds an envelope with code around err; a nil err returns nil.
+//
+// When err already contains an *Error, the new envelope starts from its
+// context, severity, correlation and trace identifiers and exit code, so
+// context accumulates as errors propagate. Otherwise severity defaults to
+// medium and the exit code to that of an ExitCode() int method on err, or
+// foundry.ExitFailure. Each call is counted in error_handling_wraps_total
+// and timed in error_handling_wrap_ms on the default metrics registry.
+func Wrap(err error, code string, opts ...Option) *Error {
+	if err == nil {
+		return nil
+	}
+	start := time.Now()
+	defer observe(start)
+
+	e := &Error{Code: code, Message: err.Error(), Timestamp: start, Err: err, Severity: SeverityMedium, exitCode: foundry.ExitFailure}
+	var coder interface{ ExitCode() int }
+	if errors.As(err, &coder) {
+		e.exitCode = coder.ExitCode()
+	}
+	var inner *Error
+	if errors.As(err, &inner) {
+		e.Severity = inner.Severity
+		e.CorrelationID = inner.CorrelationID
+		e.TraceID = inner.TraceID
+		e.Context = maps.Clone(inner.Context)
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// observe records a Wrap call that began at start.
+func observe(start time.Time) {
+	reg := metrics.Default()
+	if c, err := reg.Counter(metrics.ErrorHandlingWrapsTotal); err == nil {
+		c.Inc()
+	}
+	if h, err := reg.Histogram(metrics.ErrorHandlingWrapMs); err == nil {
+		h.ObserveDuration(time.Since(start))
+	}
+}
+
+func (e *Error) Error() string {
+	msg := e.Code
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Err != nil && e.Err.Error() != e.Message {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error with the same code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// ExitCode returns the Foundry exit code for the error.
+func (e *Error) ExitCode() int {
+	return e.exitCode
+}
+
+// Validate checks the JSON form of the error against the envelope schema.
+func (e *Error) Validate() error {
+	return crucible.ValidateSchemaValue(SchemaPath, e)
+}
+
+// MarshalJSON renders the error envelope. The wrapped error is embedded as
+// original: as its own JSON object when it marshals to one (so wrapped
+// envelopes nest), otherwise as its message.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	type envelope struct {
+		Code          string         `json:"code"`
+		Message       string         `json:"message"`
+		Details       map[string]any `json:"details,omitempty"`
+		Path          string         `json:"path,omitempty"`
+		Timestamp     string         `json:"timestamp,omitempty"`
+		Severity      Severity       `json:"severity,omitempty"`
+		SeverityLevel *int           `json:"severity_level,omitempty"`
+		CorrelationID string         `json:"correlation_id,omitempty"`
+		TraceID       string         `json:"trace_id,omitempty"`
+		ExitCode      int            `json:"exit_code"`
+		Context       map[string]any `json:"context,omitempty"`
+		Original      any            `json:"original,omitempty"`
+	}
+
+	env := envelope{
+		Code:          e.Code,
+		Message:       e.Message,
+		Details:       e.Details,
+		Path:          e.Path,
+		Severity:      e.Severity,
+		CorrelationID: e.CorrelationID,
+		TraceID:       e.TraceID,
+		ExitCode:      e.exitCode,
+		Context:       e.Context,
+	}
+	if env.Message == "" {
+		env.Message = e.Error()
+	}
+	if !e.Timestamp.IsZero() {
+		env.Timestamp = e.Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+	if level := e.Severity.Level(); level >= 0 {
+		env.SeverityLevel = &level
+	}
+	if e.Err != nil {
+		env.Original = original(e.Err)
+	}
+	return json.Marshal(env)
+}
+
+// original serializes a wrapped error.
+func original(err error) any {
+	if m, ok := err.(json.Marshaler); ok {
+		if data, jerr := m.MarshalJSON(); jerr == nil && bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+			return json.RawMessage(data)
+		}
+	}
+	return err.Error()
+}
+
+// contextValue coerces v to a type the envelope schema allows in context.
+func contextValue(v any) any {
+	switch x := v.(type) {
+	case string, bool, []string,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return x
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
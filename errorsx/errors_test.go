@@ -0,0 +1,129 @@
+package errorsx
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/fulmenhq/crucible/foundry"
+	"github.com/fulmenhq/crucible/observability/metrics"
+)
+
+// envelope validates e against the schema and decodes its JSON form.
+func envelope(t *testing.T, e *Error) map[string]any {
+	t.Helper()
+	if err := e.Validate(); err != nil {
+		t.Fatalf("envelope does not match schema: %v", err)
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestWrap(t *testing.T) {
+	if Wrap(nil, "NOPE") != nil {
+		t.Error("Wrap(nil) should return nil")
+	}
+
+	ts := time.Date(2025, 11, 6, 12, 34, 56, 0, time.UTC)
+	base := &testError{Coded: Coded[testCode]{Code: "PERMISSION_DENIED", Message: "permission denied"}, Path: "/etc/app.yaml"}
+	e := Wrap(base, "CONFIG_UNREADABLE",
+		WithMessage("config load failed"),
+		WithTimestamp(ts),
+		WithContext("attempts", 3),
+		WithContext("sources", []string{"env", "file"}),
+		WithContext("limits", map[string]int{"max": 2}),
+		WithDetail("profile", "prod"),
+		WithExitCode(999), // Not in the Foundry catalog
+	)
+	if e.ExitCode() != foundry.ExitPermissionDenied {
+		t.Errorf("exit code %d, want the wrapped error's %d", e.ExitCode(), foundry.ExitPermissionDenied)
+	}
+	if !errors.Is(e, errTestDenied) {
+		t.Error("envelope does not unwrap to the wrapped error")
+	}
+	got := envelope(t, e)
+	want := map[string]any{
+		"code":           "CONFIG_UNREADABLE",
+		"message":        "config load failed",
+		"timestamp":      "2025-11-06T12:34:56Z",
+		"severity":       "medium",
+		"severity_level": 2.0,
+		"exit_code":      float64(foundry.ExitPermissionDenied),
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %v, want %v", k, got[k], v)
+		}
+	}
+	if ctx := got["context"].(map[string]any); ctx["limits"] != `{"max":2}` || ctx["attempts"] != 3.0 {
+		t.Errorf("context = %v", ctx)
+	}
+	if orig, ok := got["original"].(map[string]any); !ok || orig["code"] != "PERMISSION_DENIED" || orig["path"] != "/etc/app.yaml" {
+		t.Errorf("original = %v", got["original"])
+	}
+}
+
+func TestWrapChain(t *testing.T) {
+	inner := Wrap(fs.ErrNotExist, "READ_FAILED",
+		WithSeverity(SeverityHigh),
+		WithCorrelationID("req-1"),
+		WithTraceID("4bf92f3577b34da6a3ce929d0e0e4736"),
+		WithExitCode(foundry.ExitFileNotFound),
+		WithContext("path", "/app.yaml"),
+	)
+	outer := Wrap(inner, "CONFIG_INVALID", WithContext("stage", "load"), WithContext("path", "/etc/app.yaml"))
+
+	if outer.Severity != SeverityHigh || outer.CorrelationID != "req-1" || outer.TraceID == "" {
+		t.Errorf("outer did not inherit telemetry: %+v", outer)
+	}
+	if outer.ExitCode() != foundry.ExitFileNotFound {
+		t.Errorf("exit code %d, want %d", outer.ExitCode(), foundry.ExitFileNotFound)
+	}
+	if outer.Context["path"] != "/etc/app.yaml" || outer.Context["stage"] != "load" || inner.Context["path"] != "/app.yaml" {
+		t.Errorf("context chain: outer %v, inner %v", outer.Context, inner.Context)
+	}
+	if !errors.Is(outer, &Error{Code: "READ_FAILED"}) || !errors.Is(outer, fs.ErrNotExist) {
+		t.Error("outer does not unwrap through the chain")
+	}
+	if got := outer.Error(); got != "CONFIG_INVALID: READ_FAILED: file does not exist" {
+		t.Errorf("Error() = %q", got)
+	}
+
+	got := envelope(t, outer)
+	orig, ok := got["original"].(map[string]any)
+	if !ok || orig["code"] != "READ_FAILED" || orig["original"] != "file does not exist" {
+		t.Errorf("original = %v", got["original"])
+	}
+}
+
+func TestWrapMetrics(t *testing.T) {
+	old := metrics.Default()
+	reg := metrics.NewRegistry()
+	metrics.SetDefault(reg)
+	t.Cleanup(func() { metrics.SetDefault(old) })
+
+	Wrap(errors.New("boom"), "A")
+	Wrap(errors.New("boom"), "B")
+
+	counts := map[metrics.Name]any{}
+	for _, ev := range reg.Export() {
+		switch v := ev.Value.(type) {
+		case float64:
+			counts[ev.Name] = v
+		case *metrics.HistogramValue:
+			counts[ev.Name] = v.Count
+		}
+	}
+	if counts[metrics.ErrorHandlingWrapsTotal] != 2.0 || counts[metrics.ErrorHandlingWrapMs] != uint64(2) {
+		t.Errorf("metrics = %v", counts)
+	}
+}
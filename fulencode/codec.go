@@ -0,0 +1,483 @@
+package fulencode
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// EncodeOptions options for the encode operation.
+// See: schemas/library/fulencode/v1.0.0/encode-options.schema.json
+//
+// All fields are optional.
+type EncodeOptions struct {
+	Padding        *bool  `json:"padding,omitempty"`          // Enable/disable padding when supported (Base64/Base32)
+	Case           string `json:"case,omitempty"`             // Hex output case (upper, lower)
+	LineLength     *int64 `json:"line_length,omitempty"`      // Optional line wrap length for text output
+	LineEnding     string `json:"line_ending,omitempty"`      // Line ending to use when line wrapping
+	MaxEncodedSize *int64 `json:"max_encoded_size,omitempty"` // Maximum encoded output size in bytes
+}
+
+// DecodeOptions options for the decode operation.
+// See: schemas/library/fulencode/v1.0.0/decode-options.schema.json
+//
+// All fields are optional.
+type DecodeOptions struct {
+	MaxDecodedSize    *int64           `json:"max_decoded_size,omitempty"`    // Maximum decoded output size in bytes
+	MaxExpansionRatio *float64         `json:"max_expansion_ratio,omitempty"` // Maximum decode expansion ratio (encoding bomb protection)
+	OnError           ErrorMode        `json:"on_error,omitempty"`            // Error handling mode
+	FallbackFormats   []EncodingFormat `json:"fallback_formats,omitempty"`    // Fallback formats to attempt when on_error is fallback
+	IgnoreWhitespace  *bool            `json:"ignore_whitespace,omitempty"`   // Ignore ASCII whitespace in encoded input when supported
+	ValidatePadding   *bool            `json:"validate_padding,omitempty"`    // Require correct padding when supported
+}
+
+// EncodingResult result of the encode operation.
+// See: schemas/library/fulencode/v1.0.0/encoding-result.schema.json
+type EncodingResult struct {
+	Data       string         `json:"data"`        // Encoded output
+	Format     EncodingFormat `json:"format"`      // Encoding format used
+	InputSize  int64          `json:"input_size"`  // Input size in bytes
+	OutputSize int64          `json:"output_size"` // Output size in bytes
+	Warnings   []string       `json:"warnings"`    // Non-fatal warnings
+}
+
+// DecodingResult result of the decode operation.
+// See: schemas/library/fulencode/v1.0.0/decoding-result.schema.json
+type DecodingResult struct {
+	Data               []byte         `json:"data"`                // Decoded bytes (UTF-8 for text formats)
+	Format             EncodingFormat `json:"format"`              // Encoding format the input was decoded from
+	InputSize          int64          `json:"input_size"`          // Input size in bytes
+	OutputSize         int64          `json:"output_size"`         // Output size in bytes
+	Warnings           []string       `json:"warnings"`            // Non-fatal warnings
+	CorrectionsApplied int64          `json:"corrections_applied"` // Count of corrections (0 in strict mode)
+}
+
+// MarshalJSON renders Data as an array of byte values as required by the schema.
+func (r *DecodingResult) MarshalJSON() ([]byte, error) {
+	type alias DecodingResult
+	data := make([]int, len(r.Data))
+	for i, b := range r.Data {
+		data[i] = int(b)
+	}
+	return json.Marshal(struct {
+		*alias
+		Data []int `json:"data"`
+	}{alias: (*alias)(r), Data: data})
+}
+
+// Codec performs fulencode operations under a fixed configuration. Limits and
+// defaults from the configuration apply to every call unless the per-call
+// options override them.
+type Codec struct {
+	cfg Config
+}
+
+// NewCodec creates a Codec from cfg. A nil cfg uses DefaultConfig.
+func NewCodec(cfg *Config) (*Codec, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &Codec{cfg: *cfg}, nil
+}
+
+// Config returns a copy of the codec configuration.
+func (c *Codec) Config() Config {
+	return c.cfg
+}
+
+// fail reports security violations before handing the error back to the caller.
+func fail(err *Error) error {
+	ReportViolation(err)
+	return err
+}
+
+func sizeLimitError(operation string, actual, limit int64) *Error {
+	err := NewError(CodeBufferOverflow, operation,
+		fmt.Sprintf("size %d exceeds limit %d", actual, limit))
+	err.Details = map[string]any{"actual_size": actual, "max_size": limit}
+	return err
+}
+
+// Encode encodes binary data using a binary-to-text format.
+func (c *Codec) Encode(data []byte, format EncodingFormat, opts *EncodeOptions) (*EncodingResult, error) {
+	if opts == nil {
+		opts = &EncodeOptions{}
+	}
+	maxSize := c.cfg.Limits.MaxEncodedSize
+	if opts.MaxEncodedSize != nil {
+		maxSize = *opts.MaxEncodedSize
+	}
+
+	var warnings []string
+	padding := func(def bool) bool {
+		if opts.Padding != nil {
+			return *opts.Padding
+		}
+		return def
+	}
+
+	var enc interface {
+		EncodedLen(int) int
+		EncodeToString([]byte) string
+	}
+	switch format {
+	case EncodingFormatBase64:
+		enc = base64.StdEncoding
+		if !padding(true) {
+			enc = base64.RawStdEncoding
+		}
+	case EncodingFormatBase64url:
+		enc = base64.RawURLEncoding
+		if padding(false) {
+			enc = base64.URLEncoding
+		}
+	case EncodingFormatBase64Raw:
+		enc = base64.RawStdEncoding
+		if padding(false) {
+			warnings = append(warnings, "padding is not supported by base64_raw and was ignored")
+		}
+	case EncodingFormatBase32:
+		enc = base32.StdEncoding
+		if !padding(true) {
+			enc = base32.StdEncoding.WithPadding(base32.NoPadding)
+		}
+	case EncodingFormatBase32hex:
+		enc = base32.HexEncoding
+		if !padding(true) {
+			enc = base32.HexEncoding.WithPadding(base32.NoPadding)
+		}
+	case EncodingFormatHex:
+		enc = hexEncoding{upper: opts.Case == "upper"}
+	default:
+		if err := ValidateEncodingFormat(format); err != nil {
+			return nil, NewError(CodeUnsupportedFormat, "encode", err.Error())
+		}
+		return nil, NewError(CodeUnsupportedFormat, "encode",
+			fmt.Sprintf("%s is a text encoding; use Decode to convert it to UTF-8", format)).
+			WithFormats("", format)
+	}
+
+	outLen := int64(enc.EncodedLen(len(data)))
+	lineLen := int64(0)
+	if opts.LineLength != nil {
+		if *opts.LineLength < 1 {
+			return nil, NewError(CodeInvalidOptions, "encode", "line_length must be at least 1")
+		}
+		lineLen = *opts.LineLength
+	}
+	ending := opts.LineEnding
+	if ending == "" {
+		ending = "\n"
+	}
+	if lineLen > 0 && outLen > 0 {
+		outLen += (outLen - 1) / lineLen * int64(len(ending))
+	}
+	if outLen > maxSize {
+		return nil, fail(sizeLimitError("encode", outLen, maxSize).WithFormats("", format))
+	}
+
+	out := enc.EncodeToString(data)
+	if lineLen > 0 {
+		out = wrapLines(out, int(lineLen), ending)
+	}
+
+	return &EncodingResult{
+		Data:       out,
+		Format:     format,
+		InputSize:  int64(len(data)),
+		OutputSize: int64(len(out)),
+		Warnings:   nonNil(warnings),
+	}, nil
+}
+
+// Decode decodes data from format. Binary-to-text formats yield raw bytes;
+// text formats are validated and converted to UTF-8.
+func (c *Codec) Decode(data []byte, format EncodingFormat, opts *DecodeOptions) (*DecodingResult, error) {
+	if opts == nil {
+		opts = &DecodeOptions{}
+	}
+	mode := opts.OnError
+	if mode == "" {
+		mode = c.cfg.Defaults.OnError
+	}
+
+	res, err := c.decode(data, format, opts, mode)
+	if err == nil {
+		return res, nil
+	}
+	if mode == ErrorModeFallback {
+		for _, fb := range opts.FallbackFormats {
+			if fb == format {
+				continue
+			}
+			if res, fbErr := c.decode(data, fb, opts, ErrorModeStrict); fbErr == nil {
+				res.Warnings = append(res.Warnings, fmt.Sprintf("decoded using fallback format %s: %v", fb, err))
+				res.CorrectionsApplied++
+				return res, nil
+			}
+		}
+	}
+	return nil, fail(err)
+}
+
+// decode makes one decoding attempt. It does not report violations: with
+// fallback formats, only the error Decode finally returns counts.
+func (c *Codec) decode(data []byte, format EncodingFormat, opts *DecodeOptions, mode ErrorMode) (*DecodingResult, *Error) {
+	maxSize := c.cfg.Limits.MaxDecodedSize
+	if opts.MaxDecodedSize != nil {
+		maxSize = *opts.MaxDecodedSize
+	}
+	maxRatio := c.cfg.Limits.MaxExpansionRatio
+	if opts.MaxExpansionRatio != nil {
+		maxRatio = *opts.MaxExpansionRatio
+	}
+
+	input := data
+	if opts.IgnoreWhitespace != nil && *opts.IgnoreWhitespace && isBinaryToText(format) {
+		input = stripASCIIWhitespace(input)
+	}
+
+	// Reject oversized input before allocating the output buffer.
+	if est := estimateDecodedLen(format, len(input)); est > maxSize {
+		return nil, sizeLimitError("decode", est, maxSize).WithFormats(format, "")
+	}
+
+	var out []byte
+	var corrections int
+	var err *Error
+	switch format {
+	case EncodingFormatBase64, EncodingFormatBase64url, EncodingFormatBase64Raw:
+		out, err = decodeBase64(input, format, opts.ValidatePadding == nil || *opts.ValidatePadding)
+	case EncodingFormatBase32, EncodingFormatBase32hex:
+		out, err = decodeBase32(input, format)
+	case EncodingFormatHex:
+		out, err = decodeHex(input)
+	case EncodingFormatUtf8:
+		var issue *utfIssue
+		if out, corrections, issue = decodeUTF8(input, mode); issue != nil {
+			err = NewError(CodeInvalidUTF8, "decode", "invalid UTF-8 sequence").
+				WithSubcode(issue.subcode).WithOffset(int64(issue.offset))
+		}
+	case EncodingFormatUtf16le, EncodingFormatUtf16be:
+		var issue *utfIssue
+		if out, corrections, issue = decodeUTF16(input, format == EncodingFormatUtf16be, mode); issue != nil {
+			err = NewError(CodeInvalidUTF16, "decode", "invalid UTF-16 sequence").
+				WithSubcode(issue.subcode).WithOffset(int64(issue.offset))
+		}
+	case EncodingFormatIso88591, EncodingFormatCp1252, EncodingFormatAscii:
+		var issue *utfIssue
+		if out, corrections, issue = decodeSingleByte(input, format, mode); issue != nil {
+			err = NewError(CodeInvalidEncoding, "decode", fmt.Sprintf("byte not valid in %s", format)).
+				WithSubcode(issue.subcode).WithOffset(int64(issue.offset))
+		}
+	default:
+		msg := fmt.Sprintf("unsupported format: %s", format)
+		if verr := ValidateEncodingFormat(format); verr != nil {
+			msg = verr.Error()
+		}
+		return nil, NewError(CodeUnsupportedFormat, "decode", msg)
+	}
+	if err != nil {
+		if err.Subcode == SubcodeInvalidCharacter && err.ByteOffset != nil && int(*err.ByteOffset) < len(input) {
+			err.Details = map[string]any{"invalid_bytes": []int{int(input[*err.ByteOffset])}}
+		}
+		return nil, err.WithFormats(format, "")
+	}
+
+	if int64(len(out)) > maxSize {
+		return nil, sizeLimitError("decode", int64(len(out)), maxSize).WithFormats(format, "")
+	}
+	if len(data) > 0 {
+		if ratio := float64(len(out)) / float64(len(data)); ratio > maxRatio {
+			e := NewError(CodeEncodingBomb, "decode",
+				fmt.Sprintf("expansion ratio %.2f exceeds limit %.2f", ratio, maxRatio)).WithFormats(format, "")
+			e.Details = map[string]any{"expansion_ratio": ratio, "max_ratio": maxRatio}
+			return nil, e
+		}
+	}
+
+	var warnings []string
+	if corrections > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d invalid sequences handled in %s mode", corrections, mode))
+	}
+	return &DecodingResult{
+		Data:               out,
+		Format:             format,
+		InputSize:          int64(len(data)),
+		OutputSize:         int64(len(out)),
+		Warnings:           nonNil(warnings),
+		CorrectionsApplied: int64(corrections),
+	}, nil
+}
+
+func decodeBase64(input []byte, format EncodingFormat, validatePadding bool) ([]byte, *Error) {
+	enc := base64.StdEncoding
+	switch format {
+	case EncodingFormatBase64url:
+		enc = base64.URLEncoding
+	case EncodingFormatBase64Raw:
+		enc = base64.RawStdEncoding
+	}
+
+	if format != EncodingFormatBase64Raw {
+		hasPadding := len(input) > 0 && input[len(input)-1] == '='
+		switch {
+		case !validatePadding:
+			input = []byte(strings.TrimRight(string(input), "="))
+			enc = enc.WithPadding(base64.NoPadding)
+		case !hasPadding && format == EncodingFormatBase64url:
+			// Padding is optional for the URL-safe alphabet.
+			enc = enc.WithPadding(base64.NoPadding)
+		}
+	}
+
+	out := make([]byte, enc.DecodedLen(len(input)))
+	n, err := enc.Strict().Decode(out, input)
+	if err != nil {
+		return nil, binaryDecodeError(err, input, "base64", base64Alphabet(format))
+	}
+	return out[:n], nil
+}
+
+func decodeBase32(input []byte, format EncodingFormat) ([]byte, *Error) {
+	enc := base32.StdEncoding
+	if format == EncodingFormatBase32hex {
+		enc = base32.HexEncoding
+	}
+	out := make([]byte, enc.DecodedLen(len(input)))
+	n, err := enc.Decode(out, input)
+	if err != nil {
+		alphabet := "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+		if format == EncodingFormatBase32hex {
+			alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUV"
+		}
+		return nil, binaryDecodeError(err, input, "base32", alphabet)
+	}
+	return out[:n], nil
+}
+
+func decodeHex(input []byte) ([]byte, *Error) {
+	out := make([]byte, hex.DecodedLen(len(input)))
+	n, err := hex.Decode(out, input)
+	if err == nil {
+		return out[:n], nil
+	}
+
+	var invalid hex.InvalidByteError
+	if errors.As(err, &invalid) {
+		offset := strings.IndexByte(string(input), byte(invalid))
+		return nil, NewError(CodeInvalidEncoding, "decode", "invalid hex character").
+			WithSubcode(SubcodeInvalidCharacter).WithOffset(int64(offset))
+	}
+	return nil, NewError(CodeInvalidEncoding, "decode", "odd length hex input").
+		WithSubcode(SubcodeTruncatedInput).WithOffset(int64(len(input) - 1))
+}
+
+// binaryDecodeError classifies stdlib base32/base64 corruption errors into
+// padding and character errors.
+func binaryDecodeError(err error, input []byte, family, alphabet string) *Error {
+	var offset int64
+	switch e := err.(type) {
+	case base64.CorruptInputError:
+		offset = int64(e)
+	case base32.CorruptInputError:
+		offset = int64(e)
+	default:
+		return NewError(CodeInvalidEncoding, "decode", err.Error())
+	}
+
+	// A corrupt offset pointing at a valid alphabet character means the
+	// input is structurally wrong (missing or misplaced padding).
+	subcode := SubcodeInvalidCharacter
+	if offset >= int64(len(input)) || input[offset] == '=' || strings.IndexByte(alphabet, input[offset]) >= 0 {
+		subcode = SubcodeInvalidPadding
+	}
+	return NewError(CodeInvalidEncoding, "decode", fmt.Sprintf("invalid %s input", family)).
+		WithSubcode(subcode).WithOffset(offset)
+}
+
+func base64Alphabet(format EncodingFormat) string {
+	const common = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	if format == EncodingFormatBase64url {
+		return common + "-_"
+	}
+	return common + "+/"
+}
+
+func estimateDecodedLen(format EncodingFormat, n int) int64 {
+	switch format {
+	case EncodingFormatBase64, EncodingFormatBase64url, EncodingFormatBase64Raw:
+		return int64(base64.RawStdEncoding.DecodedLen(n))
+	case EncodingFormatBase32, EncodingFormatBase32hex:
+		return int64(base32.StdEncoding.WithPadding(base32.NoPadding).DecodedLen(n))
+	case EncodingFormatHex:
+		return int64(n / 2)
+	default:
+		// Text formats can grow in UTF-8: single-byte code pages up to 3x
+		// (0x80 is U+20AC in Windows-1252) and UTF-16 up to 1.5x. The input
+		// size is only a lower bound, still worth rejecting early; the
+		// decoded output is checked against the limit again.
+		return int64(n)
+	}
+}
+
+func isBinaryToText(format EncodingFormat) bool {
+	switch format {
+	case EncodingFormatBase64, EncodingFormatBase64url, EncodingFormatBase64Raw,
+		EncodingFormatBase32, EncodingFormatBase32hex, EncodingFormatHex:
+		return true
+	}
+	return false
+}
+
+func stripASCIIWhitespace(p []byte) []byte {
+	out := make([]byte, 0, len(p))
+	for _, b := range p {
+		switch b {
+		case ' ', '\t', '\n', '\r', '\f', '\v':
+			continue
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+func wrapLines(s string, n int, ending string) string {
+	if len(s) <= n {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i += n {
+		if i > 0 {
+			sb.WriteString(ending)
+		}
+		sb.WriteString(s[i:min(i+n, len(s))])
+	}
+	return sb.String()
+}
+
+type hexEncoding struct{ upper bool }
+
+func (h hexEncoding) EncodedLen(n int) int { return hex.EncodedLen(n) }
+
+func (h hexEncoding) EncodeToString(p []byte) string {
+	s := hex.EncodeToString(p)
+	if h.upper {
+		return strings.ToUpper(s)
+	}
+	return s
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,304 @@
+package fulencode
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fulmenhq/crucible"
+	"gopkg.in/yaml.v3"
+)
+
+type fixtureFile struct {
+	Cases []struct {
+		Name              string         `yaml:"name"`
+		Format            EncodingFormat `yaml:"format"`
+		Profile           string         `yaml:"profile"`
+		Input             string         `yaml:"input"`
+		InputHex          string         `yaml:"input_hex"`
+		Encoded           string         `yaml:"encoded"`
+		ExpectedErrorCode string         `yaml:"expected_error_code"`
+		Expected          struct {
+			Encoding string `yaml:"encoding"`
+			Level    string `yaml:"level"`
+		} `yaml:"expected"`
+	} `yaml:"cases"`
+}
+
+func loadFixture(t *testing.T, name string) fixtureFile {
+	t.Helper()
+	data, err := crucible.GetConfig("library/fulencode/fixtures/" + name)
+	if err != nil {
+		t.Fatalf("failed to read fixture %s: %v", name, err)
+	}
+	var f fixtureFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		t.Fatalf("failed to parse fixture %s: %v", name, err)
+	}
+	return f
+}
+
+func newTestCodec(t *testing.T, cfg *Config) *Codec {
+	t.Helper()
+	c, err := NewCodec(cfg)
+	if err != nil {
+		t.Fatalf("NewCodec failed: %v", err)
+	}
+	return c
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("overlays defaults", func(t *testing.T) {
+		cfg, err := LoadConfig([]byte("limits:\n  max_decoded_size: 2048\ndefaults:\n  normalization_profile: nfkc\n"))
+		if err != nil {
+			t.Fatalf("LoadConfig failed: %v", err)
+		}
+		if cfg.Limits.MaxDecodedSize != 2048 {
+			t.Errorf("MaxDecodedSize = %d, want 2048", cfg.Limits.MaxDecodedSize)
+		}
+		if cfg.Limits.MaxEncodedSize != DefaultConfig().Limits.MaxEncodedSize {
+			t.Error("unset limits should keep their defaults")
+		}
+		if cfg.Defaults.NormalizationProfile != NormalizationProfileNfkc {
+			t.Errorf("NormalizationProfile = %q, want nfkc", cfg.Defaults.NormalizationProfile)
+		}
+	})
+
+	t.Run("rejects schema violations", func(t *testing.T) {
+		for _, doc := range []string{
+			`{"limits": {"max_decoded_size": 10}}`,
+			`{"defaults": {"on_error": "explode"}}`,
+			`{"unknown": true}`,
+		} {
+			_, err := LoadConfig([]byte(doc))
+			var verr *crucible.SchemaValidationError
+			if !errors.As(err, &verr) {
+				t.Errorf("LoadConfig(%s) error = %v, want schema validation error", doc, err)
+			}
+		}
+	})
+
+	t.Run("rejects unknown normalization profile", func(t *testing.T) {
+		if _, err := LoadConfig([]byte("defaults:\n  normalization_profile: nope\n")); err == nil {
+			t.Error("expected error for unknown profile")
+		}
+	})
+}
+
+func TestCodecEncodeDecodeFixtures(t *testing.T) {
+	c := newTestCodec(t, nil)
+
+	for _, tc := range loadFixture(t, "valid-encodings/base64.yaml").Cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			input, err := hex.DecodeString(tc.InputHex)
+			if err != nil {
+				t.Fatalf("bad fixture hex: %v", err)
+			}
+			enc, err := c.Encode(input, tc.Format, nil)
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+			if enc.Data != tc.Encoded {
+				t.Errorf("Encode = %q, want %q", enc.Data, tc.Encoded)
+			}
+			dec, err := c.Decode([]byte(tc.Encoded), tc.Format, nil)
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			if string(dec.Data) != string(input) {
+				t.Errorf("Decode = %x, want %x", dec.Data, input)
+			}
+		})
+	}
+}
+
+func TestCodecDecodeErrors(t *testing.T) {
+	c := newTestCodec(t, nil)
+
+	tests := []struct {
+		name   string
+		input  []byte
+		format EncodingFormat
+		want   error
+		offset int64
+	}{
+		{"base64 character", []byte("SGVsbG8*"), EncodingFormatBase64, ErrInvalidCharacter, 7},
+		{"base64 padding", []byte("SGVsbG8"), EncodingFormatBase64, ErrInvalidPadding, 4},
+		{"overlong NUL", []byte{'a', 0xC0, 0x80}, EncodingFormatUtf8, ErrOverlongUTF8, 1},
+		{"utf-8 surrogate", []byte{0xED, 0xA0, 0x80}, EncodingFormatUtf8, ErrSurrogateCodepoint, 0},
+		{"utf-16 lone low surrogate", []byte{0x00, 0xDC}, EncodingFormatUtf16le, ErrUnpairedLowSurrogate, 0},
+		{"utf-16 lone high surrogate", []byte{0xD8, 0x00, 0x00, 0x41}, EncodingFormatUtf16be, ErrUnpairedHighSurrogate, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Decode(tt.input, tt.format, nil)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Decode error = %v, want %v", err, tt.want)
+			}
+			var fe *Error
+			if errors.As(err, &fe); fe.ByteOffset == nil || *fe.ByteOffset != tt.offset {
+				t.Errorf("byte offset = %v, want %d", fe.ByteOffset, tt.offset)
+			}
+		})
+	}
+
+	t.Run("replace mode", func(t *testing.T) {
+		res, err := c.Decode([]byte{'a', 0xC0, 0x80, 'b'}, EncodingFormatUtf8, &DecodeOptions{OnError: ErrorModeReplace})
+		if err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		if string(res.Data) != "a�b" || res.CorrectionsApplied != 1 {
+			t.Errorf("got %q with %d corrections", res.Data, res.CorrectionsApplied)
+		}
+	})
+
+	t.Run("fallback mode", func(t *testing.T) {
+		res, err := c.Decode([]byte{'c', 'a', 'f', 0xE9}, EncodingFormatUtf8, &DecodeOptions{
+			OnError:         ErrorModeFallback,
+			FallbackFormats: []EncodingFormat{EncodingFormatIso88591},
+		})
+		if err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		if string(res.Data) != "café" {
+			t.Errorf("got %q, want café", res.Data)
+		}
+	})
+
+	t.Run("fallback violations", func(t *testing.T) {
+		reg := useRegistry(t)
+		overlong := []byte{'a', 0xC0, 0x80}
+		if _, err := c.Decode(overlong, EncodingFormatUtf8, &DecodeOptions{
+			OnError:         ErrorModeFallback,
+			FallbackFormats: []EncodingFormat{EncodingFormatUtf8, EncodingFormatIso88591},
+		}); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		if evs := reg.Export(); len(evs) != 0 {
+			t.Errorf("successful fallback reported violations: %+v", evs)
+		}
+
+		if _, err := c.Decode(overlong, EncodingFormatUtf8, &DecodeOptions{
+			OnError:         ErrorModeFallback,
+			FallbackFormats: []EncodingFormat{EncodingFormatUtf16le, EncodingFormatUtf16be},
+		}); !errors.Is(err, ErrOverlongUTF8) {
+			t.Fatalf("Decode error = %v, want overlong", err)
+		}
+		if evs := reg.Export(); len(evs) != 1 || evs[0].Value != 1.0 {
+			t.Errorf("failed fallback violations = %+v, want 1", evs)
+		}
+	})
+}
+
+func TestCodecLimits(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Limits.MaxDecodedSize = 1024
+	cfg.Limits.MaxEncodedSize = 1024
+	c := newTestCodec(t, cfg)
+
+	reg := useRegistry(t)
+
+	if _, err := c.Decode([]byte(strings.Repeat("QUFB", 400)), EncodingFormatBase64, nil); !errors.Is(err, ErrSizeLimitExceeded) {
+		t.Errorf("Decode error = %v, want size limit", err)
+	}
+	if _, err := c.Encode(make([]byte, 1024), EncodingFormatHex, nil); !errors.Is(err, ErrSizeLimitExceeded) {
+		t.Errorf("Encode error = %v, want size limit", err)
+	}
+	if _, err := c.Normalize(strings.Repeat("a", 2048), "", nil); !errors.Is(err, ErrSizeLimitExceeded) {
+		t.Errorf("Normalize error = %v, want size limit", err)
+	}
+	if res, err := c.Detect(make([]byte, 2048), nil); res != nil || !errors.Is(err, ErrSizeLimitExceeded) {
+		t.Errorf("Detect = %+v, %v, want size limit", res, err)
+	}
+	var violations float64
+	for _, ev := range reg.Export() {
+		violations += ev.Value.(float64)
+	}
+	if violations != 4 {
+		t.Errorf("expected 4 reported violations, got %v", violations)
+	}
+}
+
+func TestCodecDetectFixtures(t *testing.T) {
+	c := newTestCodec(t, nil)
+
+	for _, tc := range loadFixture(t, "detection/detection.yaml").Cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			input, err := hex.DecodeString(tc.InputHex)
+			if err != nil {
+				t.Fatalf("bad fixture hex: %v", err)
+			}
+			res, _ := c.Detect(input, nil)
+			if res == nil || res.Encoding == nil {
+				t.Fatalf("expected a detected encoding, got %+v", res)
+			}
+			if string(*res.Encoding) != tc.Expected.Encoding || string(res.Level) != tc.Expected.Level {
+				t.Errorf("Detect = %s/%s, want %s/%s", *res.Encoding, res.Level, tc.Expected.Encoding, tc.Expected.Level)
+			}
+		})
+	}
+
+	t.Run("below min confidence", func(t *testing.T) {
+		res, err := c.Detect([]byte("plain ascii"), nil)
+		var fe *Error
+		if !errors.As(err, &fe) || fe.Code != CodeDetectionFailed {
+			t.Errorf("expected DETECTION_FAILED, got %v", err)
+		}
+		if res == nil {
+			t.Error("expected result alongside detection error")
+		}
+	})
+}
+
+func TestCodecNormalize(t *testing.T) {
+	c := newTestCodec(t, nil)
+
+	for _, tc := range loadFixture(t, "normalization/text-safe.yaml").Cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			_, err := c.Normalize(tc.Input, NormalizationProfile(tc.Profile), nil)
+			var fe *Error
+			if !errors.As(err, &fe) || string(fe.Code) != tc.ExpectedErrorCode {
+				t.Errorf("Normalize error = %v, want %s", err, tc.ExpectedErrorCode)
+			}
+		})
+	}
+
+	t.Run("default profile", func(t *testing.T) {
+		res, err := c.Normalize("é", "", nil)
+		if err != nil {
+			t.Fatalf("Normalize failed: %v", err)
+		}
+		if res.Profile != NormalizationProfileNfc || res.Text != "é" {
+			t.Errorf("got %q with profile %s", res.Text, res.Profile)
+		}
+	})
+
+	t.Run("semantic changes", func(t *testing.T) {
+		res, err := c.Normalize("ﬁle²", NormalizationProfileNfkc, nil)
+		if err != nil {
+			t.Fatalf("Normalize failed: %v", err)
+		}
+		if res.Text != "file2" || len(res.SemanticChanges) != 2 || len(res.Warnings) != 1 {
+			t.Errorf("unexpected result: %+v", res)
+		}
+	})
+
+	t.Run("search optimized", func(t *testing.T) {
+		res, err := c.Normalize("  Café,   CRÈME!  ", NormalizationProfileSearchOptimized, nil)
+		if err != nil {
+			t.Fatalf("Normalize failed: %v", err)
+		}
+		if res.Text != "cafe creme" {
+			t.Errorf("got %q, want %q", res.Text, "cafe creme")
+		}
+	})
+
+	t.Run("combining marks limit", func(t *testing.T) {
+		_, err := c.Normalize("a"+strings.Repeat("́", 11), NormalizationProfileNfd, nil)
+		var fe *Error
+		if !errors.As(err, &fe) || fe.Code != CodeExcessiveCombiningMarks {
+			t.Errorf("expected EXCESSIVE_COMBINING_MARKS, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package fulencode
+
+import (
+	"fmt"
+
+	"github.com/fulmenhq/crucible"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigSchemaPath is the embedded schema used to validate module configuration.
+const ConfigSchemaPath = "library/fulencode/v1.0.0/fulencode-config.schema.json"
+
+// ErrorMode controls how decode operations handle invalid input.
+type ErrorMode string
+
+const (
+	ErrorModeStrict   ErrorMode = "strict"
+	ErrorModeReplace  ErrorMode = "replace"
+	ErrorModeIgnore   ErrorMode = "ignore"
+	ErrorModeFallback ErrorMode = "fallback"
+)
+
+// Config is the fulencode module configuration.
+// See: schemas/library/fulencode/v1.0.0/fulencode-config.schema.json
+type Config struct {
+	Limits    Limits          `yaml:"limits"    json:"limits"`
+	Defaults  Defaults        `yaml:"defaults"  json:"defaults"`
+	Detection DetectionConfig `yaml:"detection" json:"detection"`
+}
+
+// Limits bounds the size and shape of fulencode inputs and outputs.
+type Limits struct {
+	MaxDecodedSize    int64   `yaml:"max_decoded_size"    json:"max_decoded_size"`
+	MaxEncodedSize    int64   `yaml:"max_encoded_size"    json:"max_encoded_size"`
+	MaxExpansionRatio float64 `yaml:"max_expansion_ratio" json:"max_expansion_ratio"`
+	MaxCombiningMarks int     `yaml:"max_combining_marks" json:"max_combining_marks"`
+}
+
+// Defaults are applied when an operation does not specify its own options.
+type Defaults struct {
+	OnError              ErrorMode            `yaml:"on_error"              json:"on_error"`
+	ChecksumAlgorithm    string               `yaml:"checksum_algorithm"    json:"checksum_algorithm"`
+	NormalizationProfile NormalizationProfile `yaml:"normalization_profile" json:"normalization_profile"`
+}
+
+// DetectionConfig tunes encoding detection.
+type DetectionConfig struct {
+	MinConfidence float64 `yaml:"min_confidence"  json:"min_confidence"`
+	MaxSampleSize int     `yaml:"max_sample_size" json:"max_sample_size"`
+}
+
+// DefaultConfig returns the configuration with every schema default applied.
+func DefaultConfig() *Config {
+	return &Config{
+		Limits: Limits{
+			MaxDecodedSize:    104857600,
+			MaxEncodedSize:    524288000,
+			MaxExpansionRatio: 10.0,
+			MaxCombiningMarks: 10,
+		},
+		Defaults: Defaults{
+			OnError:              ErrorModeStrict,
+			ChecksumAlgorithm:    "sha256",
+			NormalizationProfile: NormalizationProfileNfc,
+		},
+		Detection: DetectionConfig{
+			MinConfidence: 0.5,
+			MaxSampleSize: 8192,
+		},
+	}
+}
+
+// LoadConfig parses a YAML or JSON configuration document, validates it
+// against the embedded fulencode-config schema, and overlays it on the defaults.
+func LoadConfig(data []byte) (*Config, error) {
+	if err := crucible.ValidateSchemaData(ConfigSchemaPath, data); err != nil {
+		return nil, fmt.Errorf("invalid fulencode config: %w", err)
+	}
+
+	cfg := DefaultConfig()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse fulencode config: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks the configuration against the schema as well as constraints
+// the schema cannot express, such as the default normalization profile being
+// a known profile.
+func (c *Config) Validate() error {
+	if err := crucible.ValidateSchemaValue(ConfigSchemaPath, c); err != nil {
+		return fmt.Errorf("invalid fulencode config: %w", err)
+	}
+	if err := ValidateNormalizationProfile(c.Defaults.NormalizationProfile); err != nil {
+		return fmt.Errorf("invalid fulencode config: defaults.normalization_profile: %w", err)
+	}
+	return nil
+}
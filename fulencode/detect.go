@@ -0,0 +1,185 @@
+package fulencode
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+)
+
+// DetectOptions options for the detect operation.
+// See: schemas/library/fulencode/v1.0.0/detect-options.schema.json
+//
+// All fields are optional.
+type DetectOptions struct {
+	MaxSampleSize *int64   `json:"max_sample_size,omitempty"` // Maximum bytes to analyze
+	MinConfidence *float64 `json:"min_confidence,omitempty"`  // Minimum confidence threshold for a non-error detection result
+}
+
+// DetectionResult result of the detect operation.
+// See: schemas/library/fulencode/v1.0.0/detection-result.schema.json
+type DetectionResult struct {
+	Encoding   *EncodingFormat `json:"encoding"`   // Detected encoding or nil when unknown
+	Confidence float64         `json:"confidence"` // Detection confidence (0.0-1.0)
+	Level      ConfidenceLevel `json:"level"`      // Confidence level from detection-confidence taxonomy
+	Warnings   []string        `json:"warnings"`   // Non-fatal warnings
+}
+
+// ConfidenceLevelFor maps a confidence score onto the detection-confidence taxonomy.
+func ConfidenceLevelFor(confidence float64) ConfidenceLevel {
+	switch {
+	case confidence >= 0.9:
+		return ConfidenceLevelHigh
+	case confidence >= 0.5:
+		return ConfidenceLevelMedium
+	default:
+		return ConfidenceLevelLow
+	}
+}
+
+var boms = []struct {
+	sig      []byte
+	encoding EncodingFormat
+}{
+	{[]byte{0xEF, 0xBB, 0xBF}, EncodingFormatUtf8},
+	{[]byte{0xFF, 0xFE}, EncodingFormatUtf16le},
+	{[]byte{0xFE, 0xFF}, EncodingFormatUtf16be},
+}
+
+// Detect guesses the text encoding of data from a bounded sample. Input
+// larger than the configured max_decoded_size, the most Decode accepts, is
+// rejected with BUFFER_OVERFLOW. When the confidence falls below the minimum
+// threshold the result is still returned, together with a DETECTION_FAILED
+// error.
+func (c *Codec) Detect(data []byte, opts *DetectOptions) (*DetectionResult, error) {
+	if opts == nil {
+		opts = &DetectOptions{}
+	}
+	if size := int64(len(data)); size > c.cfg.Limits.MaxDecodedSize {
+		return nil, fail(sizeLimitError("detect", size, c.cfg.Limits.MaxDecodedSize))
+	}
+	sampleSize := int64(c.cfg.Detection.MaxSampleSize)
+	if opts.MaxSampleSize != nil {
+		sampleSize = *opts.MaxSampleSize
+	}
+	minConfidence := c.cfg.Detection.MinConfidence
+	if opts.MinConfidence != nil {
+		minConfidence = *opts.MinConfidence
+	}
+
+	sample := data
+	var warnings []string
+	if int64(len(sample)) > sampleSize {
+		sample = sample[:sampleSize]
+		// Avoid judging a multi-byte sequence split by the sample boundary.
+		for i := len(sample) - 1; i >= 0 && i >= len(sample)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(sample[i]) {
+				if !utf8.FullRune(sample[i:]) {
+					sample = sample[:i]
+				}
+				break
+			}
+		}
+		warnings = append(warnings, fmt.Sprintf("analyzed first %d of %d bytes", len(sample), len(data)))
+	}
+
+	encoding, confidence, reason := detectSample(sample)
+	if reason != "" {
+		warnings = append(warnings, reason)
+	}
+
+	res := &DetectionResult{
+		Confidence: confidence,
+		Level:      ConfidenceLevelFor(confidence),
+		Warnings:   nonNil(warnings),
+	}
+	if encoding != "" {
+		res.Encoding = &encoding
+	}
+
+	if confidence < minConfidence {
+		err := NewError(CodeDetectionFailed, "detect",
+			fmt.Sprintf("confidence %.2f below minimum %.2f", confidence, minConfidence))
+		err.Details = map[string]any{"confidence": confidence, "min_confidence": minConfidence}
+		if encoding != "" {
+			err.Details["detected_encoding"] = string(encoding)
+		}
+		return res, err
+	}
+	return res, nil
+}
+
+func detectSample(p []byte) (EncodingFormat, float64, string) {
+	if len(p) == 0 {
+		return "", 0, "empty input"
+	}
+
+	// UTF-32 BOMs overlap with UTF-16LE, and UTF-32 is not a supported format.
+	if bytes.HasPrefix(p, []byte{0xFF, 0xFE, 0x00, 0x00}) || bytes.HasPrefix(p, []byte{0x00, 0x00, 0xFE, 0xFF}) {
+		return "", 0, "UTF-32 BOM detected; UTF-32 is not supported"
+	}
+	for _, bom := range boms {
+		if bytes.HasPrefix(p, bom.sig) {
+			return bom.encoding, 1.0, ""
+		}
+	}
+
+	if enc, ok := detectUTF16(p); ok {
+		return enc, 0.75, "UTF-16 inferred from null byte pattern without BOM"
+	}
+	if bytes.IndexByte(p, 0) >= 0 {
+		return "", 0, "null bytes suggest binary data"
+	}
+
+	ascii := true
+	for _, b := range p {
+		if b >= 0x80 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return EncodingFormatUtf8, 0.4, "ASCII-only input is ambiguous between utf-8, ascii, iso-8859-1 and cp1252"
+	}
+
+	if _, _, issue := decodeUTF8(p, ErrorModeStrict); issue == nil {
+		return EncodingFormatUtf8, 0.85, ""
+	}
+
+	for _, b := range p {
+		if b >= 0x80 && b < 0xA0 && cp1252High[b-0x80] != 0 {
+			return EncodingFormatCp1252, 0.7, ""
+		}
+	}
+	return EncodingFormatIso88591, 0.6, ""
+}
+
+// detectUTF16 infers UTF-16 byte order from the position of null bytes in
+// mostly-ASCII text.
+func detectUTF16(p []byte) (EncodingFormat, bool) {
+	if len(p) < 4 || len(p)%2 != 0 {
+		return "", false
+	}
+	var evenZeros, oddZeros int
+	for i, b := range p {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenZeros++
+		} else {
+			oddZeros++
+		}
+	}
+	pairs := len(p) / 2
+	switch {
+	case oddZeros*2 >= pairs && evenZeros == 0:
+		if _, _, issue := decodeUTF16(p, false, ErrorModeStrict); issue == nil {
+			return EncodingFormatUtf16le, true
+		}
+	case evenZeros*2 >= pairs && oddZeros == 0:
+		if _, _, issue := decodeUTF16(p, true, ErrorModeStrict); issue == nil {
+			return EncodingFormatUtf16be, true
+		}
+	}
+	return "", false
+}
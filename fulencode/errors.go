@@ -0,0 +1,219 @@
+package fulencode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fulmenhq/crucible/errorsx"
+	"github.com/fulmenhq/crucible/foundry"
+	"github.com/fulmenhq/crucible/observability/metrics"
+)
+
+// ErrorCode is a canonical fulencode error code.
+// See: docs/standards/library/modules/fulencode.md#canonical-error-codes
+type ErrorCode string
+
+const (
+	CodeInvalidEncoding         ErrorCode = "INVALID_ENCODING"
+	CodeUnsupportedFormat       ErrorCode = "UNSUPPORTED_FORMAT"
+	CodeInvalidOptions          ErrorCode = "INVALID_OPTIONS"
+	CodeInvalidUTF8             ErrorCode = "INVALID_UTF8"
+	CodeInvalidUTF16            ErrorCode = "INVALID_UTF16"
+	CodeBufferOverflow          ErrorCode = "BUFFER_OVERFLOW"
+	CodeEncodingBomb            ErrorCode = "ENCODING_BOMB"
+	CodeExcessiveCombiningMarks ErrorCode = "EXCESSIVE_COMBINING_MARKS"
+	CodeZeroWidthCharacter      ErrorCode = "ZERO_WIDTH_CHARACTER"
+	CodeBidiControlCharacter    ErrorCode = "BIDI_CONTROL_CHARACTER"
+	CodeBOMMismatch             ErrorCode = "BOM_MISMATCH"
+	CodeDetectionFailed         ErrorCode = "DETECTION_FAILED"
+	CodeNormalizationError      ErrorCode = "NORMALIZATION_ERROR"
+)
+
+// Subcodes refine a canonical code. They are reported as details.subcode.
+const (
+	SubcodeInvalidPadding        = "invalid_padding"
+	SubcodeInvalidCharacter      = "invalid_character"
+	SubcodeOverlongEncoding      = "overlong_encoding"
+	SubcodeInvalidContinuation   = "invalid_continuation"
+	SubcodeSurrogateCodepoint    = "surrogate_codepoint"
+	SubcodeOutOfRange            = "out_of_range"
+	SubcodeTruncatedSequence     = "truncated_sequence"
+	SubcodeUnpairedHighSurrogate = "unpaired_high_surrogate"
+	SubcodeUnpairedLowSurrogate  = "unpaired_low_surrogate"
+	SubcodeTruncatedInput        = "truncated_input"
+)
+
+// Sentinel errors for use with errors.Is. A sentinel without a subcode
+// matches every error carrying its code; a sentinel with a subcode only
+// matches that refinement.
+var (
+	ErrInvalidEncoding       = NewError(CodeInvalidEncoding, "", "")
+	ErrInvalidPadding        = NewError(CodeInvalidEncoding, "", "").WithSubcode(SubcodeInvalidPadding)
+	ErrInvalidCharacter      = NewError(CodeInvalidEncoding, "", "").WithSubcode(SubcodeInvalidCharacter)
+	ErrInvalidUTF8           = NewError(CodeInvalidUTF8, "", "")
+	ErrOverlongUTF8          = NewError(CodeInvalidUTF8, "", "").WithSubcode(SubcodeOverlongEncoding)
+	ErrSurrogateCodepoint    = NewError(CodeInvalidUTF8, "", "").WithSubcode(SubcodeSurrogateCodepoint)
+	ErrInvalidUTF16          = NewError(CodeInvalidUTF16, "", "")
+	ErrUnpairedHighSurrogate = NewError(CodeInvalidUTF16, "", "").WithSubcode(SubcodeUnpairedHighSurrogate)
+	ErrUnpairedLowSurrogate  = NewError(CodeInvalidUTF16, "", "").WithSubcode(SubcodeUnpairedLowSurrogate)
+	ErrSizeLimitExceeded     = NewError(CodeBufferOverflow, "", "")
+	ErrEncodingBomb          = NewError(CodeEncodingBomb, "", "")
+	ErrUnsupportedFormat     = NewError(CodeUnsupportedFormat, "", "")
+	ErrInvalidOptions        = NewError(CodeInvalidOptions, "", "")
+)
+
+// Error is the Go form of the canonical fulencode error envelope.
+// See: schemas/library/fulencode/v1.0.0/fulencode-error.schema.json
+//
+// Details are merged into the envelope's details.
+type Error struct {
+	errorsx.Coded[ErrorCode]
+	Subcode      string
+	Operation    string
+	InputFormat  EncodingFormat
+	OutputFormat EncodingFormat
+	ByteOffset   *int64 // Offset of the offending byte in the input, if known
+}
+
+// NewError creates an Error for the given operation.
+func NewError(code ErrorCode, operation, message string) *Error {
+	return &Error{Coded: errorsx.Coded[ErrorCode]{Code: code, Message: message}, Operation: operation}
+}
+
+// WithSubcode sets the subcode and returns the error for chaining.
+func (e *Error) WithSubcode(subcode string) *Error {
+	e.Subcode = subcode
+	return e
+}
+
+// WithOffset sets the byte offset and returns the error for chaining.
+func (e *Error) WithOffset(offset int64) *Error {
+	e.ByteOffset = &offset
+	return e
+}
+
+// WithFormats sets the input and output formats and returns the error for chaining.
+func (e *Error) WithFormats(in, out EncodingFormat) *Error {
+	e.InputFormat = in
+	e.OutputFormat = out
+	return e
+}
+
+func (e *Error) Error() string {
+	var parts []string
+	if e.Subcode != "" {
+		parts = append(parts, e.Subcode)
+	}
+	if e.ByteOffset != nil {
+		parts = append(parts, fmt.Sprintf("byte offset %d", *e.ByteOffset))
+	}
+	var note string
+	if len(parts) > 0 {
+		note = "(" + strings.Join(parts, ", ") + ")"
+	}
+	return e.Text(e.Operation, note)
+}
+
+// Is reports whether target is a fulencode sentinel matching this error:
+// one with the same code and either no subcode or the same one.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && e.Coded.Is(target) && (t.Subcode == "" || t.Subcode == e.Subcode)
+}
+
+// ExitCode maps the error onto a Foundry exit code. Malformed byte
+// sequences are reported as corrupt data; everything else is invalid input.
+func (e *Error) ExitCode() int {
+	switch e.Code {
+	case CodeInvalidUTF8, CodeInvalidUTF16, CodeBOMMismatch:
+		return foundry.ExitDataCorrupt
+	default:
+		return foundry.ExitDataInvalid
+	}
+}
+
+// ViolationType returns the fulencode_security_violations_total type tag for
+// the error, or "" if the error reflects ordinary invalid input rather than
+// a security protection being triggered.
+func (e *Error) ViolationType() string {
+	switch e.Code {
+	case CodeInvalidUTF8:
+		return "invalid_utf8"
+	case CodeInvalidUTF16:
+		return "invalid_utf16"
+	case CodeBufferOverflow, CodeEncodingBomb:
+		return "encoding_bomb"
+	case CodeExcessiveCombiningMarks:
+		return "excessive_combining"
+	case CodeZeroWidthCharacter:
+		return "zero_width"
+	case CodeBidiControlCharacter:
+		return "bidi_controls"
+	case CodeBOMMismatch:
+		return "bom_mismatch"
+	default:
+		return ""
+	}
+}
+
+// IsSecurityViolation reports whether the error was raised by a security
+// protection (malformed UTF, size limits, normalization attacks) as opposed
+// to a plain decoding failure.
+func (e *Error) IsSecurityViolation() bool {
+	return e.ViolationType() != ""
+}
+
+// MarshalJSON renders the error as a fulencode-error envelope.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	type envelope struct {
+		Code         ErrorCode      `json:"code"`
+		Message      string         `json:"message"`
+		Operation    string         `json:"operation"`
+		InputFormat  EncodingFormat `json:"input_format,omitempty"`
+		OutputFormat EncodingFormat `json:"output_format,omitempty"`
+		Details      map[string]any `json:"details,omitempty"`
+	}
+
+	details := make(map[string]any, len(e.Details)+2)
+	for k, v := range e.Details {
+		details[k] = v
+	}
+	if e.Subcode != "" {
+		details["subcode"] = e.Subcode
+	}
+	if e.ByteOffset != nil {
+		details["byte_offset"] = *e.ByteOffset
+	}
+	if len(details) == 0 {
+		details = nil
+	}
+
+	msg := e.Message
+	if msg == "" {
+		msg = e.Error()
+	}
+	return json.Marshal(envelope{
+		Code:         e.Code,
+		Message:      msg,
+		Operation:    e.Operation,
+		InputFormat:  e.InputFormat,
+		OutputFormat: e.OutputFormat,
+		Details:      details,
+	})
+}
+
+// ReportViolation counts err in fulencode_security_violations_total, tagged
+// with its type and operation, on the default metrics registry if it is a
+// fulencode security violation. It returns true when a violation was found.
+func ReportViolation(err error) bool {
+	var fe *Error
+	if !errors.As(err, &fe) || !fe.IsSecurityViolation() {
+		return false
+	}
+	if c, err := metrics.Default().Counter(metrics.FulencodeSecurityViolationsTotal); err == nil {
+		c.With(metrics.Tags{"type": fe.ViolationType(), "operation": fe.Operation}).Inc()
+	}
+	return true
+}
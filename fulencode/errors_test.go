@@ -0,0 +1,103 @@
+package fulencode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/fulmenhq/crucible/foundry"
+	"github.com/fulmenhq/crucible/observability/metrics"
+)
+
+func TestErrorIs(t *testing.T) {
+	err := NewError(CodeInvalidUTF8, "decode", "overlong sequence").
+		WithSubcode(SubcodeOverlongEncoding).
+		WithOffset(12)
+	wrapped := fmt.Errorf("reading payload: %w", err)
+
+	if !errors.Is(wrapped, ErrOverlongUTF8) {
+		t.Error("expected error to match ErrOverlongUTF8")
+	}
+	if !errors.Is(wrapped, ErrInvalidUTF8) {
+		t.Error("expected error to match code-only sentinel ErrInvalidUTF8")
+	}
+	if errors.Is(wrapped, ErrSurrogateCodepoint) {
+		t.Error("did not expect error to match ErrSurrogateCodepoint")
+	}
+	if errors.Is(wrapped, ErrInvalidPadding) {
+		t.Error("did not expect error to match ErrInvalidPadding")
+	}
+}
+
+func TestErrorExitCode(t *testing.T) {
+	tests := []struct {
+		err  *Error
+		want int
+	}{
+		{NewError(CodeInvalidEncoding, "decode", "").WithSubcode(SubcodeInvalidPadding), foundry.ExitDataInvalid},
+		{NewError(CodeBufferOverflow, "decode", ""), foundry.ExitDataInvalid},
+		{NewError(CodeInvalidUTF8, "decode", "").WithSubcode(SubcodeOverlongEncoding), foundry.ExitDataCorrupt},
+		{NewError(CodeInvalidUTF16, "decode", "").WithSubcode(SubcodeUnpairedLowSurrogate), foundry.ExitDataCorrupt},
+	}
+	for _, tt := range tests {
+		if got := tt.err.ExitCode(); got != tt.want {
+			t.Errorf("%s: ExitCode() = %d, want %d", tt.err.Error(), got, tt.want)
+		}
+	}
+}
+
+func TestErrorSecurityViolation(t *testing.T) {
+	if NewError(CodeInvalidEncoding, "decode", "").WithSubcode(SubcodeInvalidCharacter).IsSecurityViolation() {
+		t.Error("invalid character should not be a security violation")
+	}
+
+	reg := useRegistry(t)
+	if ReportViolation(NewError(CodeInvalidEncoding, "decode", "").WithSubcode(SubcodeInvalidPadding)) {
+		t.Error("padding error should not be reported")
+	}
+	if !ReportViolation(fmt.Errorf("wrap: %w", NewError(CodeBufferOverflow, "decode", "too large"))) {
+		t.Error("size limit error should be reported")
+	}
+	evs := reg.Export()
+	if len(evs) != 1 || evs[0].Tags["type"] != "encoding_bomb" || evs[0].Tags["operation"] != "decode" || evs[0].Value != 1.0 {
+		t.Errorf("unexpected violations: %+v", evs)
+	}
+}
+
+// useRegistry makes a fresh registry the default for the test.
+func useRegistry(t *testing.T) *metrics.Registry {
+	t.Helper()
+	old := metrics.Default()
+	reg := metrics.NewRegistry()
+	metrics.SetDefault(reg)
+	t.Cleanup(func() { metrics.SetDefault(old) })
+	return reg
+}
+
+func TestErrorMarshalJSON(t *testing.T) {
+	err := NewError(CodeInvalidEncoding, "decode", "bad padding").
+		WithSubcode(SubcodeInvalidPadding).
+		WithFormats(EncodingFormatBase64, "").
+		WithOffset(7)
+
+	data, jerr := json.Marshal(err)
+	if jerr != nil {
+		t.Fatalf("marshal failed: %v", jerr)
+	}
+
+	var out map[string]any
+	if jerr := json.Unmarshal(data, &out); jerr != nil {
+		t.Fatalf("unmarshal failed: %v", jerr)
+	}
+	if out["code"] != "INVALID_ENCODING" || out["operation"] != "decode" || out["input_format"] != "base64" {
+		t.Errorf("unexpected envelope: %s", data)
+	}
+	if _, ok := out["output_format"]; ok {
+		t.Errorf("empty output_format should be omitted: %s", data)
+	}
+	details, _ := out["details"].(map[string]any)
+	if details["byte_offset"] != float64(7) || details["subcode"] != "invalid_padding" {
+		t.Errorf("unexpected details: %s", data)
+	}
+}
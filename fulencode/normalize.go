@@ -0,0 +1,279 @@
+package fulencode
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeOptions options for the normalize operation. Unset fields take
+// the profile defaults.
+// See: schemas/library/fulencode/v1.0.0/normalize-options.schema.json
+//
+// All fields are optional.
+type NormalizeOptions struct {
+	WarnSemanticChange *bool  `json:"warn_semantic_change,omitempty"` // Warn when normalization performs semantic-changing transforms (NFKC/NFKD)
+	RejectZeroWidth    *bool  `json:"reject_zero_width,omitempty"`    // Reject zero-width characters when true
+	RejectBidiControls *bool  `json:"reject_bidi_controls,omitempty"` // Reject bidi control characters (override/isolate) when true
+	MaxCombiningMarks  *int64 `json:"max_combining_marks,omitempty"`  // Maximum combining marks per base character
+	StripAccents       *bool  `json:"strip_accents,omitempty"`        // Remove combining marks/diacritics when true
+	CaseFold           *bool  `json:"case_fold,omitempty"`            // Apply Unicode case folding when true
+	RemovePunctuation  *bool  `json:"remove_punctuation,omitempty"`   // Remove punctuation and symbol categories when true
+	CompressWhitespace *bool  `json:"compress_whitespace,omitempty"`  // Collapse consecutive whitespace to a single space when true
+}
+
+// NormalizationResult result of the normalize operation.
+// See: schemas/library/fulencode/v1.0.0/normalization-result.schema.json
+type NormalizationResult struct {
+	Text                   string               `json:"text"`                    // Normalized text
+	Profile                NormalizationProfile `json:"profile"`                 // Profile applied
+	InputLength            int64                `json:"input_length"`            // Input length in codepoints
+	OutputLength           int64                `json:"output_length"`           // Output length in codepoints
+	TransformationsApplied []string             `json:"transformations_applied"` // Transformations applied in order
+	SemanticChanges        []string             `json:"semantic_changes"`        // Semantic change types detected (NFKC/NFKD)
+	Warnings               []string             `json:"warnings"`                // Non-fatal warnings
+}
+
+// profileRules is the resolved behavior of a normalization profile.
+type profileRules struct {
+	form                 norm.Form
+	rejectZeroWidth      bool
+	rejectBidiControls   bool
+	rejectControls       bool
+	rejectPathSeparators bool
+	identifierOnly       bool
+	stripAccents         bool
+	caseFold             bool
+	removePunctuation    bool
+	compressWhitespace   bool
+}
+
+// rulesFor returns the transformation rules for profile.
+// See: docs/standards/library/modules/fulencode.md#custom-profile-transformation-rules-per-pyfulmen-feedback
+func rulesFor(profile NormalizationProfile) profileRules {
+	switch profile {
+	case NormalizationProfileNfd:
+		return profileRules{form: norm.NFD}
+	case NormalizationProfileNfkc:
+		return profileRules{form: norm.NFKC}
+	case NormalizationProfileNfkd:
+		return profileRules{form: norm.NFKD}
+	case NormalizationProfileSafeIdentifiers:
+		return profileRules{form: norm.NFKC, rejectZeroWidth: true, identifierOnly: true}
+	case NormalizationProfileSearchOptimized:
+		return profileRules{form: norm.NFKD, stripAccents: true, caseFold: true, removePunctuation: true, compressWhitespace: true}
+	case NormalizationProfileFilenameSafe:
+		return profileRules{form: norm.NFC, rejectControls: true, rejectPathSeparators: true, compressWhitespace: true}
+	case NormalizationProfileTextSafe:
+		return profileRules{form: norm.NFC, rejectControls: true, rejectZeroWidth: true, rejectBidiControls: true}
+	default:
+		return profileRules{form: norm.NFC}
+	}
+}
+
+func (r *profileRules) apply(opts *NormalizeOptions) {
+	set := func(dst *bool, src *bool) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	set(&r.rejectZeroWidth, opts.RejectZeroWidth)
+	set(&r.rejectBidiControls, opts.RejectBidiControls)
+	set(&r.stripAccents, opts.StripAccents)
+	set(&r.caseFold, opts.CaseFold)
+	set(&r.removePunctuation, opts.RemovePunctuation)
+	set(&r.compressWhitespace, opts.CompressWhitespace)
+}
+
+// Normalize applies a normalization profile to text. An empty profile uses
+// the configured default profile.
+func (c *Codec) Normalize(text string, profile NormalizationProfile, opts *NormalizeOptions) (*NormalizationResult, error) {
+	if opts == nil {
+		opts = &NormalizeOptions{}
+	}
+	if profile == "" {
+		profile = c.cfg.Defaults.NormalizationProfile
+	}
+	if err := ValidateNormalizationProfile(profile); err != nil {
+		return nil, NewError(CodeNormalizationError, "normalize", err.Error()).WithSubcode("profile_not_found")
+	}
+	if size := int64(len(text)); size > c.cfg.Limits.MaxDecodedSize {
+		return nil, fail(sizeLimitError("normalize", size, c.cfg.Limits.MaxDecodedSize))
+	}
+	if !utf8.ValidString(text) {
+		return nil, fail(NewError(CodeInvalidUTF8, "normalize", "input is not valid UTF-8"))
+	}
+
+	maxMarks := int64(c.cfg.Limits.MaxCombiningMarks)
+	if opts.MaxCombiningMarks != nil {
+		maxMarks = *opts.MaxCombiningMarks
+	}
+
+	rules := rulesFor(profile)
+	rules.apply(opts)
+
+	out := rules.form.String(text)
+	applied := []string{formName(rules.form)}
+	var warnings []string
+
+	semantic := semanticChanges(text, rules.form)
+	if len(semantic) > 0 && (opts.WarnSemanticChange == nil || *opts.WarnSemanticChange) {
+		warnings = append(warnings, fmt.Sprintf("%s changed the meaning of some characters: %s",
+			formName(rules.form), strings.Join(semantic, ", ")))
+	}
+
+	if err := checkCharacters(out, rules, maxMarks); err != nil {
+		return nil, fail(err)
+	}
+
+	if rules.stripAccents {
+		out = strings.Map(func(r rune) rune {
+			if unicode.Is(unicode.Mn, r) {
+				return -1
+			}
+			return r
+		}, out)
+		applied = append(applied, "strip_accents")
+	}
+	if rules.caseFold {
+		out = cases.Fold().String(out)
+		applied = append(applied, "case_fold")
+	}
+	if rules.removePunctuation {
+		out = strings.Map(func(r rune) rune {
+			if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+				return -1
+			}
+			return r
+		}, out)
+		applied = append(applied, "remove_punctuation")
+	}
+	if rules.compressWhitespace {
+		out = strings.Join(strings.Fields(out), " ")
+		applied = append(applied, "compress_whitespace")
+	}
+
+	return &NormalizationResult{
+		Text:                   out,
+		Profile:                profile,
+		InputLength:            int64(utf8.RuneCountInString(text)),
+		OutputLength:           int64(utf8.RuneCountInString(out)),
+		TransformationsApplied: applied,
+		SemanticChanges:        nonNil(semantic),
+		Warnings:               nonNil(warnings),
+	}, nil
+}
+
+func formName(f norm.Form) string {
+	switch f {
+	case norm.NFD:
+		return "nfd"
+	case norm.NFKC:
+		return "nfkc"
+	case norm.NFKD:
+		return "nfkd"
+	default:
+		return "nfc"
+	}
+}
+
+// checkCharacters enforces the rejection rules of a profile on normalized text.
+func checkCharacters(text string, rules profileRules, maxMarks int64) *Error {
+	reject := func(code ErrorCode, subcode string, r rune, pos int, what string) *Error {
+		err := NewError(code, "normalize",
+			fmt.Sprintf("%s U+%04X not allowed at position %d", what, r, pos)).WithSubcode(subcode)
+		err.Details = map[string]any{"character": string(r), "codepoint": fmt.Sprintf("U+%04X", r), "position": pos}
+		return err
+	}
+
+	var marks int64
+	pos := 0
+	for _, r := range text {
+		if unicode.In(r, unicode.Mn, unicode.Mc, unicode.Me) {
+			marks++
+			if marks > maxMarks {
+				err := NewError(CodeExcessiveCombiningMarks, "normalize",
+					fmt.Sprintf("more than %d combining marks at position %d", maxMarks, pos))
+				err.Details = map[string]any{"mark_count": marks, "max_marks": maxMarks, "position": pos}
+				return err
+			}
+		} else {
+			marks = 0
+		}
+
+		switch {
+		case rules.rejectZeroWidth && isZeroWidth(r):
+			return reject(CodeZeroWidthCharacter, "", r, pos, "zero-width character")
+		case rules.rejectBidiControls && isBidiControl(r):
+			return reject(CodeBidiControlCharacter, "", r, pos, "bidi control character")
+		case rules.rejectControls && unicode.IsControl(r):
+			return reject(CodeInvalidEncoding, SubcodeInvalidCharacter, r, pos, "control character")
+		case rules.rejectPathSeparators && (r == '/' || r == '\\'):
+			return reject(CodeInvalidEncoding, SubcodeInvalidCharacter, r, pos, "path separator")
+		case rules.identifierOnly && !isIdentifierRune(r):
+			return reject(CodeInvalidEncoding, SubcodeInvalidCharacter, r, pos, "character")
+		}
+		pos++
+	}
+	return nil
+}
+
+func isZeroWidth(r rune) bool {
+	return r == 0x200B || r == 0x200C || r == 0x200D || r == 0xFEFF
+}
+
+func isBidiControl(r rune) bool {
+	return (r >= 0x202A && r <= 0x202E) || (r >= 0x2066 && r <= 0x2069) ||
+		r == 0x200E || r == 0x200F || r == 0x061C
+}
+
+// isIdentifierRune allows letters, decimal digits and connector punctuation.
+func isIdentifierRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.Is(unicode.Nd, r) || unicode.Is(unicode.Pc, r)
+}
+
+// semanticChanges lists the fulencode_normalize_semantic_changes_total
+// change types introduced by a compatibility form.
+func semanticChanges(text string, form norm.Form) []string {
+	if form != norm.NFKC && form != norm.NFKD {
+		return nil
+	}
+
+	var changes []string
+	seen := map[string]bool{}
+	for _, r := range text {
+		s := string(r)
+		if norm.NFKD.String(s) == norm.NFD.String(s) {
+			continue
+		}
+		kind := semanticChangeType(r)
+		if !seen[kind] {
+			seen[kind] = true
+			changes = append(changes, kind)
+		}
+	}
+	return changes
+}
+
+func semanticChangeType(r rune) string {
+	switch {
+	case r >= 0xFB00 && r <= 0xFB4F, r == 0x0132, r == 0x0133, r == 0x01C4, r >= 0x01C5 && r <= 0x01CC:
+		return "ligatures"
+	case r == 0x00B2, r == 0x00B3, r == 0x00B9, r >= 0x2070 && r <= 0x209F, r >= 0x1D2C && r <= 0x1D6A:
+		return "superscripts_subscripts"
+	case r >= 0x2160 && r <= 0x2188:
+		return "roman_numerals"
+	case r >= 0x2460 && r <= 0x2473, r >= 0x24EA && r <= 0x24FF, r >= 0x2776 && r <= 0x2793:
+		return "circled_numbers"
+	case r >= 0x2474 && r <= 0x24E9, r >= 0x1F100 && r <= 0x1F1FF:
+		return "enclosed_alphanumerics"
+	case r >= 0x00BC && r <= 0x00BE, r >= 0x2150 && r <= 0x215F, r == 0x2189:
+		return "fractions"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,192 @@
+package fulencode
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// utfIssue describes an invalid sequence found while scanning UTF input.
+type utfIssue struct {
+	subcode string
+	offset  int
+	length  int // number of input bytes consumed by the invalid sequence
+}
+
+// scanUTF8 classifies the first invalid sequence at or after p[0]. It
+// returns the decoded rune and its width, or an issue when the sequence is
+// not well-formed. Overlong encodings and surrogate codepoints are reported
+// separately from generic continuation errors because they are attack
+// signatures rather than truncation bugs.
+func scanUTF8(p []byte) (rune, int, *utfIssue) {
+	b0 := p[0]
+	if b0 < 0x80 {
+		return rune(b0), 1, nil
+	}
+
+	var need int
+	var minRune rune
+	var r rune
+	switch {
+	case b0 < 0xC0:
+		return 0, 1, &utfIssue{subcode: SubcodeInvalidContinuation, length: 1}
+	case b0 < 0xE0:
+		need, minRune, r = 1, 0x80, rune(b0&0x1F)
+	case b0 < 0xF0:
+		need, minRune, r = 2, 0x800, rune(b0&0x0F)
+	case b0 < 0xF8:
+		need, minRune, r = 3, 0x10000, rune(b0&0x07)
+	default:
+		return 0, 1, &utfIssue{subcode: SubcodeOutOfRange, length: 1}
+	}
+
+	for i := 1; i <= need; i++ {
+		if i >= len(p) {
+			return 0, i, &utfIssue{subcode: SubcodeTruncatedSequence, length: i}
+		}
+		if p[i]&0xC0 != 0x80 {
+			return 0, i, &utfIssue{subcode: SubcodeInvalidContinuation, length: i}
+		}
+		r = r<<6 | rune(p[i]&0x3F)
+	}
+
+	width := need + 1
+	switch {
+	case r < minRune:
+		return 0, width, &utfIssue{subcode: SubcodeOverlongEncoding, length: width}
+	case r >= 0xD800 && r <= 0xDFFF:
+		return 0, width, &utfIssue{subcode: SubcodeSurrogateCodepoint, length: width}
+	case r > utf8.MaxRune:
+		return 0, width, &utfIssue{subcode: SubcodeOutOfRange, length: width}
+	}
+	return r, width, nil
+}
+
+// decodeUTF8 validates p as UTF-8. In replace mode each invalid sequence
+// becomes U+FFFD, in ignore mode it is dropped; otherwise the first issue is
+// returned.
+func decodeUTF8(p []byte, mode ErrorMode) ([]byte, int, *utfIssue) {
+	if utf8.Valid(p) {
+		return p, 0, nil
+	}
+
+	out := make([]byte, 0, len(p))
+	corrections := 0
+	for i := 0; i < len(p); {
+		r, width, issue := scanUTF8(p[i:])
+		if issue != nil {
+			issue.offset = i
+			switch mode {
+			case ErrorModeReplace:
+				out = utf8.AppendRune(out, utf8.RuneError)
+			case ErrorModeIgnore:
+			default:
+				return nil, 0, issue
+			}
+			corrections++
+			i += issue.length
+			continue
+		}
+		out = utf8.AppendRune(out, r)
+		i += width
+	}
+	return out, corrections, nil
+}
+
+// decodeUTF16 converts UTF-16 bytes to UTF-8, classifying unpaired surrogates.
+func decodeUTF16(p []byte, bigEndian bool, mode ErrorMode) ([]byte, int, *utfIssue) {
+	unit := func(i int) uint16 {
+		if bigEndian {
+			return uint16(p[i])<<8 | uint16(p[i+1])
+		}
+		return uint16(p[i+1])<<8 | uint16(p[i])
+	}
+
+	out := make([]byte, 0, len(p))
+	corrections := 0
+	fail := func() bool {
+		if mode != ErrorModeReplace && mode != ErrorModeIgnore {
+			return false
+		}
+		if mode == ErrorModeReplace {
+			out = utf8.AppendRune(out, utf8.RuneError)
+		}
+		corrections++
+		return true
+	}
+
+	for i := 0; i < len(p); {
+		if i+1 >= len(p) {
+			issue := &utfIssue{subcode: SubcodeTruncatedInput, offset: i, length: 1}
+			if !fail() {
+				return nil, 0, issue
+			}
+			break
+		}
+
+		u := unit(i)
+		switch {
+		case utf16.IsSurrogate(rune(u)) && u < 0xDC00:
+			if i+3 < len(p) {
+				if lo := unit(i + 2); lo >= 0xDC00 && lo <= 0xDFFF {
+					out = utf8.AppendRune(out, utf16.DecodeRune(rune(u), rune(lo)))
+					i += 4
+					continue
+				}
+			}
+			issue := &utfIssue{subcode: SubcodeUnpairedHighSurrogate, offset: i, length: 2}
+			if !fail() {
+				return nil, 0, issue
+			}
+		case utf16.IsSurrogate(rune(u)):
+			issue := &utfIssue{subcode: SubcodeUnpairedLowSurrogate, offset: i, length: 2}
+			if !fail() {
+				return nil, 0, issue
+			}
+		default:
+			out = utf8.AppendRune(out, rune(u))
+		}
+		i += 2
+	}
+	return out, corrections, nil
+}
+
+// cp1252High maps CP1252 bytes 0x80-0x9F to Unicode. Zero entries are
+// undefined in CP1252.
+var cp1252High = [32]rune{
+	0x20AC, 0, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0, 0x017D, 0,
+	0, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0, 0x017E, 0x0178,
+}
+
+// decodeSingleByte converts ASCII, ISO-8859-1 or CP1252 bytes to UTF-8.
+func decodeSingleByte(p []byte, format EncodingFormat, mode ErrorMode) ([]byte, int, *utfIssue) {
+	out := make([]byte, 0, len(p))
+	corrections := 0
+	for i, b := range p {
+		r := rune(b)
+		switch {
+		case b < 0x80:
+		case format == EncodingFormatAscii:
+			r = -1
+		case format == EncodingFormatCp1252 && b < 0xA0:
+			if r = cp1252High[b-0x80]; r == 0 {
+				r = -1
+			}
+		}
+
+		if r < 0 {
+			switch mode {
+			case ErrorModeReplace:
+				out = utf8.AppendRune(out, utf8.RuneError)
+			case ErrorModeIgnore:
+			default:
+				return nil, 0, &utfIssue{subcode: SubcodeInvalidCharacter, offset: i, length: 1}
+			}
+			corrections++
+			continue
+		}
+		out = utf8.AppendRune(out, r)
+	}
+	return out, corrections, nil
+}
@@ -0,0 +1,181 @@
+package fulhash
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
+
+	"github.com/zeebo/xxh3"
+)
+
+// Supported lists every algorithm accepted by Hash and NewStreamHasher.
+var Supported = []Algorithm{XXH3_128, SHA256, CRC32, CRC32C}
+
+// ErrUnsupportedAlgorithm is returned for algorithms outside Supported.
+var ErrUnsupportedAlgorithm = errors.New("fulhash: unsupported algorithm")
+
+// ErrInvalidChecksumFormat is returned by ParseChecksum for strings that are
+// not of the form "<algorithm>:<lowercase-hex>".
+var ErrInvalidChecksumFormat = errors.New("fulhash: invalid checksum format")
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+func unsupported(alg Algorithm) error {
+	names := make([]string, len(Supported))
+	for i, a := range Supported {
+		names[i] = string(a)
+	}
+	return fmt.Errorf("%w %q (supported algorithms: %s)", ErrUnsupportedAlgorithm, alg, strings.Join(names, ", "))
+}
+
+func newHash(alg Algorithm) (hash.Hash, error) {
+	switch alg {
+	case XXH3_128:
+		return xxh3.New(), nil
+	case SHA256:
+		return sha256.New(), nil
+	case CRC32:
+		return crc32.NewIEEE(), nil
+	case CRC32C:
+		return crc32.New(castagnoli), nil
+	default:
+		return nil, unsupported(alg)
+	}
+}
+
+// NewDigest builds a Digest from raw digest bytes.
+func NewDigest(alg Algorithm, sum []byte) Digest {
+	h := hex.EncodeToString(sum)
+	return Digest{
+		Algorithm: string(alg),
+		Hex:       h,
+		Formatted: string(alg) + ":" + h,
+		Bytes:     sum,
+	}
+}
+
+// Hash computes the digest of data.
+func Hash(data []byte, alg Algorithm) (Digest, error) {
+	h, err := newHash(alg)
+	if err != nil {
+		return Digest{}, err
+	}
+	h.Write(data)
+	return NewDigest(alg, sumOf(alg, h)), nil
+}
+
+// HashString computes the digest of the UTF-8 bytes of s.
+func HashString(s string, alg Algorithm) (Digest, error) {
+	return Hash([]byte(s), alg)
+}
+
+// HashReader computes the digest of everything read from r.
+func HashReader(r io.Reader, alg Algorithm) (Digest, error) {
+	h, err := NewStreamHasher(alg)
+	if err != nil {
+		return Digest{}, err
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return Digest{}, err
+	}
+	return h.Sum(), nil
+}
+
+// MultiHash computes several digests of data in a single pass.
+func MultiHash(r io.Reader, algs []Algorithm) (map[Algorithm]Digest, error) {
+	hashers := make([]StreamHasher, len(algs))
+	writers := make([]io.Writer, len(algs))
+	for i, alg := range algs {
+		h, err := NewStreamHasher(alg)
+		if err != nil {
+			return nil, err
+		}
+		hashers[i], writers[i] = h, h
+	}
+	if _, err := io.Copy(io.MultiWriter(writers...), r); err != nil {
+		return nil, err
+	}
+	out := make(map[Algorithm]Digest, len(algs))
+	for i, alg := range algs {
+		out[alg] = hashers[i].Sum()
+	}
+	return out, nil
+}
+
+// MultiHashString computes several digests of s in a single pass.
+func MultiHashString(s string, algs []Algorithm) (map[Algorithm]Digest, error) {
+	return MultiHash(strings.NewReader(s), algs)
+}
+
+// StreamHasher incrementally hashes data written to it.
+type StreamHasher interface {
+	io.Writer
+	Sum() Digest
+	Reset()
+}
+
+type streamHasher struct {
+	alg Algorithm
+	h   hash.Hash
+}
+
+// NewStreamHasher returns a StreamHasher for alg.
+func NewStreamHasher(alg Algorithm) (StreamHasher, error) {
+	h, err := newHash(alg)
+	if err != nil {
+		return nil, err
+	}
+	return &streamHasher{alg: alg, h: h}, nil
+}
+
+func (s *streamHasher) Write(p []byte) (int, error) { return s.h.Write(p) }
+func (s *streamHasher) Sum() Digest                 { return NewDigest(s.alg, sumOf(s.alg, s.h)) }
+func (s *streamHasher) Reset()                      { s.h.Reset() }
+
+// sumOf finalizes h. XXH3-128 is rendered in canonical big-endian order.
+func sumOf(alg Algorithm, h hash.Hash) []byte {
+	if alg == XXH3_128 {
+		b := h.(*xxh3.Hasher).Sum128().Bytes()
+		return b[:]
+	}
+	return h.Sum(nil)
+}
+
+// FormatChecksum renders the canonical "<algorithm>:<hex>" form.
+func FormatChecksum(alg Algorithm, hexValue string) string {
+	return string(alg) + ":" + strings.ToLower(hexValue)
+}
+
+// ParseChecksum splits a canonical checksum string into its algorithm and
+// hex value.
+func ParseChecksum(checksum string) (Algorithm, string, error) {
+	alg, value, ok := strings.Cut(checksum, ":")
+	if !ok || value == "" {
+		return "", "", fmt.Errorf("%w: %q (expected format algorithm:hex)", ErrInvalidChecksumFormat, checksum)
+	}
+	if !Algorithm(alg).IsValid() {
+		return "", "", unsupported(Algorithm(alg))
+	}
+	if _, err := hex.DecodeString(value); err != nil || strings.ToLower(value) != value {
+		return "", "", fmt.Errorf("%w: %q (hex must be lowercase)", ErrInvalidChecksumFormat, checksum)
+	}
+	return Algorithm(alg), value, nil
+}
+
+// Verify reports whether data matches the expected checksum string.
+func Verify(data []byte, checksum string) (bool, error) {
+	alg, want, err := ParseChecksum(checksum)
+	if err != nil {
+		return false, err
+	}
+	d, err := Hash(data, alg)
+	if err != nil {
+		return false, err
+	}
+	return d.Hex == want, nil
+}
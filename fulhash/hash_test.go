@@ -0,0 +1,132 @@
+package fulhash
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fulmenhq/crucible"
+	"gopkg.in/yaml.v3"
+)
+
+type fixtures struct {
+	Fixtures []struct {
+		Name       string `yaml:"name"`
+		Input      string `yaml:"input"`
+		InputBytes []byte `yaml:"input_bytes"`
+		XXH3128    string `yaml:"xxh3_128"`
+		SHA256     string `yaml:"sha256"`
+	} `yaml:"fixtures"`
+	StreamingFixtures []struct {
+		Name   string `yaml:"name"`
+		Chunks []struct {
+			Value   string `yaml:"value"`
+			Size    int    `yaml:"size"`
+			Pattern string `yaml:"pattern"`
+		} `yaml:"chunks"`
+		ExpectedXXH3128 string `yaml:"expected_xxh3_128"`
+		ExpectedSHA256  string `yaml:"expected_sha256"`
+	} `yaml:"streaming_fixtures"`
+	ErrorFixtures []struct {
+		Name                 string   `yaml:"name"`
+		Input                string   `yaml:"input"`
+		Algorithm            string   `yaml:"algorithm"`
+		Checksum             string   `yaml:"checksum"`
+		ErrorMessageContains []string `yaml:"error_message_contains"`
+	} `yaml:"error_fixtures"`
+}
+
+func loadFixtures(t *testing.T) fixtures {
+	t.Helper()
+	data, err := crucible.GetConfig("library/fulhash/fixtures.yaml")
+	if err != nil {
+		t.Fatalf("failed to read fixtures: %v", err)
+	}
+	var f fixtures
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		t.Fatalf("failed to parse fixtures: %v", err)
+	}
+	return f
+}
+
+func TestHashFixtures(t *testing.T) {
+	f := loadFixtures(t)
+
+	for _, fx := range f.Fixtures {
+		t.Run(fx.Name, func(t *testing.T) {
+			input := fx.InputBytes
+			if input == nil {
+				input = []byte(fx.Input)
+			}
+			for alg, want := range map[Algorithm]string{XXH3_128: fx.XXH3128, SHA256: fx.SHA256} {
+				d, err := Hash(input, alg)
+				if err != nil {
+					t.Fatalf("Hash(%s) failed: %v", alg, err)
+				}
+				if d.Formatted != want {
+					t.Errorf("Hash(%s) = %s, want %s", alg, d.Formatted, want)
+				}
+			}
+		})
+	}
+
+	for _, fx := range f.StreamingFixtures {
+		t.Run(fx.Name, func(t *testing.T) {
+			for alg, want := range map[Algorithm]string{XXH3_128: fx.ExpectedXXH3128, SHA256: fx.ExpectedSHA256} {
+				h, err := NewStreamHasher(alg)
+				if err != nil {
+					t.Fatalf("NewStreamHasher(%s) failed: %v", alg, err)
+				}
+				for _, c := range fx.Chunks {
+					chunk := []byte(c.Value)
+					if c.Pattern != "" {
+						chunk = bytes.Repeat([]byte(strings.TrimPrefix(c.Pattern, "repeating-")), c.Size)
+					}
+					h.Write(chunk)
+				}
+				if got := h.Sum().Formatted; got != want {
+					t.Errorf("stream %s = %s, want %s", alg, got, want)
+				}
+			}
+		})
+	}
+
+	for _, fx := range f.ErrorFixtures {
+		t.Run(fx.Name, func(t *testing.T) {
+			var err error
+			if fx.Checksum != "" {
+				_, _, err = ParseChecksum(fx.Checksum)
+			} else {
+				_, err = HashString(fx.Input, Algorithm(fx.Algorithm))
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			for _, s := range fx.ErrorMessageContains {
+				if !strings.Contains(err.Error(), s) {
+					t.Errorf("error %q does not mention %q", err, s)
+				}
+			}
+		})
+	}
+}
+
+func TestCRCAndVerify(t *testing.T) {
+	d, err := HashString("123456789", CRC32)
+	if err != nil || d.Hex != "cbf43926" {
+		t.Errorf("crc32 = %s, %v; want cbf43926", d.Hex, err)
+	}
+	d, err = HashString("123456789", CRC32C)
+	if err != nil || d.Hex != "e3069283" {
+		t.Errorf("crc32c = %s, %v; want e3069283", d.Hex, err)
+	}
+
+	ok, err := Verify([]byte("123456789"), "crc32:cbf43926")
+	if err != nil || !ok {
+		t.Errorf("Verify = %v, %v; want match", ok, err)
+	}
+	if _, err := Verify(nil, "crc32:CBF43926"); !errors.Is(err, ErrInvalidChecksumFormat) {
+		t.Errorf("uppercase hex error = %v, want invalid format", err)
+	}
+}
@@ -0,0 +1,469 @@
+package fulpack
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxSymlinkHops bounds symlink resolution within a Reader.
+const maxSymlinkHops = 40
+
+// Reader provides random access to the entries of an archive as an fs.FS,
+// so archives can be used with fs.ReadFile, fs.WalkDir, fs.Glob and
+// anything else that accepts an fs.FS.
+//
+// Open reads the table of contents once. Zip entries are then read through
+// the central directory and entries of uncompressed tar archives through
+// their recorded offsets, so reading one entry does not touch the rest of
+// the archive. Compressed tar and gzip streams cannot seek: reading an entry
+// decompresses the archive up to that entry.
+//
+// The file system applies the same path rules as Extract. Entries with
+// absolute or "../" paths and links whose targets leave the archive root are
+// left out, and symlinks are only followed within the archive. Directories
+// that the archive implies but does not store are synthesized.
+//
+// It also applies Extract's bomb protection: archives with more than
+// DefaultScanMaxEntries entries are rejected by Open, and an entry that
+// declares or decompresses to more than DefaultMaxSize bytes fails to open
+// or read with DECOMPRESSION_BOMB, so ReadFile never buffers more than that.
+//
+// Reader implements fs.ReadDirFS, fs.ReadFileFS, fs.StatFS and
+// fs.ReadLinkFS, and is safe for concurrent use.
+type Reader struct {
+	archive  string
+	format   ArchiveFormat
+	src      archiveReader // kept open for zip and uncompressed tar
+	ra       io.ReaderAt   // uncompressed tar
+	zr       *zip.Reader
+	manifest *ArchiveManifest
+	nodes    map[string]*node
+	maxSize  int64 // Bytes an entry may yield
+}
+
+var (
+	_ fs.ReadDirFS   = (*Reader)(nil)
+	_ fs.ReadFileFS  = (*Reader)(nil)
+	_ fs.StatFS      = (*Reader)(nil)
+	_ fs.ReadLinkFS  = (*Reader)(nil)
+	_ fs.ReadDirFile = (*dirFile)(nil)
+)
+
+// Open indexes archive, detecting its format from content, and returns a
+// Reader over its entries. Archives with more than DefaultScanMaxEntries
+// entries are rejected with DECOMPRESSION_BOMB. The Reader must be closed.
+func Open(archive string) (*Reader, error) {
+	src, format, oerr := openArchive(archive, "", OperationScan)
+	if oerr != nil {
+		return nil, oerr
+	}
+	r := &Reader{
+		archive: archive,
+		format:  format,
+		nodes:   map[string]*node{".": {name: ".", mode: fs.ModeDir | 0o755, offset: -1}},
+		maxSize: DefaultMaxSize,
+	}
+	if err := r.index(src); err != nil {
+		src.close()
+		return nil, err.WithArchive(archive)
+	}
+	switch s := src.(type) {
+	case *zipReader:
+		r.src, r.zr = src, s.zr
+	case *tarReader:
+		if s.pos != nil {
+			r.src, r.ra = src, s.f
+		}
+	}
+	if r.src == nil {
+		src.close()
+	}
+	return r, nil
+}
+
+// index builds the file tree and manifest in a single pass over the headers.
+func (r *Reader) index(src archiveReader) *Error {
+	var entries []ArchiveEntry
+	position := map[string]int{}
+	for ordinal := 0; ; ordinal++ {
+		h, err := src.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return corrupt(err, OperationScan)
+		}
+		if int64(ordinal) >= DefaultScanMaxEntries {
+			return NewError(CodeDecompressionBomb, OperationScan,
+				fmt.Sprintf("archive has more than %d entries", DefaultScanMaxEntries)).
+				WithDetail("entry_index", ordinal).WithDetail("max_entries", DefaultScanMaxEntries)
+		}
+
+		name, perr := sanitizeEntryPath(h.name, OperationScan)
+		if perr != nil || name == "" || h.typ == "" {
+			continue
+		}
+		n := &node{
+			name:    name,
+			mode:    h.mode,
+			modTime: h.modTime,
+			ordinal: ordinal,
+			offset:  h.dataOffset,
+		}
+		switch {
+		case h.typ == EntryTypeDirectory:
+			n.mode |= fs.ModeDir
+		case h.typ == EntryTypeSymlink:
+			if checkLinkTarget(nil, name, h.link, false, OperationScan) != nil {
+				continue
+			}
+			n.mode |= fs.ModeSymlink
+			n.link = strings.ReplaceAll(h.link, `\`, "/")
+			n.size = int64(len(n.link))
+		case h.hardlink:
+			target, terr := sanitizeEntryPath(h.link, OperationScan)
+			if terr != nil || target == "" {
+				continue
+			}
+			n.hardlink, n.link = true, target
+		default:
+			n.size = max(h.size, 0)
+		}
+		if !r.insert(n) {
+			continue
+		}
+
+		e := newArchiveEntry(name, h, true)
+		if i, ok := position[name]; ok {
+			entries[i] = e
+		} else {
+			position[name] = len(entries)
+			entries = append(entries, e)
+		}
+	}
+
+	for name, n := range r.nodes {
+		if name != "." {
+			parent := r.nodes[path.Dir(name)]
+			parent.children = append(parent.children, n)
+		}
+	}
+	for _, n := range r.nodes {
+		slices.SortFunc(n.children, func(a, b *node) int { return strings.Compare(a.Name(), b.Name()) })
+	}
+	if entries == nil {
+		entries = []ArchiveEntry{}
+	}
+	r.manifest = newManifest(r.archive, r.format, entries)
+	return nil
+}
+
+// insert adds n to the tree, synthesizing missing parent directories. Later
+// entries replace earlier ones with the same path, as they would on
+// extraction. Entries below a non-directory are dropped.
+func (r *Reader) insert(n *node) bool {
+	for dir := path.Dir(n.name); ; dir = path.Dir(dir) {
+		if p, ok := r.nodes[dir]; ok {
+			if !p.IsDir() {
+				return false
+			}
+			break
+		}
+		r.nodes[dir] = &node{name: dir, mode: fs.ModeDir | 0o755, offset: -1}
+	}
+	if old, ok := r.nodes[n.name]; ok && old.IsDir() && !n.IsDir() {
+		return false
+	}
+	r.nodes[n.name] = n
+	return true
+}
+
+// Format returns the detected archive format.
+func (r *Reader) Format() ArchiveFormat { return r.format }
+
+// Manifest returns the table of contents built by Open. It lists the
+// entries visible through the Reader; its Index maps their paths to
+// positions in Entries.
+func (r *Reader) Manifest() *ArchiveManifest { return r.manifest }
+
+// Close releases the archive file.
+func (r *Reader) Close() error {
+	if r.src == nil {
+		return nil
+	}
+	return r.src.close()
+}
+
+// lookup resolves name to a node, following symlinks in parent directories
+// and, when follow is set, in the final element.
+func (r *Reader) lookup(op, name string, follow bool) (*node, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	notExist := &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	current := name
+	for hops := 0; ; {
+		n, rest, ok := r.walk(current)
+		if !ok {
+			return nil, notExist
+		}
+		if !n.isSymlink() || (rest == "" && !follow) {
+			if rest != "" {
+				return nil, notExist
+			}
+			return n, nil
+		}
+		if hops++; hops > maxSymlinkHops {
+			return nil, &fs.PathError{Op: op, Path: name, Err: errors.New("too many levels of symbolic links")}
+		}
+		target := path.Join(path.Dir(n.name), n.link)
+		if target == ".." || strings.HasPrefix(target, "../") {
+			return nil, notExist
+		}
+		current = path.Join(target, rest)
+	}
+}
+
+// walk descends to name, stopping early at a symlink. It returns the node
+// reached and the unresolved remainder of name.
+func (r *Reader) walk(name string) (*node, string, bool) {
+	if name == "." {
+		return r.nodes["."], "", true
+	}
+	parts := strings.Split(name, "/")
+	for i := range parts {
+		n, ok := r.nodes[strings.Join(parts[:i+1], "/")]
+		if !ok {
+			return nil, "", false
+		}
+		if n.isSymlink() || i == len(parts)-1 {
+			return n, strings.Join(parts[i+1:], "/"), true
+		}
+	}
+	return nil, "", false
+}
+
+// Open opens the named entry. Symlinks are followed.
+func (r *Reader) Open(name string) (fs.File, error) {
+	n, err := r.lookup("open", name, true)
+	if err != nil {
+		return nil, err
+	}
+	if n.IsDir() {
+		return &dirFile{n: n}, nil
+	}
+	for hops := 0; n.hardlink; hops++ {
+		target, ok := r.nodes[n.link]
+		if !ok || hops > maxSymlinkHops || target.IsDir() || target.isSymlink() {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+		n = &node{name: n.name, mode: n.mode, modTime: n.modTime, size: target.size,
+			ordinal: target.ordinal, offset: target.offset, link: target.link, hardlink: target.hardlink}
+	}
+	if n.size > r.maxSize {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: r.bomb(n.size)}
+	}
+
+	switch {
+	case r.ra != nil && n.offset >= 0:
+		return &sectionFile{n: n, SectionReader: io.NewSectionReader(r.ra, n.offset, n.size)}, nil
+	case r.zr != nil:
+		rc, err := r.zr.File[n.ordinal].Open()
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: corrupt(err, OperationExtract).WithArchive(r.archive)}
+		}
+		return &streamFile{n: n, r: r.guard(rc), close: rc.Close}, nil
+	}
+	return r.openStream(name, n)
+}
+
+// openStream decompresses the archive up to the entry of n.
+func (r *Reader) openStream(name string, n *node) (fs.File, error) {
+	src, _, oerr := openArchive(r.archive, r.format, OperationExtract)
+	if oerr != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: oerr}
+	}
+	for i := 0; i <= n.ordinal; i++ {
+		if _, err := src.next(); err != nil {
+			src.close()
+			return nil, &fs.PathError{Op: "open", Path: name, Err: corrupt(err, OperationExtract).WithArchive(r.archive)}
+		}
+	}
+	rd, err := src.open()
+	if err != nil {
+		src.close()
+		return nil, &fs.PathError{Op: "open", Path: name, Err: corrupt(err, OperationExtract).WithArchive(r.archive)}
+	}
+	return &streamFile{n: n, r: r.guard(rd), close: src.close}, nil
+}
+
+// bomb reports an entry of size bytes exceeding the size limit.
+func (r *Reader) bomb(size int64) *Error {
+	return NewError(CodeDecompressionBomb, OperationExtract, "entry exceeds maximum size limit").
+		WithArchive(r.archive).WithDetail("actual_size", size).WithDetail("max_size", r.maxSize)
+}
+
+// guard counts the bytes decompressed from an entry stream, so entries that
+// under-declare their size are stopped at the size limit, as in Extract.
+func (r *Reader) guard(src io.Reader) io.Reader {
+	return &guardedReader{r: io.LimitReader(src, r.maxSize+1), fsys: r}
+}
+
+type guardedReader struct {
+	r    io.Reader
+	fsys *Reader
+	read int64
+}
+
+func (g *guardedReader) Read(p []byte) (int, error) {
+	if g.read > g.fsys.maxSize {
+		return 0, g.fsys.bomb(g.read)
+	}
+	n, err := g.r.Read(p)
+	g.read += int64(n)
+	if over := g.read - g.fsys.maxSize; over > 0 {
+		return n - int(over), g.fsys.bomb(g.read)
+	}
+	return n, err
+}
+
+// ReadFile reads the named entry.
+func (r *Reader) ReadFile(name string) ([]byte, error) {
+	f, err := r.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return data, nil
+}
+
+// ReadDir reads the named directory, sorted by file name.
+func (r *Reader) ReadDir(name string) ([]fs.DirEntry, error) {
+	n, err := r.lookup("readdir", name, true)
+	if err != nil {
+		return nil, err
+	}
+	if !n.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return n.dirEntries(), nil
+}
+
+// Stat describes the named entry, following symlinks.
+func (r *Reader) Stat(name string) (fs.FileInfo, error) {
+	n, err := r.lookup("stat", name, true)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// Lstat describes the named entry without following a final symlink.
+func (r *Reader) Lstat(name string) (fs.FileInfo, error) {
+	n, err := r.lookup("lstat", name, false)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// ReadLink returns the target of the named symlink as stored in the archive.
+func (r *Reader) ReadLink(name string) (string, error) {
+	n, err := r.lookup("readlink", name, false)
+	if err != nil {
+		return "", err
+	}
+	if !n.isSymlink() {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	return n.link, nil
+}
+
+// node is an entry of the Reader tree. It serves as both fs.FileInfo and
+// fs.DirEntry.
+type node struct {
+	name     string // slash-separated path; "." for the root
+	mode     fs.FileMode
+	size     int64
+	modTime  time.Time
+	link     string // symlink target, or hard link target path
+	hardlink bool
+	ordinal  int   // position in the archive stream
+	offset   int64 // content offset in an uncompressed tar, or -1
+	children []*node
+}
+
+func (n *node) Name() string               { return path.Base(n.name) }
+func (n *node) Size() int64                { return n.size }
+func (n *node) Mode() fs.FileMode          { return n.mode }
+func (n *node) ModTime() time.Time         { return n.modTime }
+func (n *node) IsDir() bool                { return n.mode.IsDir() }
+func (n *node) Sys() any                   { return nil }
+func (n *node) Type() fs.FileMode          { return n.mode.Type() }
+func (n *node) Info() (fs.FileInfo, error) { return n, nil }
+func (n *node) String() string             { return fs.FormatFileInfo(n) }
+func (n *node) isSymlink() bool            { return n.mode&fs.ModeSymlink != 0 }
+
+func (n *node) dirEntries() []fs.DirEntry {
+	out := make([]fs.DirEntry, len(n.children))
+	for i, c := range n.children {
+		out[i] = c
+	}
+	return out
+}
+
+type dirFile struct {
+	n      *node
+	offset int
+}
+
+func (d *dirFile) Stat() (fs.FileInfo, error) { return d.n, nil }
+func (d *dirFile) Close() error               { return nil }
+
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.n.name, Err: errors.New("is a directory")}
+}
+
+func (d *dirFile) ReadDir(count int) ([]fs.DirEntry, error) {
+	entries := d.n.dirEntries()[d.offset:]
+	if count > 0 {
+		if len(entries) == 0 {
+			return nil, io.EOF
+		}
+		entries = entries[:min(count, len(entries))]
+	}
+	d.offset += len(entries)
+	return entries, nil
+}
+
+// streamFile reads an entry sequentially.
+type streamFile struct {
+	n     *node
+	r     io.Reader
+	close func() error
+}
+
+func (f *streamFile) Stat() (fs.FileInfo, error) { return f.n, nil }
+func (f *streamFile) Read(p []byte) (int, error) { return f.r.Read(p) }
+func (f *streamFile) Close() error               { return f.close() }
+
+// sectionFile reads an entry of an uncompressed tar in place, and also
+// supports Seek and ReadAt.
+type sectionFile struct {
+	n *node
+	*io.SectionReader
+}
+
+func (f *sectionFile) Stat() (fs.FileInfo, error) { return f.n, nil }
+func (f *sectionFile) Close() error               { return nil }
@@ -0,0 +1,214 @@
+package fulpack
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestOpenFS(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []string
+	}{
+		{"basic.tar", []string{"README.md", "data/sample.txt"}},
+		{"basic.tar.gz", []string{"README.md", "subdir/file3.txt"}},
+		{"basic.tar.xz", []string{"README.md", "subdir/file3.txt"}},
+		{"nested.zip", []string{"root.txt", "level1/level2/level3/deep.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			r, err := Open(filepath.Join(fixtureDir, tt.fixture))
+			if err != nil {
+				t.Fatalf("Open failed: %v", err)
+			}
+			defer r.Close()
+			if err := fstest.TestFS(r, tt.want...); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestOpenReadsContent(t *testing.T) {
+	src := writeTree(t, sampleTree)
+	for _, format := range []ArchiveFormat{ArchiveFormatTar, ArchiveFormatTarGz, ArchiveFormatZip} {
+		t.Run(string(format), func(t *testing.T) {
+			archive := filepath.Join(t.TempDir(), "sample."+string(format))
+			if _, err := Create(src, archive, format, nil); err != nil {
+				t.Fatal(err)
+			}
+			r, err := Open(archive)
+			if err != nil {
+				t.Fatalf("Open failed: %v", err)
+			}
+			defer r.Close()
+			if r.Format() != format {
+				t.Errorf("Format = %s, want %s", r.Format(), format)
+			}
+
+			var files []string
+			err = fs.WalkDir(r, ".", func(p string, d fs.DirEntry, err error) error {
+				if err == nil && d.Type().IsRegular() {
+					files = append(files, p)
+				}
+				return err
+			})
+			if err != nil {
+				t.Fatalf("WalkDir failed: %v", err)
+			}
+			for name, want := range sampleTree {
+				if !slices.Contains(files, name) {
+					t.Errorf("%s not walked: %v", name, files)
+				}
+				got, err := fs.ReadFile(r, name)
+				if err != nil || string(got) != want {
+					t.Errorf("ReadFile(%s) = %q, %v; want %q", name, got, err, want)
+				}
+			}
+
+			m := r.Manifest()
+			if m.Format != string(format) || m.Index == nil {
+				t.Fatalf("unexpected manifest %+v", m)
+			}
+			if i, ok := m.Index.ByPath["src/util/util.go"]; !ok || m.Entries[i].Path != "src/util/util.go" {
+				t.Error("manifest index does not resolve src/util/util.go")
+			}
+		})
+	}
+}
+
+func TestOpenLinks(t *testing.T) {
+	archive := buildTar(t, []tarEntry{
+		{name: "dir/", typ: tar.TypeDir},
+		{name: "dir/a.txt", body: "hello"},
+		{name: "alias", typ: tar.TypeSymlink, link: "dir"},
+		{name: "dir/self", typ: tar.TypeSymlink, link: "a.txt"},
+		{name: "hard.txt", typ: tar.TypeLink, link: "dir/a.txt"},
+		{name: "loop", typ: tar.TypeSymlink, link: "loop"},
+		{name: "escape", typ: tar.TypeSymlink, link: "../outside"},
+		{name: "../evil.txt", body: "x"},
+		{name: "implied/child.txt", body: "implied"},
+	})
+	r, err := Open(archive)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer r.Close()
+
+	for _, name := range []string{"alias/a.txt", "alias/self", "hard.txt"} {
+		if got, err := fs.ReadFile(r, name); err != nil || string(got) != "hello" {
+			t.Errorf("ReadFile(%s) = %q, %v", name, got, err)
+		}
+	}
+	if target, err := fs.ReadLink(r, "alias"); err != nil || target != "dir" {
+		t.Errorf("ReadLink = %q, %v", target, err)
+	}
+	if info, err := fs.Lstat(r, "alias"); err != nil || info.Mode()&fs.ModeSymlink == 0 {
+		t.Errorf("Lstat(alias) = %v, %v", info, err)
+	}
+	if info, err := fs.Stat(r, "alias"); err != nil || !info.IsDir() {
+		t.Errorf("Stat(alias) = %v, %v", info, err)
+	}
+	if info, err := fs.Stat(r, "implied"); err != nil || !info.IsDir() {
+		t.Errorf("missing parent directory not synthesized: %v, %v", info, err)
+	}
+
+	if _, err := r.Open("loop"); err == nil {
+		t.Error("expected an error for a symlink loop")
+	}
+	for _, name := range []string{"escape", "evil.txt", "../evil.txt"} {
+		if _, err := r.Open(name); err == nil {
+			t.Errorf("Open(%s) succeeded", name)
+		}
+	}
+	if _, err := r.Open("/dir/a.txt"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("expected fs.ErrInvalid for a rooted name, got %v", err)
+	}
+	if _, err := r.Open("dir/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got %v", err)
+	}
+	if _, ok := r.Manifest().Index.ByPath["../evil.txt"]; ok {
+		t.Error("unsafe entry listed in the manifest")
+	}
+}
+
+func TestOpenSeeksUncompressedTar(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "sample.tar")
+	if _, err := Create(writeTree(t, sampleTree), archive, ArchiveFormatTar, nil); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(archive)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer r.Close()
+	f, err := r.Open("docs/guide.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	s, ok := f.(io.ReadSeeker)
+	if !ok {
+		t.Fatal("uncompressed tar entries should be seekable")
+	}
+	if _, err := s.Seek(2, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if rest, _ := io.ReadAll(s); string(rest) != "ide\n" {
+		t.Errorf("read after seek = %q", rest)
+	}
+}
+
+func TestOpenMissingArchive(t *testing.T) {
+	if _, err := Open(filepath.Join(t.TempDir(), "missing.zip")); !errors.Is(err, ErrArchiveNotFound) {
+		t.Errorf("expected ARCHIVE_NOT_FOUND, got %v", err)
+	}
+}
+
+func TestOpenBombProtection(t *testing.T) {
+	body := strings.Repeat("0", 1000)
+
+	// A declared size over the limit fails at Open.
+	r, err := Open(buildTar(t, []tarEntry{{name: "big.txt", body: body}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	r.maxSize = 100
+	if _, err := r.Open("big.txt"); !errors.Is(err, ErrDecompressionBomb) {
+		t.Errorf("Open error = %v, want DECOMPRESSION_BOMB", err)
+	}
+
+	// A gzip trailer under-declaring the size is caught while reading.
+	archive := filepath.Join(t.TempDir(), "bomb.txt.gz")
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	gw.Write([]byte(body))
+	gw.Close()
+	data := buf.Bytes()
+	binary.LittleEndian.PutUint32(data[len(data)-4:], 10)
+	if err := os.WriteFile(archive, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	g, err := Open(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	g.maxSize = 100
+	got, err := fs.ReadFile(g, "bomb.txt")
+	if !errors.Is(err, ErrDecompressionBomb) || len(got) > 100 {
+		t.Errorf("ReadFile = %d bytes, %v; want at most 100 and DECOMPRESSION_BOMB", len(got), err)
+	}
+}
@@ -0,0 +1,585 @@
+package fulpack
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fulmenhq/crucible"
+	"github.com/fulmenhq/crucible/fulhash"
+	"github.com/fulmenhq/crucible/pathfinder"
+)
+
+// CreateOptionsSchemaPath is the embedded schema used to validate CreateOptions.
+const CreateOptionsSchemaPath = "library/fulpack/v1.0.0/create-options.schema.json"
+
+// ChecksumPAXKey is the PAX record under which Create stores the checksum of
+// each tar file entry. Zip and gzip entries carry the checksum in their
+// comment field instead. Checksums use the fulhash "<algorithm>:<hex>" form.
+const ChecksumPAXKey = "FULPACK.checksum"
+
+// NormalizedModTime is stamped on every entry so that archives of identical
+// content are byte-for-byte identical. It is the earliest time the zip
+// format can represent.
+var NormalizedModTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Create option defaults.
+// See: docs/standards/library/modules/fulpack.md#1-create---create-archive
+const (
+	DefaultCompressionLevel  = 6
+	DefaultChecksumAlgorithm = fulhash.SHA256
+)
+
+// Modes recorded when CreateOptions.PreservePermissions is false.
+const (
+	normalizedFileMode fs.FileMode = 0o644
+	normalizedDirMode  fs.FileMode = 0o755
+)
+
+type createSettings struct {
+	level          int
+	algorithm      fulhash.Algorithm
+	preservePerms  bool
+	followSymlinks bool
+	include        []string
+	exclude        []string
+}
+
+func resolveCreateOptions(opts *CreateOptions) (createSettings, error) {
+	s := createSettings{
+		level:         DefaultCompressionLevel,
+		algorithm:     DefaultChecksumAlgorithm,
+		preservePerms: true,
+	}
+	if opts == nil {
+		return s, nil
+	}
+	if err := crucible.ValidateSchemaValue(CreateOptionsSchemaPath, opts); err != nil {
+		return s, NewError(CodeInvalidOptions, OperationCreate, "invalid create options").Wrap(err)
+	}
+	if opts.CompressionLevel != nil {
+		s.level = int(*opts.CompressionLevel)
+	}
+	if opts.ChecksumAlgorithm != "" {
+		s.algorithm = fulhash.Algorithm(opts.ChecksumAlgorithm)
+		if !s.algorithm.IsValid() {
+			return s, NewError(CodeInvalidOptions, OperationCreate,
+				fmt.Sprintf("checksum algorithm %q is not provided by fulhash", opts.ChecksumAlgorithm)).
+				Wrap(fulhash.ErrUnsupportedAlgorithm)
+		}
+	}
+	if opts.PreservePermissions != nil {
+		s.preservePerms = *opts.PreservePermissions
+	}
+	if opts.FollowSymlinks != nil {
+		s.followSymlinks = *opts.FollowSymlinks
+	}
+	for _, patterns := range [][]string{opts.IncludePatterns, opts.ExcludePatterns} {
+		if err := pathfinder.ValidatePatterns(patterns); err != nil {
+			return s, NewError(CodeInvalidOptions, OperationCreate, "invalid glob pattern").Wrap(err)
+		}
+	}
+	s.include = opts.IncludePatterns
+	s.exclude = opts.ExcludePatterns
+	return s, nil
+}
+
+// sourceEntry is a filesystem object selected for archiving.
+type sourceEntry struct {
+	name string // slash-separated archive path, without trailing slash
+	file string // filesystem path the content is read from
+	typ  EntryType
+	mode fs.FileMode
+	size int64
+	link string // symlink target
+}
+
+// Create archives src into dest using format. src may be a directory, whose
+// contents are archived relative to it, or a single file. The gzip format
+// only accepts a single file, and read-only formats (tar.zst, tar.xz,
+// tar.bz2) are rejected with INVALID_ARCHIVE_FORMAT.
+//
+// Output is reproducible: entries are written in lexical order with
+// NormalizedModTime, uid/gid 0 and no owner names, so identical input trees
+// produce identical archives. Every file entry carries a fulhash checksum
+// (see ChecksumPAXKey) of exactly the bytes archived; a file modified while
+// it is archived fails Create with INVALID_PATH. Symlinks are archived as
+// links unless FollowSymlinks is set; links whose targets leave src are
+// rejected with SYMLINK_ESCAPE. Zip cannot store links, so they are left out
+// and each one is reported in ArchiveInfo.Warnings.
+//
+// The archive is written to a temporary file next to dest and renamed into
+// place, so a failed Create never leaves a partial archive behind.
+func Create(src, dest string, format ArchiveFormat, opts *CreateOptions) (*ArchiveInfo, error) {
+	if err := ValidateArchiveFormat(format); err != nil {
+		return nil, NewError(CodeInvalidArchiveFormat, OperationCreate, err.Error()).WithArchive(dest)
+	}
+	if isReadOnly(format) {
+		return nil, NewError(CodeInvalidArchiveFormat, OperationCreate,
+			fmt.Sprintf("format %s is read-only", format)).WithArchive(dest)
+	}
+	s, err := resolveCreateOptions(opts)
+	if err != nil {
+		return nil, withArchive(err, dest)
+	}
+
+	destDir := filepath.Dir(dest)
+	tmp, err := os.CreateTemp(destDir, "."+filepath.Base(dest)+".*.tmp")
+	if err != nil {
+		return nil, ioError(err, OperationCreate, CodeInvalidPath, "cannot create archive").WithArchive(dest)
+	}
+	tmpName := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	c := &collector{settings: s, format: format, skip: map[string]bool{}}
+	for _, p := range []string{dest, tmpName} {
+		if abs, err := filepath.Abs(p); err == nil {
+			c.skip[abs] = true
+		}
+	}
+	entries, err := c.collect(src)
+	if err != nil {
+		return nil, withArchive(err, dest)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+
+	switch format {
+	case ArchiveFormatTar:
+		err = writeTar(tmp, entries, s)
+	case ArchiveFormatTarGz:
+		err = writeTarGz(tmp, entries, s)
+	case ArchiveFormatZip:
+		err = writeZip(tmp, entries, s)
+	case ArchiveFormatGzip:
+		err = writeGzip(tmp, entries, s)
+	}
+	if err != nil {
+		return nil, withArchive(err, dest)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, ioError(err, OperationCreate, CodeInvalidPath, "cannot write archive").WithArchive(dest)
+	}
+	if err := os.Chmod(tmpName, 0o644); err != nil {
+		return nil, ioError(err, OperationCreate, CodeInvalidPath, "cannot write archive").WithArchive(dest)
+	}
+	fi, err := os.Stat(tmpName)
+	if err != nil {
+		return nil, ioError(err, OperationCreate, CodeInvalidPath, "cannot write archive").WithArchive(dest)
+	}
+	if err := os.Rename(tmpName, dest); err != nil {
+		return nil, ioError(err, OperationCreate, CodeInvalidPath, "cannot write archive").WithArchive(dest)
+	}
+	committed = true
+
+	var total int64
+	checksummed := false
+	for _, e := range entries {
+		total += e.size
+		checksummed = checksummed || e.typ == EntryTypeFile
+	}
+	info := &ArchiveInfo{
+		Format:         string(format),
+		EntryCount:     int64(len(entries)),
+		TotalSize:      total,
+		CompressedSize: fi.Size(),
+		Compression:    compressionOf(format),
+		Created:        time.Now().UTC().Format(time.RFC3339),
+	}
+	ratio := 1.0
+	if format != ArchiveFormatTar && fi.Size() > 0 {
+		ratio = float64(total) / float64(fi.Size())
+	}
+	info.CompressionRatio = &ratio
+	info.Warnings = c.warnings
+	info.HasChecksums = &checksummed
+	if checksummed {
+		info.ChecksumAlgorithm = string(s.algorithm)
+	}
+	return info, nil
+}
+
+func withArchive(err error, archive string) error {
+	var fe *Error
+	if errors.As(err, &fe) && fe.Archive == "" {
+		fe.Archive = archive
+	}
+	return err
+}
+
+// collector walks the source tree and selects the entries to archive.
+type collector struct {
+	settings createSettings
+	format   ArchiveFormat
+	skip     map[string]bool // absolute paths never archived (the output itself)
+	warnings []string        // entries left out of the archive
+}
+
+func (c *collector) collect(src string) ([]sourceEntry, error) {
+	info, err := c.stat(src)
+	if err != nil {
+		return nil, ioError(err, OperationCreate, CodeInvalidPath, "cannot read source").WithPath(src)
+	}
+
+	if !info.IsDir() {
+		if !info.Mode().IsRegular() {
+			return nil, NewError(CodeInvalidPath, OperationCreate, "source is not a regular file or directory").WithPath(src)
+		}
+		name := filepath.Base(src)
+		if !c.selected(name) || !c.included(name) {
+			return nil, nil
+		}
+		return []sourceEntry{c.fileEntry(name, src, info)}, nil
+	}
+
+	if c.format == ArchiveFormatGzip {
+		return nil, NewError(CodeInvalidOptions, OperationCreate, "gzip format archives a single file, not a directory").WithPath(src)
+	}
+	var ancestors []string
+	if c.settings.followSymlinks {
+		real, err := filepath.EvalSymlinks(src)
+		if err != nil {
+			return nil, ioError(err, OperationCreate, CodeInvalidPath, "cannot resolve source").WithPath(src)
+		}
+		ancestors = []string{real}
+	}
+	entries, _, err := c.walk(src, "", ancestors)
+	return entries, err
+}
+
+func (c *collector) stat(name string) (fs.FileInfo, error) {
+	if c.settings.followSymlinks {
+		return os.Stat(name)
+	}
+	return os.Lstat(name)
+}
+
+// selected reports whether name survives the exclude patterns, which match
+// as in pathfinder.MatchGlob.
+func (c *collector) selected(name string) bool {
+	return !pathfinder.MatchAny(c.settings.exclude, name)
+}
+
+// included reports whether a file named name matches the include patterns.
+func (c *collector) included(name string) bool {
+	return len(c.settings.include) == 0 || pathfinder.MatchAny(c.settings.include, name)
+}
+
+// walk collects the entries below dir, whose archive path is rel. It also
+// reports whether any file below dir was selected, so that directories
+// emptied by include patterns are left out.
+func (c *collector) walk(dir, rel string, ancestors []string) ([]sourceEntry, bool, error) {
+	children, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, false, ioError(err, OperationCreate, CodeInvalidPath, "cannot read directory").WithPath(rel)
+	}
+
+	var entries []sourceEntry
+	var selectedFiles bool
+	for _, child := range children {
+		file := filepath.Join(dir, child.Name())
+		name := path.Join(rel, child.Name())
+		if abs, err := filepath.Abs(file); err == nil && c.skip[abs] {
+			continue
+		}
+		if !c.selected(name) {
+			continue
+		}
+
+		info, err := os.Lstat(file)
+		if err != nil {
+			return nil, false, ioError(err, OperationCreate, CodeInvalidPath, "cannot read source").WithPath(name)
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if !c.settings.followSymlinks {
+				entry, err := c.symlinkEntry(name, file)
+				if err != nil {
+					return nil, false, err
+				}
+				if !c.included(name) {
+					continue
+				}
+				if c.format == ArchiveFormatZip {
+					c.warnings = append(c.warnings, fmt.Sprintf("%s: symlink skipped, zip cannot store links", name))
+					continue
+				}
+				entries = append(entries, entry)
+				selectedFiles = true
+				continue
+			}
+			if info, err = os.Stat(file); err != nil {
+				return nil, false, ioError(err, OperationCreate, CodeInvalidPath, "cannot follow symlink").WithPath(name)
+			}
+		}
+
+		switch {
+		case info.IsDir():
+			sub := ancestors
+			if c.settings.followSymlinks {
+				real, err := filepath.EvalSymlinks(file)
+				if err != nil {
+					return nil, false, ioError(err, OperationCreate, CodeInvalidPath, "cannot follow symlink").WithPath(name)
+				}
+				for _, a := range ancestors {
+					if a == real {
+						return nil, false, NewError(CodeInvalidPath, OperationCreate, "symlink cycle").WithPath(name)
+					}
+				}
+				sub = append(ancestors[:len(ancestors):len(ancestors)], real)
+			}
+			below, found, err := c.walk(file, name, sub)
+			if err != nil {
+				return nil, false, err
+			}
+			if found || len(c.settings.include) == 0 {
+				entries = append(entries, c.dirEntry(name, info))
+				entries = append(entries, below...)
+				selectedFiles = selectedFiles || found
+			}
+		case info.Mode().IsRegular():
+			if c.included(name) {
+				entries = append(entries, c.fileEntry(name, file, info))
+				selectedFiles = true
+			}
+		}
+		// Devices, sockets and named pipes are never archived.
+	}
+	return entries, selectedFiles, nil
+}
+
+func (c *collector) fileEntry(name, file string, info fs.FileInfo) sourceEntry {
+	mode := normalizedFileMode
+	if c.settings.preservePerms {
+		mode = info.Mode().Perm()
+	}
+	return sourceEntry{name: name, file: file, typ: EntryTypeFile, mode: mode, size: info.Size()}
+}
+
+func (c *collector) dirEntry(name string, info fs.FileInfo) sourceEntry {
+	mode := normalizedDirMode
+	if c.settings.preservePerms {
+		mode = info.Mode().Perm()
+	}
+	return sourceEntry{name: name, typ: EntryTypeDirectory, mode: mode}
+}
+
+// symlinkEntry records a symlink as a link entry. Links that would resolve
+// outside the source tree are rejected.
+func (c *collector) symlinkEntry(name, file string) (sourceEntry, error) {
+	target, err := os.Readlink(file)
+	if err != nil {
+		return sourceEntry{}, ioError(err, OperationCreate, CodeInvalidPath, "cannot read symlink").WithPath(name)
+	}
+	if err := checkLinkTarget(nil, name, filepath.ToSlash(target), false, OperationCreate); err != nil {
+		return sourceEntry{}, err
+	}
+	return sourceEntry{name: name, typ: EntryTypeSymlink, mode: fs.ModePerm, link: filepath.ToSlash(target)}, nil
+}
+
+// openSource opens source files; tests replace it to modify a file while
+// it is archived.
+var openSource = func(name string) (io.ReadSeekCloser, error) { return os.Open(name) }
+
+// sourceFile is an open source file whose first size bytes have been
+// hashed. Formats store the checksum ahead of the content, so the file is
+// hashed, rewound and copied through the same handle.
+type sourceFile struct {
+	f   io.ReadSeekCloser
+	e   sourceEntry
+	alg fulhash.Algorithm
+	sum string // Formatted fulhash checksum
+}
+
+// openSourceFile opens the source file of e and computes the checksum of the
+// e.size bytes Create archives.
+func openSourceFile(e sourceEntry, alg fulhash.Algorithm) (*sourceFile, error) {
+	f, err := openSource(e.file)
+	if err != nil {
+		return nil, err
+	}
+	sf := &sourceFile{f: f, e: e, alg: alg}
+	hasher, err := fulhash.NewStreamHasher(alg)
+	if err == nil {
+		err = sf.read(hasher)
+	}
+	if err == nil {
+		sf.sum = hasher.Sum().Formatted
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return sf, nil
+}
+
+// read copies exactly e.size bytes of the file to w.
+func (sf *sourceFile) read(w io.Writer) error {
+	if _, err := io.CopyN(w, sf.f, sf.e.size); err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("file shrank while archiving: %w", io.ErrUnexpectedEOF)
+		}
+		return err
+	}
+	return nil
+}
+
+// copyTo writes the hashed bytes to w and closes the file. It fails if the
+// bytes written no longer match the checksum, so a file modified while it
+// is archived fails Create rather than yielding an archive Verify rejects.
+func (sf *sourceFile) copyTo(w io.Writer) error {
+	defer sf.f.Close()
+	hasher, err := fulhash.NewStreamHasher(sf.alg)
+	if err != nil {
+		return err
+	}
+	if err := sf.read(io.MultiWriter(w, hasher)); err != nil {
+		return err
+	}
+	if hasher.Sum().Formatted != sf.sum {
+		return errors.New("file changed while archiving")
+	}
+	return nil
+}
+
+func readError(err error, name string) *Error {
+	return ioError(err, OperationCreate, CodeInvalidPath, "cannot read source").WithPath(name)
+}
+
+func writeError(err error) *Error {
+	return ioError(err, OperationCreate, CodeInvalidPath, "cannot write archive")
+}
+
+func writeTar(w io.Writer, entries []sourceEntry, s createSettings) error {
+	tw := tar.NewWriter(w)
+	for _, e := range entries {
+		var src *sourceFile
+		hdr := &tar.Header{
+			Name:    e.name,
+			Mode:    int64(e.mode),
+			ModTime: NormalizedModTime,
+			Format:  tar.FormatPAX,
+		}
+		switch e.typ {
+		case EntryTypeDirectory:
+			hdr.Typeflag = tar.TypeDir
+			hdr.Name += "/"
+		case EntryTypeSymlink:
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = e.link
+		default:
+			var err error
+			if src, err = openSourceFile(e, s.algorithm); err != nil {
+				return readError(err, e.name)
+			}
+			hdr.Typeflag = tar.TypeReg
+			hdr.Size = e.size
+			hdr.PAXRecords = map[string]string{ChecksumPAXKey: src.sum}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			if src != nil {
+				src.f.Close()
+			}
+			return writeError(err).WithPath(e.name)
+		}
+		if src != nil {
+			if err := src.copyTo(tw); err != nil {
+				return readError(err, e.name)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return writeError(err)
+	}
+	return nil
+}
+
+func writeTarGz(w io.Writer, entries []sourceEntry, s createSettings) error {
+	gw, err := gzip.NewWriterLevel(w, s.level)
+	if err != nil {
+		return NewError(CodeInvalidOptions, OperationCreate, "invalid compression level").Wrap(err)
+	}
+	if err := writeTar(gw, entries, s); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return writeError(err)
+	}
+	return nil
+}
+
+func writeZip(w io.Writer, entries []sourceEntry, s createSettings) error {
+	zw := zip.NewWriter(w)
+	zw.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(out, s.level)
+	})
+	for _, e := range entries {
+		hdr := &zip.FileHeader{Name: e.name, Modified: NormalizedModTime}
+		if e.typ == EntryTypeDirectory {
+			hdr.Name += "/"
+			hdr.Method = zip.Store
+			hdr.SetMode(fs.ModeDir | e.mode)
+			if _, err := zw.CreateHeader(hdr); err != nil {
+				return writeError(err).WithPath(e.name)
+			}
+			continue
+		}
+
+		src, err := openSourceFile(e, s.algorithm)
+		if err != nil {
+			return readError(err, e.name)
+		}
+		hdr.Method = zip.Deflate
+		hdr.Comment = src.sum
+		hdr.SetMode(e.mode)
+		fw, err := zw.CreateHeader(hdr)
+		if err != nil {
+			src.f.Close()
+			return writeError(err).WithPath(e.name)
+		}
+		if err := src.copyTo(fw); err != nil {
+			return readError(err, e.name)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return writeError(err)
+	}
+	return nil
+}
+
+func writeGzip(w io.Writer, entries []sourceEntry, s createSettings) error {
+	gw, err := gzip.NewWriterLevel(w, s.level)
+	if err != nil {
+		return NewError(CodeInvalidOptions, OperationCreate, "invalid compression level").Wrap(err)
+	}
+	if len(entries) == 1 {
+		e := entries[0]
+		src, err := openSourceFile(e, s.algorithm)
+		if err != nil {
+			return readError(err, e.name)
+		}
+		gw.Header = gzip.Header{Name: e.name, Comment: src.sum, ModTime: NormalizedModTime}
+		if err := src.copyTo(gw); err != nil {
+			return readError(err, e.name)
+		}
+	}
+	if err := gw.Close(); err != nil {
+		return writeError(err)
+	}
+	return nil
+}
@@ -0,0 +1,397 @@
+package fulpack
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fulmenhq/crucible/fulhash"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+// writeTree creates files (path -> content) below a fresh temp directory.
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func readTar(t *testing.T, r io.Reader) []*tar.Header {
+	t.Helper()
+	tr := tar.NewReader(r)
+	var hdrs []*tar.Header
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return hdrs
+		}
+		if err != nil {
+			t.Fatalf("reading tar: %v", err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			data, _ := io.ReadAll(tr)
+			if ok, err := fulhash.Verify(data, hdr.PAXRecords[ChecksumPAXKey]); err != nil || !ok {
+				t.Errorf("%s: checksum %q does not match content (%v)", hdr.Name, hdr.PAXRecords[ChecksumPAXKey], err)
+			}
+		}
+		hdrs = append(hdrs, hdr)
+	}
+}
+
+func names(hdrs []*tar.Header) []string {
+	out := make([]string, len(hdrs))
+	for i, h := range hdrs {
+		out[i] = h.Name
+	}
+	return out
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+var sampleTree = map[string]string{
+	"README.md":        "# sample\n",
+	"src/main.go":      "package main\n",
+	"src/util/util.go": "package util\n",
+	"src/.git/HEAD":    "ref: refs/heads/main\n",
+	"docs/guide.md":    "guide\n",
+}
+
+func TestCreateTar(t *testing.T) {
+	src := writeTree(t, sampleTree)
+	dest := filepath.Join(t.TempDir(), "out.tar")
+
+	info, err := Create(src, dest, ArchiveFormatTar, &CreateOptions{PreservePermissions: ptr(false)})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	f, err := os.Open(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	hdrs := readTar(t, f)
+
+	want := []string{"README.md", "docs/", "docs/guide.md", "src/", "src/.git/", "src/.git/HEAD", "src/main.go", "src/util/", "src/util/util.go"}
+	if got := names(hdrs); !equalStrings(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+	for _, h := range hdrs {
+		if !h.ModTime.Equal(NormalizedModTime) || h.Uid != 0 || h.Gid != 0 || h.Uname != "" || h.Gname != "" {
+			t.Errorf("%s: header not normalized: mtime=%v uid=%d gid=%d", h.Name, h.ModTime, h.Uid, h.Gid)
+		}
+		wantMode := int64(0o644)
+		if h.Typeflag == tar.TypeDir {
+			wantMode = 0o755
+		}
+		if h.Mode != wantMode {
+			t.Errorf("%s: mode = %o, want %o", h.Name, h.Mode, wantMode)
+		}
+	}
+
+	if info.EntryCount != int64(len(want)) || info.Format != "tar" || *info.CompressionRatio != 1.0 {
+		t.Errorf("unexpected info: %+v", info)
+	}
+	if !*info.HasChecksums || info.ChecksumAlgorithm != "sha256" {
+		t.Errorf("checksums = %v/%q, want sha256", *info.HasChecksums, info.ChecksumAlgorithm)
+	}
+}
+
+func TestCreateReproducible(t *testing.T) {
+	for _, format := range []ArchiveFormat{ArchiveFormatTar, ArchiveFormatTarGz, ArchiveFormatZip} {
+		t.Run(string(format), func(t *testing.T) {
+			var outputs [][]byte
+			for i := 0; i < 2; i++ {
+				src := writeTree(t, sampleTree)
+				stamp := time.Now().Add(time.Duration(i) * time.Hour)
+				os.Chtimes(filepath.Join(src, "README.md"), stamp, stamp)
+				dest := filepath.Join(t.TempDir(), "out")
+				if _, err := Create(src, dest, format, nil); err != nil {
+					t.Fatalf("Create failed: %v", err)
+				}
+				data, err := os.ReadFile(dest)
+				if err != nil {
+					t.Fatal(err)
+				}
+				outputs = append(outputs, data)
+			}
+			if !bytes.Equal(outputs[0], outputs[1]) {
+				t.Error("archives of identical trees differ")
+			}
+		})
+	}
+}
+
+func TestCreatePatterns(t *testing.T) {
+	src := writeTree(t, sampleTree)
+	dest := filepath.Join(t.TempDir(), "out.tar.gz")
+
+	_, err := Create(src, dest, ArchiveFormatTarGz, &CreateOptions{
+		IncludePatterns:   []string{"**/*.go", "*.md"},
+		ExcludePatterns:   []string{"**/.git", "docs"},
+		ChecksumAlgorithm: "xxh3-128",
+		CompressionLevel:  ptr(int64(9)),
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	f, err := os.Open(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hdrs := readTar(t, gr)
+
+	want := []string{"README.md", "src/", "src/main.go", "src/util/", "src/util/util.go"}
+	if got := names(hdrs); !equalStrings(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+}
+
+func TestCreateZip(t *testing.T) {
+	src := writeTree(t, sampleTree)
+	dest := filepath.Join(t.TempDir(), "out.zip")
+
+	info, err := Create(src, dest, ArchiveFormatZip, &CreateOptions{ExcludePatterns: []string{".git"}})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	zr, err := zip.OpenReader(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+
+	if int64(len(zr.File)) != info.EntryCount {
+		t.Errorf("zip has %d entries, info reports %d", len(zr.File), info.EntryCount)
+	}
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() {
+			continue
+		}
+		if zf.Mode().Perm() != 0o600 {
+			t.Errorf("%s: mode = %v, want preserved 0600", zf.Name, zf.Mode())
+		}
+		rc, _ := zf.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		if ok, err := fulhash.Verify(data, zf.Comment); err != nil || !ok {
+			t.Errorf("%s: checksum %q does not match content", zf.Name, zf.Comment)
+		}
+	}
+}
+
+func TestCreateGzip(t *testing.T) {
+	src := writeTree(t, map[string]string{"app.log": "line one\nline two\n"})
+	dest := filepath.Join(t.TempDir(), "app.log.gz")
+
+	if _, err := Create(filepath.Join(src, "app.log"), dest, ArchiveFormatGzip, nil); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	f, err := os.Open(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(gr)
+	if gr.Name != "app.log" || string(data) != "line one\nline two\n" {
+		t.Errorf("got %q named %q", data, gr.Name)
+	}
+	if ok, _ := fulhash.Verify(data, gr.Comment); !ok {
+		t.Errorf("checksum %q does not match content", gr.Comment)
+	}
+
+	if _, err := Create(src, dest+"2", ArchiveFormatGzip, nil); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("gzip of a directory: error = %v, want INVALID_OPTIONS", err)
+	}
+}
+
+func TestCreateSymlinks(t *testing.T) {
+	src := writeTree(t, map[string]string{"data/real.txt": "real\n"})
+	if err := os.Symlink("data/real.txt", filepath.Join(src, "link.txt")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	dir := t.TempDir()
+
+	t.Run("stored as links", func(t *testing.T) {
+		dest := filepath.Join(dir, "links.tar")
+		if _, err := Create(src, dest, ArchiveFormatTar, nil); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		f, _ := os.Open(dest)
+		defer f.Close()
+		for _, h := range readTar(t, f) {
+			if h.Name == "link.txt" && (h.Typeflag != tar.TypeSymlink || h.Linkname != "data/real.txt") {
+				t.Errorf("link.txt stored as type %c -> %q", h.Typeflag, h.Linkname)
+			}
+		}
+	})
+
+	t.Run("followed", func(t *testing.T) {
+		dest := filepath.Join(dir, "followed.tar")
+		if _, err := Create(src, dest, ArchiveFormatTar, &CreateOptions{FollowSymlinks: ptr(true)}); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		f, _ := os.Open(dest)
+		defer f.Close()
+		for _, h := range readTar(t, f) {
+			if h.Name == "link.txt" && (h.Typeflag != tar.TypeReg || h.Size != 5) {
+				t.Errorf("link.txt stored as type %c size %d, want regular file", h.Typeflag, h.Size)
+			}
+		}
+	})
+
+	t.Run("skipped by zip", func(t *testing.T) {
+		dest := filepath.Join(dir, "links.zip")
+		info, err := Create(src, dest, ArchiveFormatZip, nil)
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if len(info.Warnings) != 1 || !strings.HasPrefix(info.Warnings[0], "link.txt:") {
+			t.Errorf("Warnings = %q, want one for link.txt", info.Warnings)
+		}
+		zr, err := zip.OpenReader(dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer zr.Close()
+		for _, zf := range zr.File {
+			if zf.Name == "link.txt" {
+				t.Error("link.txt archived in zip")
+			}
+		}
+	})
+
+	t.Run("escape rejected", func(t *testing.T) {
+		if err := os.Symlink("../../etc/passwd", filepath.Join(src, "data", "escape")); err != nil {
+			t.Fatal(err)
+		}
+		dest := filepath.Join(dir, "escape.tar")
+		if _, err := Create(src, dest, ArchiveFormatTar, nil); !errors.Is(err, ErrSymlinkEscape) {
+			t.Errorf("error = %v, want SYMLINK_ESCAPE", err)
+		}
+		if _, err := os.Stat(dest); !os.IsNotExist(err) {
+			t.Error("failed Create left an archive behind")
+		}
+	})
+}
+
+func TestCreateInvalidOptions(t *testing.T) {
+	src := writeTree(t, sampleTree)
+	dest := filepath.Join(t.TempDir(), "out.tar")
+
+	for name, opts := range map[string]*CreateOptions{
+		"compression level": {CompressionLevel: ptr(int64(10))},
+		"extension algo":    {ChecksumAlgorithm: "md5"},
+		"bad pattern":       {IncludePatterns: []string{"[a-"}},
+	} {
+		if _, err := Create(src, dest, ArchiveFormatTar, opts); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("%s: error = %v, want INVALID_OPTIONS", name, err)
+		}
+	}
+	if _, err := Create(src, dest, "rar", nil); !errors.Is(err, ErrInvalidArchiveFormat) {
+		t.Errorf("error = %v, want INVALID_ARCHIVE_FORMAT", err)
+	}
+}
+
+// mutatingFile serves one content until it is rewound, then another of the
+// same length, like a file rewritten in place while it is archived.
+type mutatingFile struct {
+	*strings.Reader
+	after string
+}
+
+func (f *mutatingFile) Seek(offset int64, whence int) (int64, error) {
+	f.Reader = strings.NewReader(f.after)
+	return f.Reader.Seek(offset, whence)
+}
+
+func (f *mutatingFile) Close() error { return nil }
+
+func TestCreateFileChangedWhileArchiving(t *testing.T) {
+	src := writeTree(t, map[string]string{"data.txt": "before\n"})
+	defer func(orig func(string) (io.ReadSeekCloser, error)) { openSource = orig }(openSource)
+	openSource = func(string) (io.ReadSeekCloser, error) {
+		return &mutatingFile{Reader: strings.NewReader("before\n"), after: "after!\n"}, nil
+	}
+
+	for _, format := range []ArchiveFormat{ArchiveFormatTar, ArchiveFormatZip, ArchiveFormatGzip} {
+		source := src
+		if format == ArchiveFormatGzip {
+			source = filepath.Join(src, "data.txt")
+		}
+		dest := filepath.Join(t.TempDir(), "out."+string(format))
+		_, err := Create(source, dest, format, nil)
+		if err == nil || !strings.Contains(err.Error(), "file changed while archiving") {
+			t.Errorf("%s: Create error = %v, want a changed-file error", format, err)
+		}
+	}
+}
+
+func TestCreatePatternsAnchored(t *testing.T) {
+	src := writeTree(t, sampleTree)
+	dir := t.TempDir()
+
+	tests := []struct {
+		name string
+		opts *CreateOptions
+		want []string
+	}{
+		{"top-level include", &CreateOptions{IncludePatterns: []string{"*.md"}}, []string{"README.md"}},
+		{"any-depth include", &CreateOptions{IncludePatterns: []string{"**/*.md"}}, []string{"README.md", "docs/", "docs/guide.md"}},
+		{"top-level exclude", &CreateOptions{IncludePatterns: []string{"**/HEAD"}, ExcludePatterns: []string{".git"}}, []string{"src/", "src/.git/", "src/.git/HEAD"}},
+		{"any-depth exclude", &CreateOptions{IncludePatterns: []string{"**/HEAD"}, ExcludePatterns: []string{"**/.git"}}, nil},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := filepath.Join(dir, fmt.Sprintf("out%d.tar", i))
+			if _, err := Create(src, dest, ArchiveFormatTar, tt.opts); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+			f, err := os.Open(dest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			if got := names(readTar(t, f)); !equalStrings(got, tt.want) {
+				t.Errorf("entries = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package fulpack
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/fulmenhq/crucible/fulhash"
+)
+
+// Diff compares the entries of the base and target archives, detecting each
+// format from the archive content, so artifacts can be compared across
+// formats.
+//
+// Every file is read and its content hashed with SHA-256, whether or not the
+// archive embeds checksums; ArchiveEntry.Checksum in the result carries the
+// computed digest. Entries present in both archives are reported as
+// modified when their content or entry type differs, as mode changed when
+// their permissions differ, and as symlink retargeted when their link target
+// differs; an entry can appear in more than one of these lists. Paths are
+// normalized as by Scan, and every list is sorted by path.
+//
+// Each archive is held to DefaultMaxEntries and DefaultMaxSize. Unlike
+// Verify, Diff does not judge path safety: use Verify on the target for
+// that.
+func Diff(base, target string) (*ArchiveDiff, error) {
+	before, err := diffEntries(base)
+	if err != nil {
+		return nil, err
+	}
+	after, err := diffEntries(target)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &ArchiveDiff{
+		Base:              base,
+		Target:            target,
+		Added:             []ArchiveEntry{},
+		Removed:           []ArchiveEntry{},
+		Modified:          []EntryChange{},
+		ModeChanged:       []EntryChange{},
+		SymlinkRetargeted: []EntryChange{},
+	}
+	for _, p := range slices.Sorted(maps.Keys(before)) {
+		b := before[p]
+		a, ok := after[p]
+		if !ok {
+			d.Removed = append(d.Removed, b)
+			continue
+		}
+		change := EntryChange{Path: p, Before: b, After: a}
+		changed := false
+		if a.Type != b.Type || !equalPtr(a.Checksum, b.Checksum) {
+			d.Modified = append(d.Modified, change)
+			changed = true
+		}
+		if a.Mode != nil && b.Mode != nil && *a.Mode != *b.Mode {
+			d.ModeChanged = append(d.ModeChanged, change)
+			changed = true
+		}
+		if a.Type == b.Type && a.Type == string(EntryTypeSymlink) && !equalPtr(a.SymlinkTarget, b.SymlinkTarget) {
+			d.SymlinkRetargeted = append(d.SymlinkRetargeted, change)
+			changed = true
+		}
+		if !changed {
+			d.UnchangedCount++
+		}
+	}
+	for _, p := range slices.Sorted(maps.Keys(after)) {
+		if _, ok := before[p]; !ok {
+			d.Added = append(d.Added, after[p])
+		}
+	}
+	d.Identical = len(d.Added)+len(d.Removed)+len(d.Modified)+len(d.ModeChanged)+len(d.SymlinkRetargeted) == 0
+	return d, nil
+}
+
+// diffEntries reads archive into entries keyed by path, with the content
+// digest of every file. Later entries replace earlier ones with the same
+// path, and hard links take the digest of their target.
+func diffEntries(archive string) (map[string]ArchiveEntry, *Error) {
+	r, _, oerr := openArchive(archive, "", OperationDiff)
+	if oerr != nil {
+		return nil, oerr
+	}
+	defer r.close()
+
+	entries := map[string]ArchiveEntry{}
+	var total int64
+	for index := 0; ; index++ {
+		h, err := r.next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, corrupt(err, OperationDiff).WithArchive(archive).WithDetail("entry_index", index)
+		}
+		if int64(index) >= DefaultMaxEntries {
+			return nil, NewError(CodeDecompressionBomb, OperationDiff,
+				fmt.Sprintf("archive has more than %d entries", DefaultMaxEntries)).WithArchive(archive).
+				WithDetail("entry_index", index).WithDetail("max_entries", DefaultMaxEntries)
+		}
+		name := scanPath(h.name)
+		if h.typ == "" || name == "" {
+			continue
+		}
+
+		e := newArchiveEntry(name, h, true)
+		e.Checksum = nil
+		switch {
+		case h.hardlink:
+			if linked, ok := entries[scanPath(h.link)]; ok {
+				e.Size, e.Checksum = linked.Size, linked.Checksum
+			}
+		case h.typ == EntryTypeFile:
+			src, err := r.open()
+			if err != nil {
+				return nil, corrupt(err, OperationDiff).WithArchive(archive).WithPath(name)
+			}
+			budget := DefaultMaxSize - total
+			hasher, _ := fulhash.NewStreamHasher(fulhash.SHA256)
+			n, err := io.Copy(hasher, io.LimitReader(src, budget+1))
+			total += n
+			if n > budget {
+				return nil, NewError(CodeDecompressionBomb, OperationDiff, "archive exceeds maximum size limit").
+					WithArchive(archive).WithDetail("actual_size", total).WithDetail("max_size", DefaultMaxSize)
+			}
+			if err != nil {
+				return nil, corrupt(err, OperationDiff).WithArchive(archive).WithPath(name)
+			}
+			digest := hasher.Sum().Hex
+			e.Size, e.Checksum = n, &digest
+		}
+		entries[name] = e
+	}
+}
+
+func equalPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,102 @@
+package fulpack
+
+import (
+	"archive/tar"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/fulmenhq/crucible"
+)
+
+func changePaths(changes []EntryChange) []string {
+	out := make([]string, len(changes))
+	for i, c := range changes {
+		out[i] = c.Path
+	}
+	return out
+}
+
+func TestDiff(t *testing.T) {
+	base := buildTar(t, []tarEntry{
+		{name: "bin/", typ: tar.TypeDir},
+		{name: "bin/tool", body: "v1"},
+		{name: "README.md", body: "readme"},
+		{name: "current", typ: tar.TypeSymlink, link: "bin/tool"},
+		{name: "legacy.txt", body: "old"},
+		{name: "same.txt", body: "same"},
+	})
+	target := buildTar(t, []tarEntry{
+		{name: "bin/", typ: tar.TypeDir},
+		{name: "bin/tool", body: "v2"},
+		{name: "README.md", body: "readme", mode: 0o755},
+		{name: "current", typ: tar.TypeSymlink, link: "bin/tool2"},
+		{name: "same.txt", body: "same"},
+		{name: "NOTICE", body: "notice"},
+	})
+
+	d, err := Diff(base, target)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if d.Identical {
+		t.Error("archives reported identical")
+	}
+	if got := entryPaths(d.Added); !equalStrings(got, []string{"NOTICE"}) {
+		t.Errorf("added = %v", got)
+	}
+	if got := entryPaths(d.Removed); !equalStrings(got, []string{"legacy.txt"}) {
+		t.Errorf("removed = %v", got)
+	}
+	if got := changePaths(d.Modified); !equalStrings(got, []string{"bin/tool"}) {
+		t.Errorf("modified = %v", got)
+	}
+	if got := changePaths(d.ModeChanged); !equalStrings(got, []string{"README.md"}) {
+		t.Errorf("mode_changed = %v", got)
+	}
+	if got := changePaths(d.SymlinkRetargeted); !equalStrings(got, []string{"current"}) {
+		t.Errorf("symlink_retargeted = %v", got)
+	}
+	if d.UnchangedCount != 2 {
+		t.Errorf("unchanged_count = %d, want 2", d.UnchangedCount)
+	}
+	if c := d.Modified[0]; c.Before.Checksum == nil || c.After.Checksum == nil || *c.Before.Checksum == *c.After.Checksum {
+		t.Errorf("modified entry must carry differing digests: %+v", c)
+	}
+	if err := crucible.ValidateSchemaValue("library/fulpack/v1.0.0/archive-diff.schema.json", d); err != nil {
+		t.Errorf("diff does not match schema: %v", err)
+	}
+}
+
+func TestDiffAcrossFormats(t *testing.T) {
+	src := writeTree(t, sampleTree)
+	tarball := filepath.Join(t.TempDir(), "sample.tar.gz")
+	zipped := filepath.Join(t.TempDir(), "sample.zip")
+	for archive, format := range map[string]ArchiveFormat{tarball: ArchiveFormatTarGz, zipped: ArchiveFormatZip} {
+		if _, err := Create(src, archive, format, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d, err := Diff(tarball, zipped)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if !d.Identical || d.UnchangedCount == 0 {
+		t.Errorf("expected identical archives, got %+v", d)
+	}
+
+	d, err = Diff(filepath.Join(fixtureDir, "basic.tar.zst"), filepath.Join(fixtureDir, "basic.tar.xz"))
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if !d.Identical {
+		t.Errorf("basic fixtures differ: %+v", d)
+	}
+}
+
+func TestDiffMissingArchive(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.tar.gz")
+	if _, err := Diff(filepath.Join(fixtureDir, "basic.tar"), missing); !errors.Is(err, ErrArchiveNotFound) {
+		t.Errorf("expected ARCHIVE_NOT_FOUND, got %v", err)
+	}
+}
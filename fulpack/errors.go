@@ -0,0 +1,228 @@
+package fulpack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+	"syscall"
+
+	"github.com/fulmenhq/crucible/errorsx"
+	"github.com/fulmenhq/crucible/foundry"
+)
+
+// ErrorCode is a canonical fulpack error code.
+// See: docs/standards/library/modules/fulpack.md#canonical-error-codes
+type ErrorCode string
+
+const (
+	// Validation errors (invalid input)
+	CodeInvalidArchiveFormat ErrorCode = "INVALID_ARCHIVE_FORMAT"
+	CodeInvalidPath          ErrorCode = "INVALID_PATH"
+	CodeInvalidOptions       ErrorCode = "INVALID_OPTIONS"
+
+	// Security errors (protection triggered)
+	CodePathTraversal     ErrorCode = "PATH_TRAVERSAL"
+	CodeAbsolutePath      ErrorCode = "ABSOLUTE_PATH"
+	CodeSymlinkEscape     ErrorCode = "SYMLINK_ESCAPE"
+	CodeDecompressionBomb ErrorCode = "DECOMPRESSION_BOMB"
+	CodeChecksumMismatch  ErrorCode = "CHECKSUM_MISMATCH"
+
+	// Runtime errors (I/O failures)
+	CodeArchiveNotFound  ErrorCode = "ARCHIVE_NOT_FOUND"
+	CodeArchiveCorrupt   ErrorCode = "ARCHIVE_CORRUPT"
+	CodeExtractionFailed ErrorCode = "EXTRACTION_FAILED"
+	CodePermissionDenied ErrorCode = "PERMISSION_DENIED"
+	CodeDiskFull         ErrorCode = "DISK_FULL"
+)
+
+// Sentinel errors for use with errors.Is; they match any Error with the same code.
+var (
+	ErrInvalidArchiveFormat = NewError(CodeInvalidArchiveFormat, "", "")
+	ErrInvalidPath          = NewError(CodeInvalidPath, "", "")
+	ErrInvalidOptions       = NewError(CodeInvalidOptions, "", "")
+	ErrPathTraversal        = NewError(CodePathTraversal, "", "")
+	ErrAbsolutePath         = NewError(CodeAbsolutePath, "", "")
+	ErrSymlinkEscape        = NewError(CodeSymlinkEscape, "", "")
+	ErrDecompressionBomb    = NewError(CodeDecompressionBomb, "", "")
+	ErrChecksumMismatch     = NewError(CodeChecksumMismatch, "", "")
+	ErrArchiveNotFound      = NewError(CodeArchiveNotFound, "", "")
+	ErrArchiveCorrupt       = NewError(CodeArchiveCorrupt, "", "")
+	ErrExtractionFailed     = NewError(CodeExtractionFailed, "", "")
+	ErrPermissionDenied     = NewError(CodePermissionDenied, "", "")
+	ErrDiskFull             = NewError(CodeDiskFull, "", "")
+)
+
+// Error is the Go form of the canonical fulpack error envelope.
+// See: docs/standards/library/modules/fulpack.md#canonical-error-envelope
+//
+// Details carry entry_index, compression_ratio, actual_size, max_size and
+// similar fields.
+type Error struct {
+	errorsx.Coded[ErrorCode]
+	Path      string    // Entry path that caused the error, if applicable
+	Archive   string    // Archive file path
+	Operation Operation // Operation name (create, extract, scan, verify, info)
+}
+
+// NewError creates an Error for the given operation.
+func NewError(code ErrorCode, op Operation, message string) *Error {
+	return &Error{Coded: errorsx.Coded[ErrorCode]{Code: code, Message: message}, Operation: op}
+}
+
+// WithPath sets the entry path and returns the error for chaining.
+func (e *Error) WithPath(path string) *Error {
+	e.Path = path
+	return e
+}
+
+// WithArchive sets the archive path and returns the error for chaining.
+func (e *Error) WithArchive(archive string) *Error {
+	e.Archive = archive
+	return e
+}
+
+// WithDetail sets a detail field and returns the error for chaining.
+func (e *Error) WithDetail(key string, value any) *Error {
+	e.SetDetail(key, value)
+	return e
+}
+
+// Wrap sets the underlying cause and returns the error for chaining.
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+func (e *Error) Error() string {
+	var note string
+	if e.Path != "" {
+		note = fmt.Sprintf("(entry %q)", e.Path)
+	}
+	return e.Text(string(e.Operation), note)
+}
+
+// ExitCode maps the error onto a Foundry exit code.
+func (e *Error) ExitCode() int {
+	switch e.Code {
+	case CodeInvalidOptions:
+		return foundry.ExitInvalidArgument
+	case CodeInvalidArchiveFormat, CodeInvalidPath:
+		return foundry.ExitDataInvalid
+	case CodePathTraversal, CodeAbsolutePath, CodeSymlinkEscape, CodeDecompressionBomb, CodeChecksumMismatch:
+		return foundry.ExitSecurityViolation
+	case CodeArchiveNotFound:
+		return foundry.ExitFileNotFound
+	case CodeArchiveCorrupt:
+		return foundry.ExitDataCorrupt
+	case CodePermissionDenied:
+		return foundry.ExitPermissionDenied
+	case CodeDiskFull:
+		return foundry.ExitResourceExhausted
+	default:
+		return foundry.ExitFileWriteError
+	}
+}
+
+// ViolationType returns the fulpack.security.violations_total type tag for
+// the error, or "" if the error is not a security violation.
+func (e *Error) ViolationType() string {
+	switch e.Code {
+	case CodePathTraversal:
+		return "path_traversal"
+	case CodeAbsolutePath:
+		return "absolute_path"
+	case CodeSymlinkEscape:
+		return "symlink_escape"
+	case CodeDecompressionBomb:
+		return "decompression_bomb"
+	case CodeChecksumMismatch:
+		return "checksum_mismatch"
+	default:
+		return ""
+	}
+}
+
+// IsSecurityViolation reports whether the error was raised by a security
+// protection.
+func (e *Error) IsSecurityViolation() bool {
+	return e.ViolationType() != ""
+}
+
+// MarshalJSON renders the error as the canonical fulpack error envelope.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	type envelope struct {
+		Code      ErrorCode      `json:"code"`
+		Message   string         `json:"message"`
+		Path      string         `json:"path,omitempty"`
+		Archive   string         `json:"archive,omitempty"`
+		Operation Operation      `json:"operation"`
+		Details   map[string]any `json:"details,omitempty"`
+	}
+
+	msg := e.Message
+	if msg == "" {
+		msg = e.Error()
+	}
+	return json.Marshal(envelope{
+		Code:      e.Code,
+		Message:   msg,
+		Path:      e.Path,
+		Archive:   e.Archive,
+		Operation: e.Operation,
+		Details:   e.Details,
+	})
+}
+
+// ioError classifies a filesystem error into a fulpack error. fallback is
+// used for failures that are neither permission nor disk-space related.
+func ioError(err error, op Operation, fallback ErrorCode, message string) *Error {
+	code := fallback
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		code = CodePermissionDenied
+	case errors.Is(err, syscall.ENOSPC):
+		code = CodeDiskFull
+	}
+	return NewError(code, op, message).Wrap(err)
+}
+
+// Violation describes a security violation detected during an operation.
+type Violation struct {
+	Type      string    // fulpack.security.violations_total type tag
+	Operation Operation // fulpack.security.violations_total operation tag
+	Err       *Error
+}
+
+var (
+	violationMu       sync.RWMutex
+	violationReporter func(Violation)
+)
+
+// SetViolationReporter installs a callback invoked for every security
+// violation passed to ReportViolation, typically to increment
+// fulpack.security.violations_total. Passing nil disables reporting.
+func SetViolationReporter(fn func(Violation)) {
+	violationMu.Lock()
+	defer violationMu.Unlock()
+	violationReporter = fn
+}
+
+// ReportViolation forwards err to the installed reporter if it is a fulpack
+// security violation. It returns true when a violation was found.
+func ReportViolation(err error) bool {
+	var fe *Error
+	if !errors.As(err, &fe) || !fe.IsSecurityViolation() {
+		return false
+	}
+
+	violationMu.RLock()
+	fn := violationReporter
+	violationMu.RUnlock()
+
+	if fn != nil {
+		fn(Violation{Type: fe.ViolationType(), Operation: fe.Operation, Err: fe})
+	}
+	return true
+}
@@ -0,0 +1,418 @@
+package fulpack
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/fulmenhq/crucible"
+	"github.com/fulmenhq/crucible/fulhash"
+	"github.com/fulmenhq/crucible/pathfinder"
+)
+
+// ExtractOptionsSchemaPath is the embedded schema used to validate ExtractOptions.
+const ExtractOptionsSchemaPath = "library/fulpack/v1.0.0/extract-options.schema.json"
+
+// ExtractOptions.Overwrite values.
+const (
+	OverwriteError   = "error"
+	OverwriteSkip    = "skip"
+	OverwriteReplace = "overwrite"
+)
+
+// Extract option defaults.
+// See: docs/standards/library/modules/fulpack.md#2-extract---extract-archive
+const (
+	DefaultMaxSize    int64 = 1 << 30
+	DefaultMaxEntries int64 = 10000
+)
+
+// suspiciousRatio is the compression ratio above which extraction warns
+// about a potential decompression bomb.
+const suspiciousRatio = 100
+
+type extractSettings struct {
+	overwrite     string
+	verify        bool
+	preservePerms bool
+	include       []string
+	maxSize       int64
+	maxEntries    int64
+}
+
+func resolveExtractOptions(opts *ExtractOptions) (extractSettings, error) {
+	s := extractSettings{
+		overwrite:     OverwriteError,
+		verify:        true,
+		preservePerms: true,
+		maxSize:       DefaultMaxSize,
+		maxEntries:    DefaultMaxEntries,
+	}
+	if opts == nil {
+		return s, nil
+	}
+	if err := crucible.ValidateSchemaValue(ExtractOptionsSchemaPath, opts); err != nil {
+		return s, NewError(CodeInvalidOptions, OperationExtract, "invalid extract options").Wrap(err)
+	}
+	if err := pathfinder.ValidatePatterns(opts.IncludePatterns); err != nil {
+		return s, NewError(CodeInvalidOptions, OperationExtract, "invalid glob pattern").Wrap(err)
+	}
+	if opts.Overwrite != "" {
+		s.overwrite = opts.Overwrite
+	}
+	if opts.VerifyChecksums != nil {
+		s.verify = *opts.VerifyChecksums
+	}
+	if opts.PreservePermissions != nil {
+		s.preservePerms = *opts.PreservePermissions
+	}
+	if opts.MaxSize != nil {
+		s.maxSize = *opts.MaxSize
+	}
+	if opts.MaxEntries != nil {
+		s.maxEntries = *opts.MaxEntries
+	}
+	s.include = opts.IncludePatterns
+	return s, nil
+}
+
+// fail reports security violations before returning err.
+func fail(err *Error) error {
+	ReportViolation(err)
+	return err
+}
+
+// Extract extracts archive into destDir, detecting the format from the
+// archive content (see DetectFormat).
+//
+// Every entry is validated before anything is written: absolute paths,
+// "../" traversal and links resolving outside destDir abort extraction with
+// ABSOLUTE_PATH, PATH_TRAVERSAL or SYMLINK_ESCAPE, and declared sizes and
+// entry counts beyond MaxSize/MaxEntries abort with DECOMPRESSION_BOMB.
+// While extracting, decompressed bytes are counted as they are written so
+// that archives lying about their sizes are stopped as soon as MaxSize is
+// exceeded. All writes go through an os.Root, so pre-existing symlinks in
+// destDir cannot redirect them outside it.
+//
+// Entries whose embedded checksum does not match abort extraction with
+// CHECKSUM_MISMATCH unless VerifyChecksums is false. Per-entry I/O failures
+// are collected in ExtractResult.Errors. When extraction fails after it has
+// started, the partial result is returned together with the error.
+func Extract(archive, destDir string, opts *ExtractOptions) (*ExtractResult, error) {
+	s, err := resolveExtractOptions(opts)
+	if err != nil {
+		return nil, withArchive(err, archive)
+	}
+	if destDir == "" {
+		return nil, NewError(CodeInvalidPath, OperationExtract, "destination directory must be explicit").WithArchive(archive)
+	}
+
+	x := &extractor{settings: s, archive: archive}
+
+	// Pass 1: validate every entry before touching the destination.
+	var root *os.Root
+	if _, err := os.Stat(destDir); err == nil {
+		if root, err = os.OpenRoot(destDir); err != nil {
+			return nil, ioError(err, OperationExtract, CodeExtractionFailed, "cannot open destination").WithArchive(archive)
+		}
+		defer root.Close()
+	}
+	if err := x.validate(root); err != nil {
+		return nil, fail(err.WithArchive(archive))
+	}
+
+	if root == nil {
+		if err := os.MkdirAll(destDir, 0o755); err != nil {
+			return nil, ioError(err, OperationExtract, CodeExtractionFailed, "cannot create destination").WithArchive(archive)
+		}
+		if root, err = os.OpenRoot(destDir); err != nil {
+			return nil, ioError(err, OperationExtract, CodeExtractionFailed, "cannot open destination").WithArchive(archive)
+		}
+		defer root.Close()
+	}
+
+	// Pass 2: extract.
+	res, xerr := x.extract(root)
+	if xerr != nil {
+		return res, fail(xerr.WithArchive(archive))
+	}
+	if res.ErrorCount > 0 {
+		return res, NewError(CodeExtractionFailed, OperationExtract,
+			fmt.Sprintf("%d entries failed to extract", res.ErrorCount)).WithArchive(archive)
+	}
+	return res, nil
+}
+
+type extractor struct {
+	settings extractSettings
+	archive  string
+}
+
+// selected reports whether a sanitized entry passes the include patterns.
+func (x *extractor) selected(name string) bool {
+	return len(x.settings.include) == 0 || pathfinder.MatchAny(x.settings.include, name)
+}
+
+// check validates one entry and returns its sanitized name. Link targets
+// are resolved through the links checked earlier in the same pass.
+func (x *extractor) check(h *entryHeader, index int, links *pathfinder.LinkChecker) (string, *Error) {
+	name, err := sanitizeEntryPath(h.name, OperationExtract)
+	if err != nil {
+		return "", err.WithDetail("entry_index", index)
+	}
+	if h.typ == EntryTypeSymlink || h.hardlink {
+		if err := checkLinkTarget(links, name, h.link, h.hardlink, OperationExtract); err != nil {
+			return "", err.WithDetail("entry_index", index)
+		}
+	}
+	return name, nil
+}
+
+func (x *extractor) bomb(message string, actual, limit int64) *Error {
+	return NewError(CodeDecompressionBomb, OperationExtract, message).
+		WithDetail("actual_size", actual).WithDetail("max_size", limit)
+}
+
+// validate walks the archive headers, enforcing path safety, the entry and
+// declared size limits, and, in error overwrite mode, conflicts with files
+// already present in the destination.
+func (x *extractor) validate(root *os.Root) *Error {
+	r, _, oerr := openArchive(x.archive, "", OperationExtract)
+	if oerr != nil {
+		return oerr
+	}
+	defer r.close()
+
+	var entries, declared int64
+	var links pathfinder.LinkChecker
+	for index := 0; ; index++ {
+		h, err := r.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return corrupt(err, OperationExtract)
+		}
+		entries++
+		if entries > x.settings.maxEntries {
+			return NewError(CodeDecompressionBomb, OperationExtract,
+				fmt.Sprintf("archive has more than %d entries", x.settings.maxEntries)).
+				WithDetail("entry_index", index).WithDetail("max_entries", x.settings.maxEntries)
+		}
+		if h.size > 0 {
+			declared += h.size
+			if declared > x.settings.maxSize {
+				return x.bomb("archive exceeds maximum size limit", declared, x.settings.maxSize).WithPath(h.name)
+			}
+		}
+
+		name, cerr := x.check(h, index, &links)
+		if cerr != nil {
+			return cerr
+		}
+		if root == nil || name == "" || x.settings.overwrite != OverwriteError || !x.selected(name) {
+			continue
+		}
+		if existing, err := root.Lstat(filepath.FromSlash(name)); err == nil {
+			if !(existing.IsDir() && h.typ == EntryTypeDirectory) {
+				return NewError(CodeExtractionFailed, OperationExtract, "destination already exists").
+					WithPath(name).WithDetail("entry_index", index)
+			}
+		}
+	}
+}
+
+// dirAttrs are applied to directories after their contents are written, so
+// that read-only directories do not block extraction into them.
+type dirAttrs struct {
+	name string
+	h    *entryHeader
+}
+
+func (x *extractor) extract(root *os.Root) (*ExtractResult, *Error) {
+	r, _, oerr := openArchive(x.archive, "", OperationExtract)
+	if oerr != nil {
+		return nil, oerr
+	}
+	defer r.close()
+
+	res := &ExtractResult{Errors: []string{}, Warnings: []string{}}
+	var written, verified, missing int64
+	var dirs []dirAttrs
+	var links pathfinder.LinkChecker
+
+	finish := func() *ExtractResult {
+		for i := len(dirs) - 1; i >= 0; i-- {
+			x.applyAttrs(root, dirs[i].name, dirs[i].h)
+		}
+		res.TotalBytes = &written
+		res.ChecksumsVerified = &verified
+		if x.settings.verify && missing > 0 {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("%d files have no embedded checksum", missing))
+		}
+		if fi, err := os.Stat(x.archive); err == nil && fi.Size() > 0 && written/fi.Size() > suspiciousRatio {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("compression ratio %d:1 exceeds %d:1", written/fi.Size(), suspiciousRatio))
+		}
+		return res
+	}
+	entryFailed := func(name string, err error) {
+		res.ErrorCount++
+		res.Errors = append(res.Errors, fmt.Sprintf("%s: %v", name, err))
+	}
+
+	for index := 0; ; index++ {
+		h, err := r.next()
+		if err == io.EOF {
+			return finish(), nil
+		}
+		if err != nil {
+			return finish(), corrupt(err, OperationExtract)
+		}
+		name, cerr := x.check(h, index, &links)
+		if cerr != nil {
+			return finish(), cerr
+		}
+		if name == "" || !x.selected(name) {
+			continue
+		}
+		if h.typ == "" {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("%s: unsupported entry type skipped", name))
+			continue
+		}
+		osName := filepath.FromSlash(name)
+
+		if existing, err := root.Lstat(osName); err == nil {
+			switch {
+			case existing.IsDir() && h.typ == EntryTypeDirectory:
+				dirs = append(dirs, dirAttrs{osName, h})
+				res.ExtractedCount++
+				continue
+			case x.settings.overwrite == OverwriteSkip:
+				res.SkippedCount++
+				res.Warnings = append(res.Warnings, fmt.Sprintf("%s: already exists, skipped", name))
+				continue
+			case x.settings.overwrite == OverwriteError:
+				return finish(), NewError(CodeExtractionFailed, OperationExtract, "destination already exists").
+					WithPath(name).WithDetail("entry_index", index)
+			case existing.IsDir():
+				entryFailed(name, errors.New("cannot replace a directory"))
+				continue
+			}
+			if err := root.Remove(osName); err != nil {
+				entryFailed(name, err)
+				continue
+			}
+		}
+		if dir := path.Dir(name); dir != "." {
+			if err := root.MkdirAll(filepath.FromSlash(dir), 0o755); err != nil {
+				entryFailed(name, err)
+				continue
+			}
+		}
+
+		switch {
+		case h.typ == EntryTypeDirectory:
+			if err := root.Mkdir(osName, 0o755); err != nil {
+				entryFailed(name, err)
+				continue
+			}
+			dirs = append(dirs, dirAttrs{osName, h})
+		case h.typ == EntryTypeSymlink:
+			if err := root.Symlink(filepath.FromSlash(h.link), osName); err != nil {
+				entryFailed(name, err)
+				continue
+			}
+		case h.hardlink:
+			target, _ := sanitizeEntryPath(h.link, OperationExtract)
+			if err := root.Link(filepath.FromSlash(target), osName); err != nil {
+				entryFailed(name, err)
+				continue
+			}
+		default:
+			n, ok, ferr := x.writeFile(root, r, osName, h, x.settings.maxSize-written)
+			written += n
+			if ferr != nil {
+				if ferr.Code == CodeExtractionFailed || ferr.Code == CodePermissionDenied || ferr.Code == CodeDiskFull {
+					entryFailed(name, ferr)
+					continue
+				}
+				return finish(), ferr.WithPath(name).WithDetail("entry_index", index)
+			}
+			switch {
+			case ok:
+				verified++
+			case x.settings.verify:
+				missing++
+			}
+			x.applyAttrs(root, osName, h)
+		}
+		res.ExtractedCount++
+	}
+}
+
+// writeFile streams the current entry into name, counting bytes against
+// budget. It reports the bytes written and whether a checksum was verified.
+func (x *extractor) writeFile(root *os.Root, r archiveReader, name string, h *entryHeader, budget int64) (int64, bool, *Error) {
+	src, err := r.open()
+	if err != nil {
+		return 0, false, corrupt(err, OperationExtract)
+	}
+
+	var hasher fulhash.StreamHasher
+	alg, want, hasChecksum := parseEntryChecksum(h.checksum)
+	if x.settings.verify && hasChecksum {
+		if hasher, err = fulhash.NewStreamHasher(alg); err != nil {
+			hasChecksum = false
+		} else {
+			src = io.TeeReader(src, hasher)
+		}
+	}
+
+	f, err := root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return 0, false, ioError(err, OperationExtract, CodeExtractionFailed, "cannot create file")
+	}
+	n, err := io.Copy(f, io.LimitReader(src, budget+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	var fe *Error
+	switch {
+	case n > budget:
+		n = budget
+		fe = x.bomb("archive exceeds maximum size limit", x.settings.maxSize+1, x.settings.maxSize)
+	case err != nil && isWriteError(err):
+		fe = ioError(err, OperationExtract, CodeExtractionFailed, "cannot write file")
+	case err != nil:
+		fe = corrupt(err, OperationExtract)
+	case hasher != nil && hasher.Sum().Hex != want:
+		fe = NewError(CodeChecksumMismatch, OperationExtract, "entry checksum verification failed").
+			WithDetail("expected", h.checksum).WithDetail("actual", hasher.Sum().Formatted)
+	}
+	if fe != nil {
+		root.Remove(name)
+		return n, false, fe
+	}
+	return n, hasher != nil, nil
+}
+
+// isWriteError distinguishes destination failures from archive read errors.
+func isWriteError(err error) bool {
+	var pe *fs.PathError
+	return errors.As(err, &pe) && pe.Op == "write"
+}
+
+// applyAttrs sets the permissions and modification time recorded in h.
+func (x *extractor) applyAttrs(root *os.Root, name string, h *entryHeader) {
+	if x.settings.preservePerms && h.mode != 0 {
+		root.Chmod(name, h.mode)
+	}
+	if !h.modTime.IsZero() {
+		root.Chtimes(name, h.modTime, h.modTime)
+	}
+}
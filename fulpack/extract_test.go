@@ -0,0 +1,385 @@
+package fulpack
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fulmenhq/crucible/foundry"
+)
+
+const fixtureDir = "../config/library/fulpack/fixtures"
+
+type tarEntry struct {
+	name     string
+	typ      byte
+	body     string
+	link     string
+	checksum string
+	mode     int64 // defaults to 0644
+}
+
+// buildTar writes a tar.gz archive from raw entries, bypassing Create so
+// that malicious headers can be expressed.
+func buildTar(t *testing.T, entries []tarEntry) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "crafted.tar.gz")
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Typeflag: e.typ, Mode: e.mode, Linkname: e.link, Size: int64(len(e.body))}
+		if hdr.Typeflag == 0 {
+			hdr.Typeflag = tar.TypeReg
+		}
+		if hdr.Mode == 0 {
+			hdr.Mode = 0o644
+		}
+		if e.checksum != "" {
+			hdr.PAXRecords = map[string]string{ChecksumPAXKey: e.checksum}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(e.body))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func assertEmpty(t *testing.T, dir string) {
+	t.Helper()
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("%s should be empty, has %d entries", dir, len(entries))
+	}
+}
+
+func TestExtractRoundTrip(t *testing.T) {
+	src := writeTree(t, sampleTree)
+	for _, format := range []ArchiveFormat{ArchiveFormatTar, ArchiveFormatTarGz, ArchiveFormatZip} {
+		t.Run(string(format), func(t *testing.T) {
+			archive := filepath.Join(t.TempDir(), "out."+string(format))
+			if _, err := Create(src, archive, format, nil); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+			dest := filepath.Join(t.TempDir(), "dest")
+			res, err := Extract(archive, dest, nil)
+			if err != nil {
+				t.Fatalf("Extract failed: %v", err)
+			}
+			for name, content := range sampleTree {
+				data, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(name)))
+				if err != nil || string(data) != content {
+					t.Errorf("%s = %q, %v; want %q", name, data, err, content)
+				}
+			}
+			if *res.ChecksumsVerified != int64(len(sampleTree)) {
+				t.Errorf("ChecksumsVerified = %d, want %d", *res.ChecksumsVerified, len(sampleTree))
+			}
+			if fi, _ := os.Stat(filepath.Join(dest, "README.md")); fi.Mode().Perm() != 0o600 {
+				t.Errorf("README.md mode = %v, want preserved 0600", fi.Mode().Perm())
+			}
+		})
+	}
+
+	t.Run("gzip", func(t *testing.T) {
+		archive := filepath.Join(t.TempDir(), "README.md.gz")
+		if _, err := Create(filepath.Join(src, "README.md"), archive, ArchiveFormatGzip, nil); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		dest := t.TempDir()
+		if _, err := Extract(archive, dest, nil); err != nil {
+			t.Fatalf("Extract failed: %v", err)
+		}
+		if data, _ := os.ReadFile(filepath.Join(dest, "README.md")); string(data) != sampleTree["README.md"] {
+			t.Errorf("README.md = %q", data)
+		}
+	})
+}
+
+func TestExtractFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		files   []string
+	}{
+		{"basic.tar", []string{"README.md", "config.json", "metadata.txt", "data/sample.txt", "data/tiny.png"}},
+		{"basic.tar.gz", []string{"README.md", "file1.txt", "file2.txt", "subdir/file3.txt"}},
+		{"basic.tar.zst", []string{"README.md", "file1.txt", "file2.txt", "subdir/file3.txt"}},
+		{"basic.tar.xz", []string{"README.md", "file1.txt", "file2.txt", "subdir/file3.txt"}},
+		{"basic.tar.bz2", []string{"README.md", "file1.txt", "file2.txt", "subdir/file3.txt"}},
+		{"nested.zip", []string{"root.txt", "level1/level2/level3/deep.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			dest := t.TempDir()
+			res, err := Extract(filepath.Join(fixtureDir, tt.fixture), dest, nil)
+			if err != nil {
+				t.Fatalf("Extract failed: %v", err)
+			}
+			for _, name := range tt.files {
+				if _, err := os.Stat(filepath.Join(dest, filepath.FromSlash(name))); err != nil {
+					t.Errorf("%s not extracted: %v", name, err)
+				}
+			}
+			if len(res.Warnings) == 0 || !strings.Contains(res.Warnings[len(res.Warnings)-1], "no embedded checksum") {
+				t.Errorf("expected a missing-checksum warning, got %v", res.Warnings)
+			}
+		})
+	}
+}
+
+func TestExtractPathologicalFixture(t *testing.T) {
+	dest := t.TempDir()
+	_, err := Extract(filepath.Join(fixtureDir, "pathological.tar.gz"), dest, nil)
+	if !errors.Is(err, ErrPathTraversal) {
+		t.Fatalf("expected PATH_TRAVERSAL, got %v", err)
+	}
+	assertEmpty(t, dest)
+}
+
+func TestExtractRejectsMaliciousEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+		want    error
+	}{
+		{"traversal", []tarEntry{{name: "ok.txt", body: "ok"}, {name: "../../../etc/passwd", body: "x"}}, ErrPathTraversal},
+		{"nested traversal", []tarEntry{{name: "a/../../b", body: "x"}}, ErrPathTraversal},
+		{"absolute", []tarEntry{{name: "/etc/passwd", body: "x"}}, ErrAbsolutePath},
+		{"windows drive", []tarEntry{{name: `C:\Windows\evil.dll`, body: "x"}}, ErrAbsolutePath},
+		{"symlink escape", []tarEntry{{name: "link", typ: tar.TypeSymlink, link: "../../../../etc/shadow"}}, ErrSymlinkEscape},
+		{"absolute symlink", []tarEntry{{name: "link", typ: tar.TypeSymlink, link: "/etc/shadow"}}, ErrSymlinkEscape},
+		{"hardlink escape", []tarEntry{{name: "link", typ: tar.TypeLink, link: "../outside"}}, ErrSymlinkEscape},
+		{"symlink chain escape", []tarEntry{
+			{name: "d/up", typ: tar.TypeSymlink, link: ".."},
+			{name: "d/up/escape", typ: tar.TypeSymlink, link: "../.."},
+		}, ErrSymlinkEscape},
+		{"hardlink through symlink", []tarEntry{
+			{name: "d/up", typ: tar.TypeSymlink, link: ".."},
+			{name: "passwd", typ: tar.TypeLink, link: "d/up/../etc/passwd"},
+		}, ErrSymlinkEscape},
+	}
+
+	var violations []Violation
+	SetViolationReporter(func(v Violation) { violations = append(violations, v) })
+	defer SetViolationReporter(nil)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := t.TempDir()
+			_, err := Extract(buildTar(t, tt.entries), dest, nil)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Extract error = %v, want %v", err, tt.want)
+			}
+			var fe *Error
+			if errors.As(err, &fe); fe.ExitCode() != foundry.ExitSecurityViolation {
+				t.Errorf("exit code = %d, want ExitSecurityViolation", fe.ExitCode())
+			}
+			assertEmpty(t, dest)
+		})
+	}
+	if len(violations) != len(tests) {
+		t.Errorf("reported %d violations, want %d", len(violations), len(tests))
+	}
+}
+
+func TestExtractPreexistingSymlink(t *testing.T) {
+	dest, outside := t.TempDir(), t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(dest, "evil")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	_, err := Extract(buildTar(t, []tarEntry{{name: "evil/pwned.txt", body: "pwned"}}), dest, nil)
+	if !errors.Is(err, ErrExtractionFailed) {
+		t.Errorf("Extract error = %v, want EXTRACTION_FAILED", err)
+	}
+	assertEmpty(t, outside)
+}
+
+func TestExtractBombProtection(t *testing.T) {
+	t.Run("declared size", func(t *testing.T) {
+		dest := t.TempDir()
+		archive := buildTar(t, []tarEntry{{name: "a", body: "small"}, {name: "b", body: strings.Repeat("x", 4096)}})
+		_, err := Extract(archive, dest, &ExtractOptions{MaxSize: ptr(int64(1024))})
+		if !errors.Is(err, ErrDecompressionBomb) {
+			t.Fatalf("Extract error = %v, want DECOMPRESSION_BOMB", err)
+		}
+		assertEmpty(t, dest)
+	})
+
+	t.Run("max entries", func(t *testing.T) {
+		entries := make([]tarEntry, 5)
+		for i := range entries {
+			entries[i] = tarEntry{name: string(rune('a' + i)), body: "x"}
+		}
+		dest := t.TempDir()
+		_, err := Extract(buildTar(t, entries), dest, &ExtractOptions{MaxEntries: ptr(int64(4))})
+		if !errors.Is(err, ErrDecompressionBomb) {
+			t.Fatalf("Extract error = %v, want DECOMPRESSION_BOMB", err)
+		}
+		assertEmpty(t, dest)
+	})
+
+	t.Run("streamed size", func(t *testing.T) {
+		// Understate the size in the gzip trailer, so that the limit can
+		// only be enforced while decompressing.
+		src := writeTree(t, map[string]string{"zeros.bin": strings.Repeat("\x00", 1<<20)})
+		archive := filepath.Join(t.TempDir(), "zeros.bin.gz")
+		if _, err := Create(filepath.Join(src, "zeros.bin"), archive, ArchiveFormatGzip, nil); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.OpenFile(archive, os.O_RDWR, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fi, _ := f.Stat()
+		f.WriteAt([]byte{1, 0, 0, 0}, fi.Size()-4)
+		f.Close()
+		dest := t.TempDir()
+		res, err := Extract(archive, dest, &ExtractOptions{MaxSize: ptr(int64(64 << 10))})
+		if !errors.Is(err, ErrDecompressionBomb) {
+			t.Fatalf("Extract error = %v, want DECOMPRESSION_BOMB", err)
+		}
+		if *res.TotalBytes > 64<<10 {
+			t.Errorf("wrote %d bytes past the limit", *res.TotalBytes)
+		}
+		assertEmpty(t, dest)
+	})
+}
+
+func TestExtractChecksums(t *testing.T) {
+	archive := buildTar(t, []tarEntry{{name: "data.csv", body: "a,b\n", checksum: "sha256:" + strings.Repeat("0", 64)}})
+
+	dest := t.TempDir()
+	if _, err := Extract(archive, dest, nil); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("Extract error = %v, want CHECKSUM_MISMATCH", err)
+	}
+	assertEmpty(t, dest)
+
+	res, err := Extract(archive, dest, &ExtractOptions{VerifyChecksums: ptr(false)})
+	if err != nil {
+		t.Fatalf("Extract without verification failed: %v", err)
+	}
+	if res.ExtractedCount != 1 || *res.ChecksumsVerified != 0 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+}
+
+func TestExtractOverwrite(t *testing.T) {
+	archive := buildTar(t, []tarEntry{{name: "dir/", typ: tar.TypeDir}, {name: "dir/a.txt", body: "new"}, {name: "b.txt", body: "new"}})
+
+	setup := func(t *testing.T) string {
+		dest := t.TempDir()
+		os.MkdirAll(filepath.Join(dest, "dir"), 0o755)
+		os.WriteFile(filepath.Join(dest, "dir", "a.txt"), []byte("old"), 0o644)
+		return dest
+	}
+
+	t.Run("error", func(t *testing.T) {
+		dest := setup(t)
+		if _, err := Extract(archive, dest, nil); !errors.Is(err, ErrExtractionFailed) {
+			t.Fatalf("Extract error = %v, want EXTRACTION_FAILED", err)
+		}
+		if _, err := os.Stat(filepath.Join(dest, "b.txt")); err == nil {
+			t.Error("conflict should be detected before writing")
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		dest := setup(t)
+		res, err := Extract(archive, dest, &ExtractOptions{Overwrite: OverwriteSkip})
+		if err != nil {
+			t.Fatalf("Extract failed: %v", err)
+		}
+		data, _ := os.ReadFile(filepath.Join(dest, "dir", "a.txt"))
+		if string(data) != "old" || res.SkippedCount != 1 || res.ExtractedCount != 2 {
+			t.Errorf("a.txt = %q, result %+v", data, res)
+		}
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		dest := setup(t)
+		if _, err := Extract(archive, dest, &ExtractOptions{Overwrite: OverwriteReplace}); err != nil {
+			t.Fatalf("Extract failed: %v", err)
+		}
+		if data, _ := os.ReadFile(filepath.Join(dest, "dir", "a.txt")); string(data) != "new" {
+			t.Errorf("a.txt = %q, want replaced", data)
+		}
+	})
+}
+
+func TestExtractIncludePatterns(t *testing.T) {
+	src := writeTree(t, sampleTree)
+	archive := filepath.Join(t.TempDir(), "out.zip")
+	if _, err := Create(src, archive, ArchiveFormatZip, nil); err != nil {
+		t.Fatal(err)
+	}
+	dest := t.TempDir()
+	res, err := Extract(archive, dest, &ExtractOptions{IncludePatterns: []string{"**/*.go"}})
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if res.ExtractedCount != 2 {
+		t.Errorf("ExtractedCount = %d, want 2", res.ExtractedCount)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "README.md")); err == nil {
+		t.Error("README.md should not be extracted")
+	}
+	if _, err := os.Stat(filepath.Join(dest, "src", "util", "util.go")); err != nil {
+		t.Errorf("util.go not extracted: %v", err)
+	}
+}
+
+func TestExtractInvalidInput(t *testing.T) {
+	if _, err := Extract(filepath.Join(t.TempDir(), "missing.tar"), t.TempDir(), nil); !errors.Is(err, ErrArchiveNotFound) {
+		t.Errorf("missing archive: error = %v, want ARCHIVE_NOT_FOUND", err)
+	}
+	if _, err := Extract(filepath.Join(fixtureDir, "basic.tar"), "", nil); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("empty destination: error = %v, want INVALID_PATH", err)
+	}
+	if _, err := Extract(filepath.Join(fixtureDir, "basic.tar"), t.TempDir(), &ExtractOptions{Overwrite: "clobber"}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("bad overwrite mode: error = %v, want INVALID_OPTIONS", err)
+	}
+	notTar := filepath.Join(t.TempDir(), "bogus.tar.gz")
+	os.WriteFile(notTar, []byte("definitely not gzip"), 0o644)
+	if _, err := Extract(notTar, t.TempDir(), nil); !errors.Is(err, ErrArchiveCorrupt) {
+		t.Errorf("corrupt archive: error = %v, want ARCHIVE_CORRUPT", err)
+	}
+}
+
+func TestExtractIncludePatternsAnchored(t *testing.T) {
+	src := writeTree(t, sampleTree)
+	archive := filepath.Join(t.TempDir(), "out.tar")
+	if _, err := Create(src, archive, ArchiveFormatTar, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for pattern, want := range map[string]int64{"*.md": 1, "**/*.md": 2} {
+		dest := t.TempDir()
+		res, err := Extract(archive, dest, &ExtractOptions{IncludePatterns: []string{pattern}})
+		if err != nil {
+			t.Fatalf("%s: Extract failed: %v", pattern, err)
+		}
+		if res.ExtractedCount != want {
+			t.Errorf("%s: ExtractedCount = %d, want %d", pattern, res.ExtractedCount, want)
+		}
+		_, err = os.Stat(filepath.Join(dest, "docs", "guide.md"))
+		if got := err == nil; got != (want == 2) {
+			t.Errorf("%s: docs/guide.md extracted = %v", pattern, got)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package fulpack
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// formatExtensions maps file name suffixes onto formats, longest first.
+// See: schemas/taxonomy/library/fulpack/archive-formats/v1.0.0/formats.yaml
+var formatExtensions = []struct {
+	ext    string
+	format ArchiveFormat
+}{
+	{".tar.gz", ArchiveFormatTarGz},
+	{".tar.zst", ArchiveFormatTarZst},
+	{".tar.xz", ArchiveFormatTarXz},
+	{".tar.bz2", ArchiveFormatTarBz2},
+	{".tgz", ArchiveFormatTarGz},
+	{".tzst", ArchiveFormatTarZst},
+	{".txz", ArchiveFormatTarXz},
+	{".tbz2", ArchiveFormatTarBz2},
+	{".tbz", ArchiveFormatTarBz2},
+	{".tar", ArchiveFormatTar},
+	{".zip", ArchiveFormatZip},
+	{".gz", ArchiveFormatGzip},
+}
+
+// FormatFromPath infers the archive format from the extension of name.
+// Reading operations detect the format from content instead; see
+// DetectFormat.
+func FormatFromPath(name string) (ArchiveFormat, error) {
+	lower := strings.ToLower(name)
+	for _, fe := range formatExtensions {
+		if strings.HasSuffix(lower, fe.ext) {
+			return fe.format, nil
+		}
+	}
+	return "", fmt.Errorf("cannot infer archive format from %q", name)
+}
+
+// compressionOf returns the compression field of the archive-formats taxonomy.
+func compressionOf(format ArchiveFormat) string {
+	switch format {
+	case ArchiveFormatTarGz, ArchiveFormatGzip:
+		return "gzip"
+	case ArchiveFormatZip:
+		return "deflate"
+	case ArchiveFormatTarZst:
+		return "zstd"
+	case ArchiveFormatTarXz:
+		return "xz"
+	case ArchiveFormatTarBz2:
+		return "bzip2"
+	default:
+		return "none"
+	}
+}
+
+// isReadOnly reports whether format is marked read_only in the
+// archive-formats taxonomy.
+func isReadOnly(format ArchiveFormat) bool {
+	switch format {
+	case ArchiveFormatTarZst, ArchiveFormatTarXz, ArchiveFormatTarBz2:
+		return true
+	}
+	return false
+}
+
+// ErrUnknownFormat is returned by DetectFormat for content that matches no
+// supported archive signature.
+var ErrUnknownFormat = errors.New("unrecognized archive format")
+
+// Archive signatures.
+var (
+	magicGzip     = []byte{0x1f, 0x8b}
+	magicZstd     = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	magicXz       = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	magicBzip2    = []byte("BZh")
+	magicZip      = []byte("PK\x03\x04")
+	magicZipEmpty = []byte("PK\x05\x06")
+)
+
+const (
+	tarBlockSize  = 512
+	tarRecordSize = 20 * tarBlockSize
+)
+
+// DetectFormat identifies the archive format of the content read from r by
+// its magic bytes, without trusting a file name. gzip content is reported as
+// tar.gz when the decompressed stream starts with a tar header and as gzip
+// otherwise. DetectFormat consumes data from r; content that matches no
+// signature yields ErrUnknownFormat.
+func DetectFormat(r io.Reader) (ArchiveFormat, error) {
+	br := bufio.NewReaderSize(r, tarBlockSize)
+	head, err := br.Peek(tarBlockSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return "", err
+	}
+	switch {
+	case bytes.HasPrefix(head, magicGzip):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid gzip header: %v", ErrUnknownFormat, err)
+		}
+		defer gz.Close()
+		if isTar(bufio.NewReaderSize(gz, tarBlockSize)) {
+			return ArchiveFormatTarGz, nil
+		}
+		return ArchiveFormatGzip, nil
+	case bytes.HasPrefix(head, magicZstd):
+		return ArchiveFormatTarZst, nil
+	case bytes.HasPrefix(head, magicXz):
+		return ArchiveFormatTarXz, nil
+	case bytes.HasPrefix(head, magicBzip2):
+		return ArchiveFormatTarBz2, nil
+	case bytes.HasPrefix(head, magicZip), bytes.HasPrefix(head, magicZipEmpty):
+		return ArchiveFormatZip, nil
+	case isTar(br):
+		return ArchiveFormatTar, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// isTar reports whether r starts with a tar header, or holds nothing but
+// the zero blocks of an empty tar (at most one 10 KiB record).
+func isTar(r *bufio.Reader) bool {
+	head, _ := r.Peek(tarBlockSize)
+	if isTarHeader(head) {
+		return true
+	}
+	if len(head) < tarBlockSize || !allZero(head) {
+		return false
+	}
+	rest, err := io.ReadAll(io.LimitReader(r, tarRecordSize+1))
+	if err != nil || len(rest) < 2*tarBlockSize || len(rest) > tarRecordSize {
+		return false
+	}
+	return allZero(rest)
+}
+
+func allZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// isTarHeader reports whether block is a tar header with a valid checksum.
+func isTarHeader(block []byte) bool {
+	if len(block) < tarBlockSize {
+		return false
+	}
+	var sum int64
+	for i, b := range block[:tarBlockSize] {
+		if i >= 148 && i < 156 {
+			b = ' ' // the checksum field counts as spaces
+		}
+		sum += int64(b)
+	}
+	field := strings.TrimRight(strings.TrimLeft(string(block[148:156]), " \x00"), " \x00")
+	want, err := strconv.ParseInt(field, 8, 64)
+	return err == nil && want == sum
+}
@@ -0,0 +1,130 @@
+package fulpack
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    ArchiveFormat
+	}{
+		{"basic.tar", ArchiveFormatTar},
+		{"basic.tar.gz", ArchiveFormatTarGz},
+		{"basic.tar.zst", ArchiveFormatTarZst},
+		{"basic.tar.xz", ArchiveFormatTarXz},
+		{"basic.tar.bz2", ArchiveFormatTarBz2},
+		{"nested.zip", ArchiveFormatZip},
+		{"pathological.tar.gz", ArchiveFormatTarGz},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			f, err := os.Open(filepath.Join(fixtureDir, tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			got, err := DetectFormat(f)
+			if err != nil || got != tt.want {
+				t.Errorf("DetectFormat = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	t.Run("single gzip file", func(t *testing.T) {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		gw.Write(bytes.Repeat([]byte("log line\n"), 100))
+		gw.Close()
+		if got, err := DetectFormat(&buf); err != nil || got != ArchiveFormatGzip {
+			t.Errorf("DetectFormat = %q, %v; want gzip", got, err)
+		}
+	})
+
+	t.Run("empty tar", func(t *testing.T) {
+		src := t.TempDir()
+		archive := filepath.Join(t.TempDir(), "empty.tar.gz")
+		if _, err := Create(src, archive, ArchiveFormatTarGz, nil); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Open(archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if got, err := DetectFormat(f); err != nil || got != ArchiveFormatTarGz {
+			t.Errorf("DetectFormat = %q, %v; want tar.gz", got, err)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		for _, data := range []string{"", "plain text", string(make([]byte, 64))} {
+			if _, err := DetectFormat(bytes.NewReader([]byte(data))); !errors.Is(err, ErrUnknownFormat) {
+				t.Errorf("DetectFormat(%q) error = %v, want ErrUnknownFormat", data, err)
+			}
+		}
+	})
+}
+
+func TestFormatDetectionIgnoresExtension(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(fixtureDir, "basic.tar.xz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	disguised := filepath.Join(t.TempDir(), "artifact.zip")
+	if err := os.WriteFile(disguised, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := Info(disguised)
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+	if info.Format != string(ArchiveFormatTarXz) || info.Compression != "xz" {
+		t.Errorf("format = %s/%s, want tar.xz/xz", info.Format, info.Compression)
+	}
+	if _, err := Extract(disguised, t.TempDir(), nil); err != nil {
+		t.Errorf("Extract failed: %v", err)
+	}
+
+	unknown := filepath.Join(t.TempDir(), "artifact.bin")
+	if err := os.WriteFile(unknown, []byte("not an archive"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Scan(unknown, nil); !errors.Is(err, ErrInvalidArchiveFormat) {
+		t.Errorf("expected INVALID_ARCHIVE_FORMAT, got %v", err)
+	}
+}
+
+func TestCreateRejectsReadOnlyFormats(t *testing.T) {
+	src := writeTree(t, sampleTree)
+	for _, format := range []ArchiveFormat{ArchiveFormatTarZst, ArchiveFormatTarXz, ArchiveFormatTarBz2} {
+		dest := filepath.Join(t.TempDir(), "out."+string(format))
+		if _, err := Create(src, dest, format, nil); !errors.Is(err, ErrInvalidArchiveFormat) {
+			t.Errorf("%s: expected INVALID_ARCHIVE_FORMAT, got %v", format, err)
+		}
+		if _, err := os.Stat(dest); !os.IsNotExist(err) {
+			t.Errorf("%s: archive written despite error", format)
+		}
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := map[string]ArchiveFormat{
+		"a.tar": ArchiveFormatTar, "a.TGZ": ArchiveFormatTarGz, "a.tar.gz": ArchiveFormatTarGz,
+		"a.gz": ArchiveFormatGzip, "a.zip": ArchiveFormatZip, "a.tzst": ArchiveFormatTarZst,
+		"a.tar.xz": ArchiveFormatTarXz, "a.tbz2": ArchiveFormatTarBz2,
+	}
+	for name, want := range tests {
+		if got, err := FormatFromPath(name); err != nil || got != want {
+			t.Errorf("FormatFromPath(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := FormatFromPath("a.rar"); err == nil {
+		t.Error("expected an error for .rar")
+	}
+}
@@ -0,0 +1,63 @@
+package fulpack
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Info returns metadata about archive, detecting its format from content.
+// Only entry headers are read; sizes are those declared by the archive.
+// Archives with more than DefaultScanMaxEntries entries are rejected with
+// DECOMPRESSION_BOMB.
+func Info(archive string) (*ArchiveInfo, error) {
+	r, format, oerr := openArchive(archive, "", OperationInfo)
+	if oerr != nil {
+		return nil, oerr
+	}
+	defer r.close()
+
+	info := &ArchiveInfo{Format: string(format), Compression: compressionOf(format)}
+	checksummed := false
+	for index := 0; ; index++ {
+		h, err := r.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, corrupt(err, OperationInfo).WithArchive(archive)
+		}
+		if int64(index) >= DefaultScanMaxEntries {
+			return nil, NewError(CodeDecompressionBomb, OperationInfo,
+				fmt.Sprintf("archive has more than %d entries", DefaultScanMaxEntries)).WithArchive(archive).
+				WithDetail("entry_index", index).WithDetail("max_entries", DefaultScanMaxEntries)
+		}
+		if h.typ == "" || scanPath(h.name) == "" {
+			continue
+		}
+		info.EntryCount++
+		if h.typ != EntryTypeFile {
+			continue
+		}
+		if h.size > 0 {
+			info.TotalSize += h.size
+		}
+		if alg, _, ok := parseEntryChecksum(h.checksum); ok && !checksummed {
+			checksummed = true
+			info.ChecksumAlgorithm = string(alg)
+		}
+	}
+
+	fi, err := os.Stat(archive)
+	if err != nil {
+		return nil, ioError(err, OperationInfo, CodeArchiveCorrupt, "cannot stat archive").WithArchive(archive)
+	}
+	info.CompressedSize = fi.Size()
+	ratio := 1.0
+	if format != ArchiveFormatTar && fi.Size() > 0 {
+		ratio = float64(info.TotalSize) / float64(fi.Size())
+	}
+	info.CompressionRatio = &ratio
+	info.HasChecksums = &checksummed
+	return info, nil
+}
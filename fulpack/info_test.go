@@ -0,0 +1,43 @@
+package fulpack
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/fulmenhq/crucible"
+)
+
+func TestInfo(t *testing.T) {
+	for _, fixture := range []string{"basic.tar.gz", "basic.tar.zst", "basic.tar.xz", "basic.tar.bz2"} {
+		t.Run(fixture, func(t *testing.T) {
+			info, err := Info(filepath.Join(fixtureDir, fixture))
+			if err != nil {
+				t.Fatalf("Info failed: %v", err)
+			}
+			if info.EntryCount == 0 || info.TotalSize == 0 || info.CompressedSize == 0 {
+				t.Errorf("unexpected info %+v", info)
+			}
+			if *info.HasChecksums {
+				t.Error("fixtures carry no checksums")
+			}
+			if err := crucible.ValidateSchemaValue("library/fulpack/v1.0.0/archive-info.schema.json", info); err != nil {
+				t.Errorf("info does not match schema: %v", err)
+			}
+		})
+	}
+
+	t.Run("checksums", func(t *testing.T) {
+		archive := filepath.Join(t.TempDir(), "sample.zip")
+		created, err := Create(writeTree(t, sampleTree), archive, ArchiveFormatZip, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		info, err := Info(archive)
+		if err != nil {
+			t.Fatalf("Info failed: %v", err)
+		}
+		if !*info.HasChecksums || info.ChecksumAlgorithm != string(DefaultChecksumAlgorithm) || info.EntryCount != created.EntryCount {
+			t.Errorf("unexpected info %+v", info)
+		}
+	})
+}
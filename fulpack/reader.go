@@ -0,0 +1,339 @@
+package fulpack
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fulmenhq/crucible/fulhash"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// maxZipSymlinkTarget bounds the content read from a zip symlink entry.
+const maxZipSymlinkTarget = 4096
+
+// entryHeader is the format-independent view of an archive entry.
+type entryHeader struct {
+	name           string    // raw entry name as stored in the archive
+	typ            EntryType // "" for entry types fulpack does not handle (devices, fifos)
+	hardlink       bool      // tar hard link to link
+	mode           fs.FileMode
+	size           int64
+	compressedSize *int64
+	modTime        time.Time
+	link           string // symlink or hard link target
+	checksum       string // embedded fulhash checksum, if any
+	dataOffset     int64  // offset of the content in an uncompressed tar, or -1
+}
+
+// archiveReader iterates the entries of an archive in stored order.
+type archiveReader interface {
+	// next advances to the next entry and returns io.EOF after the last one.
+	next() (*entryHeader, error)
+	// open returns the content of the current entry.
+	open() (io.Reader, error)
+	close() error
+}
+
+// openArchive opens archive for reading. An empty format is detected from
+// the archive content.
+func openArchive(archive string, format ArchiveFormat, op Operation) (archiveReader, ArchiveFormat, *Error) {
+	if format != "" {
+		if err := ValidateArchiveFormat(format); err != nil {
+			return nil, "", NewError(CodeInvalidArchiveFormat, op, err.Error()).WithArchive(archive)
+		}
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, "", NewError(CodeArchiveNotFound, op, "archive does not exist").WithArchive(archive).Wrap(err)
+		}
+		return nil, "", ioError(err, op, CodeArchiveCorrupt, "cannot open archive").WithArchive(archive)
+	}
+
+	if format == "" {
+		format, err = DetectFormat(f)
+		if err == nil {
+			_, err = f.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			f.Close()
+			if errors.Is(err, ErrUnknownFormat) {
+				// Content contradicting a known extension is damage, not an
+				// unsupported format.
+				if _, perr := FormatFromPath(archive); perr == nil {
+					return nil, "", corrupt(err, op).WithArchive(archive)
+				}
+				return nil, "", NewError(CodeInvalidArchiveFormat, op, err.Error()).WithArchive(archive)
+			}
+			return nil, "", ioError(err, op, CodeArchiveCorrupt, "cannot read archive").WithArchive(archive)
+		}
+	}
+
+	var r archiveReader
+	switch format {
+	case ArchiveFormatTar:
+		cr := &countingReader{r: f}
+		r = &tarReader{f: f, tr: tar.NewReader(cr), pos: cr}
+	case ArchiveFormatTarGz:
+		gz, gerr := gzip.NewReader(f)
+		if gerr != nil {
+			err = gerr
+			break
+		}
+		r = &tarReader{f: f, tr: tar.NewReader(gz), release: func() { gz.Close() }}
+	case ArchiveFormatTarZst:
+		// Windows beyond 128 MiB (zstd --long=27) are refused to bound memory.
+		zr, zerr := zstd.NewReader(f, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(128<<20))
+		if zerr != nil {
+			err = zerr
+			break
+		}
+		r = &tarReader{f: f, tr: tar.NewReader(zr), release: zr.Close}
+	case ArchiveFormatTarXz:
+		xr, xerr := xz.NewReader(bufio.NewReader(f))
+		if xerr != nil {
+			err = xerr
+			break
+		}
+		r = &tarReader{f: f, tr: tar.NewReader(xr)}
+	case ArchiveFormatTarBz2:
+		r = &tarReader{f: f, tr: tar.NewReader(bzip2.NewReader(f))}
+	case ArchiveFormatZip:
+		r, err = newZipReader(f)
+	case ArchiveFormatGzip:
+		gz, gerr := gzip.NewReader(f)
+		if gerr != nil {
+			err = gerr
+			break
+		}
+		r = &gzipReader{f: f, gz: gz, name: archive}
+	}
+	if err != nil {
+		f.Close()
+		return nil, "", corrupt(err, op).WithArchive(archive)
+	}
+	return r, format, nil
+}
+
+func corrupt(err error, op Operation) *Error {
+	return NewError(CodeArchiveCorrupt, op, "archive structure is invalid").Wrap(err)
+}
+
+type tarReader struct {
+	f       *os.File
+	tr      *tar.Reader
+	release func()          // releases the decompressor, if any
+	pos     *countingReader // position in an uncompressed tar, if any
+}
+
+// countingReader tracks the read position of an uncompressed tar. The tar
+// reader does not read ahead, so after Next it is the entry's data offset.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (r *tarReader) next() (*entryHeader, error) {
+	hdr, err := r.tr.Next()
+	if err != nil {
+		return nil, err
+	}
+	h := &entryHeader{
+		name:       hdr.Name,
+		mode:       fs.FileMode(hdr.Mode).Perm(),
+		modTime:    hdr.ModTime,
+		link:       hdr.Linkname,
+		checksum:   hdr.PAXRecords[ChecksumPAXKey],
+		dataOffset: -1,
+	}
+	switch hdr.Typeflag {
+	case tar.TypeReg:
+		h.typ, h.size = EntryTypeFile, hdr.Size
+		if r.pos != nil {
+			h.dataOffset = r.pos.n
+		}
+	case tar.TypeGNUSparse:
+		h.typ, h.size = EntryTypeFile, hdr.Size
+	case tar.TypeLink:
+		h.typ, h.hardlink = EntryTypeFile, true
+	case tar.TypeDir:
+		h.typ = EntryTypeDirectory
+	case tar.TypeSymlink:
+		h.typ = EntryTypeSymlink
+	}
+	return h, nil
+}
+
+func (r *tarReader) open() (io.Reader, error) { return r.tr, nil }
+
+func (r *tarReader) close() error {
+	if r.release != nil {
+		r.release()
+	}
+	return r.f.Close()
+}
+
+type zipReader struct {
+	f     *os.File
+	zr    *zip.Reader
+	index int
+	cur   *zip.File
+	rc    io.ReadCloser
+}
+
+func newZipReader(f *os.File) (*zipReader, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(f, fi.Size())
+	if err != nil {
+		return nil, err
+	}
+	return &zipReader{f: f, zr: zr}, nil
+}
+
+func (r *zipReader) next() (*entryHeader, error) {
+	r.closeCurrent()
+	if r.index >= len(r.zr.File) {
+		return nil, io.EOF
+	}
+	r.cur = r.zr.File[r.index]
+	r.index++
+
+	zf := r.cur
+	compressed := int64(zf.CompressedSize64)
+	h := &entryHeader{
+		name:           zf.Name,
+		mode:           zf.Mode().Perm(),
+		size:           int64(zf.UncompressedSize64),
+		compressedSize: &compressed,
+		modTime:        zf.Modified,
+		checksum:       zf.Comment,
+		dataOffset:     -1,
+	}
+	switch mode := zf.Mode(); {
+	case mode.IsDir() || strings.HasSuffix(zf.Name, "/"):
+		h.typ, h.size = EntryTypeDirectory, 0
+	case mode&fs.ModeSymlink != 0:
+		h.typ = EntryTypeSymlink
+		rc, err := zf.Open()
+		if err != nil {
+			return nil, err
+		}
+		target, err := io.ReadAll(io.LimitReader(rc, maxZipSymlinkTarget))
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		h.link, h.size = string(target), 0
+	case mode.IsRegular():
+		h.typ = EntryTypeFile
+	}
+	return h, nil
+}
+
+func (r *zipReader) open() (io.Reader, error) {
+	r.closeCurrent()
+	rc, err := r.cur.Open()
+	if err != nil {
+		return nil, err
+	}
+	r.rc = rc
+	return rc, nil
+}
+
+func (r *zipReader) closeCurrent() {
+	if r.rc != nil {
+		r.rc.Close()
+		r.rc = nil
+	}
+}
+
+func (r *zipReader) close() error {
+	r.closeCurrent()
+	return r.f.Close()
+}
+
+// gzipReader presents a gzip file as a single-entry archive named after the
+// original file name in the header, or the archive name without ".gz".
+type gzipReader struct {
+	f    *os.File
+	gz   *gzip.Reader
+	name string
+	done bool
+}
+
+func (r *gzipReader) next() (*entryHeader, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+	r.done = true
+	name := r.gz.Name
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(r.name), filepath.Ext(r.name))
+	}
+	return &entryHeader{
+		name:       name,
+		typ:        EntryTypeFile,
+		mode:       normalizedFileMode,
+		size:       r.trailerSize(),
+		modTime:    r.gz.ModTime,
+		checksum:   r.gz.Comment,
+		dataOffset: -1,
+	}, nil
+}
+
+// trailerSize returns the uncompressed size recorded in the gzip trailer
+// (ISIZE, modulo 2^32), or -1 when it cannot be read. The value is declared
+// by the archive and is not trusted by extraction.
+func (r *gzipReader) trailerSize() int64 {
+	fi, err := r.f.Stat()
+	if err != nil || fi.Size() < 4 {
+		return -1
+	}
+	var buf [4]byte
+	if _, err := r.f.ReadAt(buf[:], fi.Size()-4); err != nil {
+		return -1
+	}
+	return int64(binary.LittleEndian.Uint32(buf[:]))
+}
+
+func (r *gzipReader) open() (io.Reader, error) { return r.gz, nil }
+
+func (r *gzipReader) close() error {
+	r.gz.Close()
+	return r.f.Close()
+}
+
+// parseEntryChecksum returns the embedded checksum of an entry when it is a
+// well-formed fulhash checksum string.
+func parseEntryChecksum(s string) (fulhash.Algorithm, string, bool) {
+	if s == "" {
+		return "", "", false
+	}
+	alg, hexValue, err := fulhash.ParseChecksum(s)
+	if err != nil {
+		return "", "", false
+	}
+	return alg, hexValue, true
+}
@@ -0,0 +1,232 @@
+package fulpack
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/fulmenhq/crucible"
+	"github.com/fulmenhq/crucible/fulhash"
+)
+
+// ScanOptionsSchemaPath is the embedded schema used to validate ScanOptions.
+const ScanOptionsSchemaPath = "library/fulpack/v1.0.0/scan-options.schema.json"
+
+// DefaultScanMaxEntries is the default safety limit on entries read by Scan.
+// See: docs/standards/library/modules/fulpack.md#3-scan---scan-archive-pathfinder-integration
+const DefaultScanMaxEntries int64 = 100000
+
+// ManifestVersion is the archive manifest schema version written by BuildManifest.
+const ManifestVersion = "1.0.0"
+
+// ManifestIndex is the searchable index of an ArchiveManifest. Values are
+// indices into ArchiveManifest.Entries.
+type ManifestIndex struct {
+	ByPath      map[string]int64   `json:"by_path,omitempty"`      // Map of entry paths to array indices
+	ByType      map[string][]int64 `json:"by_type,omitempty"`      // Map of entry types to array indices
+	ByExtension map[string][]int64 `json:"by_extension,omitempty"` // Map of file extensions to array indices
+}
+
+type scanSettings struct {
+	metadata   bool
+	types      []string
+	maxDepth   int64 // -1 for unlimited
+	maxEntries int64
+}
+
+func resolveScanOptions(opts *ScanOptions) (scanSettings, error) {
+	s := scanSettings{metadata: true, maxDepth: -1, maxEntries: DefaultScanMaxEntries}
+	if opts == nil {
+		return s, nil
+	}
+	if err := crucible.ValidateSchemaValue(ScanOptionsSchemaPath, opts); err != nil {
+		return s, NewError(CodeInvalidOptions, OperationScan, "invalid scan options").Wrap(err)
+	}
+	if opts.IncludeMetadata != nil {
+		s.metadata = *opts.IncludeMetadata
+	}
+	if opts.MaxDepth != nil {
+		s.maxDepth = *opts.MaxDepth
+	}
+	if opts.MaxEntries != nil {
+		s.maxEntries = *opts.MaxEntries
+	}
+	s.types = opts.EntryTypes
+	return s, nil
+}
+
+func (s scanSettings) wants(typ EntryType, depth int64) bool {
+	if s.maxDepth >= 0 && depth > s.maxDepth {
+		return false
+	}
+	if len(s.types) == 0 {
+		return true
+	}
+	for _, t := range s.types {
+		if t == string(typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan lists the entries of archive without extracting it, detecting the
+// format from the archive content.
+//
+// Scan is discovery, not enforcement: entry paths containing "../" are
+// returned as stored, absolute paths are made relative and reported through
+// the violation reporter, and symlink targets are returned unresolved. Use
+// Verify or Extract to enforce path safety. Entry types fulpack does not
+// handle (devices, fifos) are omitted.
+//
+// Entries beyond ScanOptions.MaxEntries abort the scan with
+// DECOMPRESSION_BOMB.
+func Scan(archive string, opts *ScanOptions) ([]ArchiveEntry, error) {
+	s, err := resolveScanOptions(opts)
+	if err != nil {
+		return nil, withArchive(err, archive)
+	}
+	entries, _, serr := scan(archive, s)
+	if serr != nil {
+		return nil, serr
+	}
+	return entries, nil
+}
+
+func scan(archive string, s scanSettings) ([]ArchiveEntry, ArchiveFormat, *Error) {
+	r, format, oerr := openArchive(archive, "", OperationScan)
+	if oerr != nil {
+		return nil, "", oerr
+	}
+	defer r.close()
+
+	entries := []ArchiveEntry{}
+	for index := 0; ; index++ {
+		h, err := r.next()
+		if err == io.EOF {
+			return entries, format, nil
+		}
+		if err != nil {
+			return nil, "", corrupt(err, OperationScan).WithArchive(archive)
+		}
+		if int64(index) >= s.maxEntries {
+			return nil, "", NewError(CodeDecompressionBomb, OperationScan,
+				fmt.Sprintf("archive has more than %d entries", s.maxEntries)).WithArchive(archive).
+				WithDetail("entry_index", index).WithDetail("max_entries", s.maxEntries)
+		}
+		if h.typ == "" {
+			continue
+		}
+		name := scanPath(h.name)
+		if isAbsolute(strings.ReplaceAll(h.name, `\`, "/")) {
+			ReportViolation(NewError(CodeAbsolutePath, OperationScan, "entry path is absolute; listed as relative").
+				WithArchive(archive).WithPath(h.name).WithDetail("entry_index", index))
+		}
+		if name == "" || !s.wants(h.typ, int64(strings.Count(name, "/"))) {
+			continue
+		}
+		entries = append(entries, newArchiveEntry(name, h, s.metadata))
+	}
+}
+
+// scanPath normalizes a raw entry name for listing. Absolute paths are made
+// relative and invalid UTF-8 is replaced with U+FFFD; names containing ".."
+// segments keep them so that traversal attempts stay visible.
+func scanPath(name string) string {
+	p := strings.ToValidUTF8(strings.ReplaceAll(name, `\`, "/"), "\uFFFD")
+	if len(p) >= 2 && p[1] == ':' && isAbsolute(p) {
+		p = p[2:]
+	}
+	p = strings.TrimLeft(p, "/")
+	for _, seg := range strings.Split(p, "/") {
+		if seg == ".." {
+			for strings.HasPrefix(p, "./") {
+				p = p[2:]
+			}
+			return strings.TrimSuffix(p, "/")
+		}
+	}
+	p = path.Clean(p)
+	if p == "." {
+		return ""
+	}
+	return p
+}
+
+func newArchiveEntry(name string, h *entryHeader, metadata bool) ArchiveEntry {
+	e := ArchiveEntry{Path: name, Type: string(h.typ)}
+	if h.typ == EntryTypeFile && h.size > 0 {
+		e.Size = h.size
+	}
+	if h.typ == EntryTypeSymlink {
+		target := h.link
+		e.SymlinkTarget = &target
+	}
+	if !metadata {
+		return e
+	}
+	if h.compressedSize != nil && h.typ == EntryTypeFile {
+		compressed := *h.compressedSize
+		e.CompressedSize = &compressed
+	}
+	if !h.modTime.IsZero() {
+		e.Modified = h.modTime.UTC().Format(time.RFC3339)
+	}
+	if alg, hexValue, ok := parseEntryChecksum(h.checksum); ok && alg == fulhash.SHA256 {
+		e.Checksum = &hexValue
+	}
+	if h.typ != EntryTypeSymlink {
+		mode := fmt.Sprintf("%04o", h.mode.Perm())
+		e.Mode = &mode
+	}
+	return e
+}
+
+// BuildManifest scans archive with default options and returns its table of
+// contents with an index by path, entry type and file extension.
+func BuildManifest(archive string) (*ArchiveManifest, error) {
+	s, _ := resolveScanOptions(nil)
+	entries, format, err := scan(archive, s)
+	if err != nil {
+		return nil, err
+	}
+	return newManifest(archive, format, entries), nil
+}
+
+func newManifest(archive string, format ArchiveFormat, entries []ArchiveEntry) *ArchiveManifest {
+	index := &ManifestIndex{
+		ByPath:      make(map[string]int64, len(entries)),
+		ByType:      map[string][]int64{},
+		ByExtension: map[string][]int64{},
+	}
+	var total int64
+	for i, e := range entries {
+		index.ByPath[e.Path] = int64(i)
+		index.ByType[e.Type] = append(index.ByType[e.Type], int64(i))
+		if e.Type != string(EntryTypeFile) {
+			continue
+		}
+		total += e.Size
+		if ext := path.Ext(e.Path); ext != "" {
+			index.ByExtension[ext] = append(index.ByExtension[ext], int64(i))
+		}
+	}
+
+	m := &ArchiveManifest{
+		Format:     string(format),
+		Version:    ManifestVersion,
+		Generated:  time.Now().UTC().Format(time.RFC3339),
+		EntryCount: int64(len(entries)),
+		Entries:    entries,
+		TotalSize:  &total,
+		Index:      index,
+	}
+	if fi, err := os.Stat(archive); err == nil {
+		size := fi.Size()
+		m.CompressedSize = &size
+	}
+	return m
+}
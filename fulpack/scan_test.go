@@ -0,0 +1,171 @@
+package fulpack
+
+import (
+	"archive/tar"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/fulmenhq/crucible"
+)
+
+func entryPaths(entries []ArchiveEntry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.Path
+	}
+	return out
+}
+
+func TestScanFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []string
+	}{
+		{"basic.tar", []string{"README.md", "config.json", "metadata.txt", "data", "data/sample.txt", "data/tiny.png"}},
+		{"basic.tar.gz", []string{"README.md", "file1.txt", "subdir", "subdir/file3.txt"}},
+		{"basic.tar.zst", []string{"README.md", "file1.txt", "subdir", "subdir/file3.txt"}},
+		{"basic.tar.xz", []string{"README.md", "file1.txt", "subdir", "subdir/file3.txt"}},
+		{"basic.tar.bz2", []string{"README.md", "file1.txt", "subdir", "subdir/file3.txt"}},
+		{"nested.zip", []string{"root.txt", "level1/file1.txt", "level1/level2/level3/deep.txt"}},
+		{"pathological.tar.gz", []string{"safe-traversal/..traversal1.txt", "safe-symlinks/symlink-test.txt", "../../../etc/passwd", "etc/passwd", "root/.ssh/id_rsa", "chain/up/escape"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			entries, err := Scan(filepath.Join(fixtureDir, tt.fixture), nil)
+			if err != nil {
+				t.Fatalf("Scan failed: %v", err)
+			}
+			paths := entryPaths(entries)
+			for _, p := range tt.want {
+				if !slices.Contains(paths, p) {
+					t.Errorf("%s missing from %v", p, paths)
+				}
+			}
+			for _, e := range entries {
+				if err := crucible.ValidateSchemaValue("library/fulpack/v1.0.0/archive-entry.schema.json", e); err != nil {
+					t.Errorf("entry %s does not match schema: %v", e.Path, err)
+				}
+			}
+		})
+	}
+}
+
+func TestScanMetadata(t *testing.T) {
+	archive := buildTar(t, []tarEntry{
+		{name: "dir/", typ: tar.TypeDir},
+		{name: "dir/a.txt", body: "hello", checksum: "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+		{name: "dir/link", typ: tar.TypeSymlink, link: "../outside"},
+	})
+
+	entries, err := Scan(archive, nil)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %v", entryPaths(entries))
+	}
+	file := entries[1]
+	if file.Type != "file" || file.Size != 5 || file.Modified == "" || file.Mode == nil || *file.Mode != "0644" {
+		t.Errorf("unexpected file entry %+v", file)
+	}
+	if file.Checksum == nil || *file.Checksum != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Errorf("checksum not reported: %v", file.Checksum)
+	}
+	link := entries[2]
+	if link.Type != "symlink" || link.SymlinkTarget == nil || *link.SymlinkTarget != "../outside" {
+		t.Errorf("symlink target must be reported unresolved: %+v", link)
+	}
+
+	bare, err := Scan(archive, &ScanOptions{IncludeMetadata: ptr(false)})
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if e := bare[1]; e.Modified != "" || e.Mode != nil || e.Checksum != nil || e.Size != 5 {
+		t.Errorf("metadata returned despite IncludeMetadata=false: %+v", e)
+	}
+}
+
+func TestScanFilters(t *testing.T) {
+	archive := filepath.Join(fixtureDir, "nested.zip")
+
+	files, err := Scan(archive, &ScanOptions{EntryTypes: []string{"file"}})
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	for _, e := range files {
+		if e.Type != "file" {
+			t.Errorf("unexpected %s entry %s", e.Type, e.Path)
+		}
+	}
+
+	shallow, err := Scan(archive, &ScanOptions{MaxDepth: ptr[int64](1)})
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	paths := entryPaths(shallow)
+	if !slices.Contains(paths, "level1/file1.txt") || slices.Contains(paths, "level1/level2/file2.txt") {
+		t.Errorf("max_depth 1 returned %v", paths)
+	}
+
+	_, err = Scan(archive, &ScanOptions{MaxEntries: ptr[int64](2)})
+	if !errors.Is(err, ErrDecompressionBomb) {
+		t.Errorf("expected DECOMPRESSION_BOMB for max_entries, got %v", err)
+	}
+
+	_, err = Scan(archive, &ScanOptions{EntryTypes: []string{"socket"}})
+	if !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("expected INVALID_OPTIONS, got %v", err)
+	}
+}
+
+func TestScanMaliciousPaths(t *testing.T) {
+	archive := buildTar(t, []tarEntry{
+		{name: "../../etc/passwd", body: "x"},
+		{name: "/etc/shadow", body: "x"},
+		{name: `C:\Windows\win.ini`, body: "x"},
+	})
+
+	var violations []Violation
+	SetViolationReporter(func(v Violation) { violations = append(violations, v) })
+	defer SetViolationReporter(nil)
+
+	entries, err := Scan(archive, nil)
+	if err != nil {
+		t.Fatalf("Scan must list malicious entries, got %v", err)
+	}
+	want := []string{"../../etc/passwd", "etc/shadow", "Windows/win.ini"}
+	if got := entryPaths(entries); !equalStrings(got, want) {
+		t.Errorf("paths = %v, want %v", got, want)
+	}
+	if len(violations) != 2 || violations[0].Type != "absolute_path" || violations[0].Operation != OperationScan {
+		t.Errorf("expected two absolute_path violations, got %+v", violations)
+	}
+}
+
+func TestBuildManifest(t *testing.T) {
+	archive := filepath.Join(fixtureDir, "nested.zip")
+	m, err := BuildManifest(archive)
+	if err != nil {
+		t.Fatalf("BuildManifest failed: %v", err)
+	}
+	if m.Format != "zip" || m.EntryCount != int64(len(m.Entries)) || m.CompressedSize == nil {
+		t.Errorf("unexpected manifest header %+v", m)
+	}
+	i, ok := m.Index.ByPath["level1/level2/level3/deep.txt"]
+	if !ok || m.Entries[i].Path != "level1/level2/level3/deep.txt" {
+		t.Errorf("path index does not resolve deep.txt")
+	}
+	for _, i := range m.Index.ByType["directory"] {
+		if m.Entries[i].Type != "directory" {
+			t.Errorf("type index points at %s entry", m.Entries[i].Type)
+		}
+	}
+	if len(m.Index.ByExtension[".txt"]) == 0 {
+		t.Error("extension index is empty")
+	}
+	if err := crucible.ValidateSchemaValue("library/fulpack/v1.0.0/archive-manifest.schema.json", m); err != nil {
+		t.Errorf("manifest does not match schema: %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package fulpack
+
+import (
+	"github.com/fulmenhq/crucible/pathfinder"
+)
+
+// sanitizeEntryPath validates a raw entry name and returns its normalized,
+// slash-separated form relative to the archive root. Entries naming the root
+// itself ("./") normalize to "".
+func sanitizeEntryPath(name string, op Operation) (string, *Error) {
+	p, err := pathfinder.CleanRelative(name)
+	if err != nil {
+		e := fromPathfinder(err, op, name)
+		e.Message = "entry " + e.Message
+		return "", e
+	}
+	return p, nil
+}
+
+// checkLinkTarget validates the target of a symlink (or hard link) stored at
+// the sanitized path name. Symlink targets are relative to the link's
+// directory; hard link targets are relative to the archive root. With links,
+// targets are also resolved through the symlinks of earlier entries, so a
+// chain of links cannot escape the root; without, the check is lexical.
+func checkLinkTarget(links *pathfinder.LinkChecker, name, target string, hardlink bool, op Operation) *Error {
+	var err *pathfinder.Error
+	if links != nil {
+		err = links.Check(name, target, hardlink)
+	} else {
+		err = pathfinder.CheckLinkTarget(name, target, hardlink)
+	}
+	if err != nil {
+		return fromPathfinder(err, op, name)
+	}
+	return nil
+}
+
+// isAbsolute reports whether a slash-separated path is absolute on any
+// platform: rooted, a Windows drive path, or a UNC path.
+func isAbsolute(p string) bool {
+	return pathfinder.IsAbsolute(p)
+}
+
+// fromPathfinder converts a pathfinder path violation for entry name into a
+// fulpack error. The path violation codes are shared by both packages.
+func fromPathfinder(err *pathfinder.Error, op Operation, name string) *Error {
+	e := NewError(ErrorCode(err.Code), op, err.Message).WithPath(name)
+	for k, v := range err.Details {
+		e.WithDetail(k, v)
+	}
+	return e
+}
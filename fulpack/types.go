@@ -23,10 +23,13 @@ import (
 type ArchiveFormat string
 
 const (
-	ArchiveFormatTar   ArchiveFormat = "tar"
-	ArchiveFormatTarGz ArchiveFormat = "tar.gz"
-	ArchiveFormatZip   ArchiveFormat = "zip"
-	ArchiveFormatGzip  ArchiveFormat = "gzip"
+	ArchiveFormatTar    ArchiveFormat = "tar"
+	ArchiveFormatTarGz  ArchiveFormat = "tar.gz"
+	ArchiveFormatZip    ArchiveFormat = "zip"
+	ArchiveFormatGzip   ArchiveFormat = "gzip"
+	ArchiveFormatTarZst ArchiveFormat = "tar.zst"
+	ArchiveFormatTarXz  ArchiveFormat = "tar.xz"
+	ArchiveFormatTarBz2 ArchiveFormat = "tar.bz2"
 )
 
 // ValidateArchiveFormat checks if the value is valid.
@@ -36,6 +39,9 @@ func ValidateArchiveFormat(value ArchiveFormat) error {
 	case ArchiveFormatTarGz:
 	case ArchiveFormatZip:
 	case ArchiveFormatGzip:
+	case ArchiveFormatTarZst:
+	case ArchiveFormatTarXz:
+	case ArchiveFormatTarBz2:
 	default:
 		return fmt.Errorf("invalid archiveformat: %s", value)
 	}
@@ -74,6 +80,7 @@ const (
 	OperationScan    Operation = "scan"
 	OperationVerify  Operation = "verify"
 	OperationInfo    Operation = "info"
+	OperationDiff    Operation = "diff"
 )
 
 // ValidateOperation checks if the value is valid.
@@ -84,6 +91,7 @@ func ValidateOperation(value Operation) error {
 	case OperationScan:
 	case OperationVerify:
 	case OperationInfo:
+	case OperationDiff:
 	default:
 		return fmt.Errorf("invalid operation: %s", value)
 	}
@@ -106,6 +114,7 @@ type ArchiveInfo struct {
 	HasChecksums      *bool    `json:"has_checksums,omitempty"`      // Whether the archive contains checksums
 	ChecksumAlgorithm string   `json:"checksum_algorithm,omitempty"` // Checksum algorithm used from fulhash module (xxh3-128 and sha256 are standard, others may require optional extensions)
 	Created           string   `json:"created,omitempty"`            // Archive creation timestamp (ISO 8601 format)
+	Warnings          []string `json:"warnings,omitempty"`           // Array of warning messages (e.g., symlinks skipped by formats that cannot store them)
 }
 
 // ArchiveEntry metadata for a single archive entry (returned by scan operation).
@@ -124,14 +133,14 @@ type ArchiveEntry struct {
 // ArchiveManifest complete archive table of contents (for large archives and caching).
 // See: schemas/library/fulpack/v1.0.0/archive-manifest.schema.json
 type ArchiveManifest struct {
-	Format         string                  `json:"format"`                    // Archive format from archive-formats taxonomy
-	Version        string                  `json:"version"`                   // Manifest schema version (semantic versioning)
-	Generated      string                  `json:"generated"`                 // Manifest generation timestamp (ISO 8601 format)
-	EntryCount     int64                   `json:"entry_count"`               // Total number of entries in manifest
-	Entries        []interface{}           `json:"entries"`                   // Array of archive entries
-	TotalSize      *int64                  `json:"total_size,omitempty"`      // Total uncompressed size in bytes
-	CompressedSize *int64                  `json:"compressed_size,omitempty"` // Compressed archive file size in bytes
-	Index          *map[string]interface{} `json:"index,omitempty"`           // Optional searchable index for fast lookups
+	Format         string         `json:"format"`                    // Archive format from archive-formats taxonomy
+	Version        string         `json:"version"`                   // Manifest schema version (semantic versioning)
+	Generated      string         `json:"generated"`                 // Manifest generation timestamp (ISO 8601 format)
+	EntryCount     int64          `json:"entry_count"`               // Total number of entries in manifest
+	Entries        []ArchiveEntry `json:"entries"`                   // Array of archive entries
+	TotalSize      *int64         `json:"total_size,omitempty"`      // Total uncompressed size in bytes
+	CompressedSize *int64         `json:"compressed_size,omitempty"` // Compressed archive file size in bytes
+	Index          *ManifestIndex `json:"index,omitempty"`           // Optional searchable index for fast lookups
 }
 
 // ValidationResult result of archive integrity verification (from verify operation).
@@ -157,6 +166,28 @@ type ExtractResult struct {
 	TotalBytes        *int64   `json:"total_bytes,omitempty"`        // Total bytes extracted
 }
 
+// ArchiveDiff entry-level comparison of a base archive and a target archive (from diff operation).
+// See: schemas/library/fulpack/v1.0.0/archive-diff.schema.json
+type ArchiveDiff struct {
+	Base              string         `json:"base"`               // Path of the base (previous) archive
+	Target            string         `json:"target"`             // Path of the target (candidate) archive
+	Identical         bool           `json:"identical"`          // Whether the archives have the same entries with the same content, modes and symlink targets
+	Added             []ArchiveEntry `json:"added"`              // Entries present only in the target archive
+	Removed           []ArchiveEntry `json:"removed"`            // Entries present only in the base archive
+	Modified          []EntryChange  `json:"modified"`           // Entries whose content checksum or entry type differs
+	ModeChanged       []EntryChange  `json:"mode_changed"`       // Entries whose Unix permissions differ
+	SymlinkRetargeted []EntryChange  `json:"symlink_retargeted"` // Symlinks whose target differs
+	UnchangedCount    int64          `json:"unchanged_count"`    // Number of entries identical in both archives
+}
+
+// EntryChange an entry present in both archives of a diff, with its metadata on each side.
+// See: schemas/library/fulpack/v1.0.0/entry-change.schema.json
+type EntryChange struct {
+	Path   string       `json:"path"`   // Normalized entry path within both archives
+	Before ArchiveEntry `json:"before"` // Entry metadata in the base archive
+	After  ArchiveEntry `json:"after"`  // Entry metadata in the target archive
+}
+
 // ============================================================================
 // Options
 // ============================================================================
@@ -0,0 +1,167 @@
+package fulpack
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fulmenhq/crucible/fulhash"
+	"github.com/fulmenhq/crucible/pathfinder"
+)
+
+// Checks reported in ValidationResult.ChecksPerformed.
+const (
+	CheckStructureValid      = "structure_valid"
+	CheckChecksumsVerified   = "checksums_verified"
+	CheckNoPathTraversal     = "no_path_traversal"
+	CheckNoDecompressionBomb = "no_decompression_bomb"
+	CheckSymlinksSafe        = "symlinks_safe"
+)
+
+// Verify checks the integrity and safety of archive without extracting it,
+// detecting the format from the archive content.
+//
+// Every entry is read: the archive structure is validated, embedded
+// checksums are recomputed, entry paths are checked for absolute paths and
+// "../" traversal, links are checked for targets outside the archive root,
+// and entry counts and decompressed sizes are held to DefaultMaxEntries and
+// DefaultMaxSize. Findings are collected in the result rather than returned
+// as errors, and security findings are also sent to the violation reporter.
+// Missing checksums are warnings. An error is returned only when the archive
+// cannot be opened at all.
+func Verify(archive string) (*ValidationResult, error) {
+	r, _, oerr := openArchive(archive, "", OperationVerify)
+	if oerr != nil {
+		if oerr.Code != CodeArchiveCorrupt {
+			return nil, oerr
+		}
+		return &ValidationResult{
+			Errors:          []string{oerr.Error()},
+			Warnings:        []string{},
+			ChecksPerformed: []string{CheckStructureValid},
+		}, nil
+	}
+	defer r.close()
+
+	v := &verifier{
+		res: &ValidationResult{
+			Valid:    true,
+			Errors:   []string{},
+			Warnings: []string{},
+			ChecksPerformed: []string{
+				CheckStructureValid,
+				CheckChecksumsVerified,
+				CheckNoPathTraversal,
+				CheckNoDecompressionBomb,
+				CheckSymlinksSafe,
+			},
+		},
+		archive: archive,
+	}
+	v.run(r)
+	return v.finish(), nil
+}
+
+type verifier struct {
+	res      *ValidationResult
+	archive  string
+	verified int64
+	missing  int64
+	total    int64 // decompressed bytes read
+	links    pathfinder.LinkChecker
+}
+
+func (v *verifier) fail(err *Error) {
+	err = err.WithArchive(v.archive)
+	ReportViolation(err)
+	v.res.Valid = false
+	v.res.Errors = append(v.res.Errors, err.Error())
+}
+
+// run reads every entry, recording findings. It stops at the first
+// structural failure or size limit breach, since later entries cannot be
+// trusted or would only add to the breach.
+func (v *verifier) run(r archiveReader) {
+	for index := 0; ; index++ {
+		h, err := r.next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			v.fail(corrupt(err, OperationVerify).WithDetail("entry_index", index))
+			return
+		}
+		v.res.EntryCount++
+		if v.res.EntryCount > DefaultMaxEntries {
+			v.fail(NewError(CodeDecompressionBomb, OperationVerify,
+				fmt.Sprintf("archive has more than %d entries", DefaultMaxEntries)).
+				WithDetail("entry_index", index).WithDetail("max_entries", DefaultMaxEntries))
+			return
+		}
+
+		name, perr := sanitizeEntryPath(h.name, OperationVerify)
+		if perr != nil {
+			v.fail(perr.WithDetail("entry_index", index))
+			name = h.name
+		} else if h.typ == EntryTypeSymlink || h.hardlink {
+			if lerr := checkLinkTarget(&v.links, name, h.link, h.hardlink, OperationVerify); lerr != nil {
+				v.fail(lerr.WithDetail("entry_index", index))
+			}
+		}
+		if h.typ != EntryTypeFile || h.hardlink {
+			continue
+		}
+		if ferr := v.readFile(r, h); ferr != nil {
+			v.fail(ferr.WithPath(name).WithDetail("entry_index", index))
+			if ferr.Code != CodeChecksumMismatch {
+				return
+			}
+		}
+	}
+}
+
+// readFile reads the current entry, counting its bytes against
+// DefaultMaxSize and checking its embedded checksum.
+func (v *verifier) readFile(r archiveReader, h *entryHeader) *Error {
+	src, err := r.open()
+	if err != nil {
+		return corrupt(err, OperationVerify)
+	}
+	var hasher fulhash.StreamHasher
+	alg, want, ok := parseEntryChecksum(h.checksum)
+	if ok {
+		if hasher, err = fulhash.NewStreamHasher(alg); err == nil {
+			src = io.TeeReader(src, hasher)
+		}
+	}
+
+	budget := DefaultMaxSize - v.total
+	n, err := io.Copy(io.Discard, io.LimitReader(src, budget+1))
+	v.total += n
+	switch {
+	case n > budget:
+		return NewError(CodeDecompressionBomb, OperationVerify, "archive exceeds maximum size limit").
+			WithDetail("actual_size", v.total).WithDetail("max_size", DefaultMaxSize)
+	case err != nil:
+		return corrupt(err, OperationVerify)
+	case hasher == nil:
+		v.missing++
+	case hasher.Sum().Hex != want:
+		return NewError(CodeChecksumMismatch, OperationVerify, "entry checksum verification failed").
+			WithDetail("expected", h.checksum).WithDetail("actual", hasher.Sum().Formatted)
+	default:
+		v.verified++
+	}
+	return nil
+}
+
+func (v *verifier) finish() *ValidationResult {
+	v.res.ChecksumsVerified = &v.verified
+	if v.missing > 0 {
+		v.res.Warnings = append(v.res.Warnings, fmt.Sprintf("%d files have no embedded checksum", v.missing))
+	}
+	if fi, err := os.Stat(v.archive); err == nil && fi.Size() > 0 && v.total/fi.Size() > suspiciousRatio {
+		v.res.Warnings = append(v.res.Warnings, fmt.Sprintf("compression ratio %d:1 exceeds %d:1", v.total/fi.Size(), suspiciousRatio))
+	}
+	return v.res
+}
@@ -0,0 +1,134 @@
+package fulpack
+
+import (
+	"archive/tar"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fulmenhq/crucible"
+)
+
+func TestVerifyFixtures(t *testing.T) {
+	for _, fixture := range []string{"basic.tar", "basic.tar.gz", "basic.tar.zst", "basic.tar.xz", "basic.tar.bz2", "nested.zip"} {
+		t.Run(fixture, func(t *testing.T) {
+			res, err := Verify(filepath.Join(fixtureDir, fixture))
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+			if !res.Valid || len(res.Errors) != 0 {
+				t.Errorf("expected valid archive, got errors %v", res.Errors)
+			}
+			if len(res.ChecksPerformed) != 5 {
+				t.Errorf("checks_performed = %v", res.ChecksPerformed)
+			}
+			if len(res.Warnings) == 0 || !strings.Contains(res.Warnings[0], "no embedded checksum") {
+				t.Errorf("expected a missing-checksum warning, got %v", res.Warnings)
+			}
+			if err := crucible.ValidateSchemaValue("library/fulpack/v1.0.0/validation-result.schema.json", res); err != nil {
+				t.Errorf("result does not match schema: %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	src := writeTree(t, sampleTree)
+	archive := filepath.Join(t.TempDir(), "sample.tar.gz")
+	if _, err := Create(src, archive, ArchiveFormatTarGz, nil); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	res, err := Verify(archive)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !res.Valid || *res.ChecksumsVerified != int64(len(sampleTree)) || len(res.Warnings) != 0 {
+		t.Errorf("unexpected result %+v", res)
+	}
+
+	tampered := buildTar(t, []tarEntry{
+		{name: "a.txt", body: "tampered", checksum: "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+	})
+	res, err = Verify(tampered)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if res.Valid || len(res.Errors) != 1 || !strings.Contains(res.Errors[0], string(CodeChecksumMismatch)) {
+		t.Errorf("expected a checksum mismatch, got %+v", res)
+	}
+}
+
+func TestVerifyMaliciousEntries(t *testing.T) {
+	archive := buildTar(t, []tarEntry{
+		{name: "ok.txt", body: "ok"},
+		{name: "../../etc/passwd", body: "x"},
+		{name: "/etc/shadow", body: "x"},
+		{name: "link", typ: tar.TypeSymlink, link: "../../outside"},
+	})
+
+	var violations []Violation
+	SetViolationReporter(func(v Violation) { violations = append(violations, v) })
+	defer SetViolationReporter(nil)
+
+	res, err := Verify(archive)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if res.Valid || res.EntryCount != 4 {
+		t.Errorf("expected an invalid result over 4 entries, got %+v", res)
+	}
+	for _, code := range []ErrorCode{CodePathTraversal, CodeAbsolutePath, CodeSymlinkEscape} {
+		found := false
+		for _, e := range res.Errors {
+			found = found || strings.Contains(e, string(code))
+		}
+		if !found {
+			t.Errorf("%s not reported in %v", code, res.Errors)
+		}
+	}
+	if len(violations) != 3 {
+		t.Errorf("expected 3 violations, got %+v", violations)
+	}
+}
+
+func TestVerifyPathologicalFixture(t *testing.T) {
+	res, err := Verify(filepath.Join(fixtureDir, "pathological.tar.gz"))
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	want := []string{
+		`PATH_TRAVERSAL: entry path escapes the root (entry "../../../etc/passwd")`,
+		`ABSOLUTE_PATH: entry path is absolute (entry "/etc/passwd")`,
+		`ABSOLUTE_PATH: entry path is absolute (entry "/root/.ssh/id_rsa")`,
+		`SYMLINK_ESCAPE: link target "../../../../etc/passwd" is outside the root (entry "escape/passwd")`,
+		`SYMLINK_ESCAPE: link target "../.." resolves outside the root through other links (entry "chain/up/escape")`,
+	}
+	if res.Valid || len(res.Errors) != len(want) {
+		t.Fatalf("expected %d errors, got %+v", len(want), res)
+	}
+	for i, w := range want {
+		if !strings.Contains(res.Errors[i], w) {
+			t.Errorf("error %d = %q, want %q", i, res.Errors[i], w)
+		}
+	}
+}
+
+func TestVerifyInvalidArchive(t *testing.T) {
+	if _, err := Verify(filepath.Join(t.TempDir(), "missing.tar")); !errors.Is(err, ErrArchiveNotFound) {
+		t.Errorf("expected ARCHIVE_NOT_FOUND, got %v", err)
+	}
+
+	garbage := filepath.Join(t.TempDir(), "garbage.tar.gz")
+	if err := os.WriteFile(garbage, []byte("not a gzip stream"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res, err := Verify(garbage)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if res.Valid || len(res.Errors) != 1 {
+		t.Errorf("expected a structure error, got %+v", res)
+	}
+}
@@ -2,4 +2,13 @@ module github.com/fulmenhq/crucible
 
 go 1.25
 
-require gopkg.in/yaml.v3 v3.0.1
+require (
+	github.com/klauspost/compress v1.18.0
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.2
+	github.com/ulikunitz/xz v0.5.12
+	github.com/zeebo/xxh3 v1.0.2
+	golang.org/x/text v0.28.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require github.com/klauspost/cpuid/v2 v2.0.9 // indirect
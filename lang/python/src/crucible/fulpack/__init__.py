@@ -33,9 +33,11 @@ from .options import (
 
 # Data structures
 from .types import (
+    ArchiveDiff,
     ArchiveEntry,
     ArchiveInfo,
     ArchiveManifest,
+    EntryChange,
     ExtractResult,
     ValidationResult,
 )
@@ -51,6 +53,8 @@ __all__ = [
     "ArchiveManifest",
     "ValidationResult",
     "ExtractResult",
+    "ArchiveDiff",
+    "EntryChange",
     # Options
     "CreateOptions",
     "ExtractOptions",
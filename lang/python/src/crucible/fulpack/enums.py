@@ -27,6 +27,12 @@ class ArchiveFormat(StrEnum):
 
     GZIP = "gzip"
 
+    TAR_ZST = "tar.zst"
+
+    TAR_XZ = "tar.xz"
+
+    TAR_BZ2 = "tar.bz2"
+
 
 class EntryType(StrEnum):
     """EntryType enum
@@ -56,3 +62,5 @@ class Operation(StrEnum):
     VERIFY = "verify"
 
     INFO = "info"
+
+    DIFF = "diff"
@@ -21,7 +21,7 @@ class ArchiveInfo:
     Generated from: schemas/library/fulpack/v1.0.0/archive-info.schema.json
     """
 
-    format: Literal["tar", "tar.gz", "zip", "gzip"]  # Archive format from archive-formats taxonomy
+    format: Literal["tar", "tar.gz", "zip", "gzip", "tar.zst", "tar.xz", "tar.bz2"]  # Archive format from archive-formats taxonomy
 
     entry_count: int  # Total number of entries in the archive
 
@@ -29,7 +29,7 @@ class ArchiveInfo:
 
     compressed_size: int  # Compressed archive file size in bytes
 
-    compression: Literal["gzip", "deflate", "none"] | None = None  # Compression algorithm used
+    compression: Literal["gzip", "deflate", "zstd", "xz", "bzip2", "none"] | None = None  # Compression algorithm used
 
     compression_ratio: float | None = None  # Compression ratio (total_size / compressed_size)
 
@@ -41,6 +41,8 @@ class ArchiveInfo:
 
     created: str | None = None  # Archive creation timestamp (ISO 8601 format)
 
+    warnings: list[str] | None = None  # Array of warning messages (e.g., symlinks skipped by formats that cannot store them)
+
 
 @dataclass
 class ArchiveEntry:
@@ -73,7 +75,7 @@ class ArchiveManifest:
     Generated from: schemas/library/fulpack/v1.0.0/archive-manifest.schema.json
     """
 
-    format: Literal["tar", "tar.gz", "zip", "gzip"]  # Archive format from archive-formats taxonomy
+    format: Literal["tar", "tar.gz", "zip", "gzip", "tar.zst", "tar.xz", "tar.bz2"]  # Archive format from archive-formats taxonomy
 
     version: str  # Manifest schema version (semantic versioning)
 
@@ -137,3 +139,43 @@ class ExtractResult:
     checksums_verified: int | None = None  # Number of checksums successfully verified during extraction
 
     total_bytes: int | None = None  # Total bytes extracted
+
+
+@dataclass
+class ArchiveDiff:
+    """Entry-level comparison of a base archive and a target archive (from diff operation)
+
+    Generated from: schemas/library/fulpack/v1.0.0/archive-diff.schema.json
+    """
+
+    base: str  # Path of the base (previous) archive
+
+    target: str  # Path of the target (candidate) archive
+
+    identical: bool  # Whether the archives have the same entries with the same content, modes and symlink targets
+
+    added: list[Any]  # Entries present only in the target archive
+
+    removed: list[Any]  # Entries present only in the base archive
+
+    modified: list[Any]  # Entries whose content checksum or entry type differs
+
+    mode_changed: list[Any]  # Entries whose Unix permissions differ
+
+    symlink_retargeted: list[Any]  # Symlinks whose target differs
+
+    unchanged_count: int  # Number of entries identical in both archives
+
+
+@dataclass
+class EntryChange:
+    """An entry present in both archives of a diff, with its metadata on each side
+
+    Generated from: schemas/library/fulpack/v1.0.0/entry-change.schema.json
+    """
+
+    path: str  # Normalized entry path within both archives
+
+    before: Any  # Entry metadata in the base archive
+
+    after: Any  # Entry metadata in the target archive
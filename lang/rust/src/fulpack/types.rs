@@ -25,6 +25,15 @@ pub enum ArchiveFormat {
     /// GZIP compressed single file
     #[serde(rename = "gzip")]
     Gzip,
+    /// POSIX tar archive with Zstandard compression
+    #[serde(rename = "tar.zst")]
+    TarZst,
+    /// POSIX tar archive with xz (LZMA2) compression
+    #[serde(rename = "tar.xz")]
+    TarXz,
+    /// POSIX tar archive with bzip2 compression
+    #[serde(rename = "tar.bz2")]
+    TarBz2,
 }
 
 impl std::fmt::Display for ArchiveFormat {
@@ -34,6 +43,9 @@ impl std::fmt::Display for ArchiveFormat {
             Self::TarGz => write!(f, "tar.gz"),
             Self::Zip => write!(f, "zip"),
             Self::Gzip => write!(f, "gzip"),
+            Self::TarZst => write!(f, "tar.zst"),
+            Self::TarXz => write!(f, "tar.xz"),
+            Self::TarBz2 => write!(f, "tar.bz2"),
         }
     }
 }
@@ -82,6 +94,9 @@ pub enum Operation {
     /// Get archive metadata without extraction
     #[serde(rename = "info")]
     Info,
+    /// Compare the entries of two archives
+    #[serde(rename = "diff")]
+    Diff,
 }
 
 impl std::fmt::Display for Operation {
@@ -92,6 +107,7 @@ impl std::fmt::Display for Operation {
             Self::Scan => write!(f, "scan"),
             Self::Verify => write!(f, "verify"),
             Self::Info => write!(f, "info"),
+            Self::Diff => write!(f, "diff"),
         }
     }
 }
@@ -137,6 +153,10 @@ pub struct ArchiveInfo {
     #[serde(rename = "created")]
     #[serde(skip_serializing_if = "Option::is_none")]
     pub created: Option<String>,
+    /// Array of warning messages (e.g., symlinks skipped by formats that cannot store them)
+    #[serde(rename = "warnings")]
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub warnings: Option<Vec<String>>,
 }
 
 /// ArchiveEntry metadata for a single archive entry (returned by scan operation).
@@ -268,6 +288,54 @@ pub struct ExtractResult {
     pub total_bytes: Option<i64>,
 }
 
+/// ArchiveDiff entry-level comparison of a base archive and a target archive (from diff operation).
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(rename_all = "camelCase")]
+pub struct ArchiveDiff {
+    /// Path of the base (previous) archive
+    #[serde(rename = "base")]
+    pub base: String,
+    /// Path of the target (candidate) archive
+    #[serde(rename = "target")]
+    pub target: String,
+    /// Whether the archives have the same entries with the same content, modes and symlink targets
+    #[serde(rename = "identical")]
+    pub identical: bool,
+    /// Entries present only in the target archive
+    #[serde(rename = "added")]
+    pub added: Vec<serde_json::Value>,
+    /// Entries present only in the base archive
+    #[serde(rename = "removed")]
+    pub removed: Vec<serde_json::Value>,
+    /// Entries whose content checksum or entry type differs
+    #[serde(rename = "modified")]
+    pub modified: Vec<serde_json::Value>,
+    /// Entries whose Unix permissions differ
+    #[serde(rename = "mode_changed")]
+    pub mode_changed: Vec<serde_json::Value>,
+    /// Symlinks whose target differs
+    #[serde(rename = "symlink_retargeted")]
+    pub symlink_retargeted: Vec<serde_json::Value>,
+    /// Number of entries identical in both archives
+    #[serde(rename = "unchanged_count")]
+    pub unchanged_count: i64,
+}
+
+/// EntryChange an entry present in both archives of a diff, with its metadata on each side.
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(rename_all = "camelCase")]
+pub struct EntryChange {
+    /// Normalized entry path within both archives
+    #[serde(rename = "path")]
+    pub path: String,
+    /// Entry metadata in the base archive
+    #[serde(rename = "before")]
+    pub before: serde_json::Value,
+    /// Entry metadata in the target archive
+    #[serde(rename = "after")]
+    pub after: serde_json::Value,
+}
+
 // ============================================================================
 // Options
 // ============================================================================
@@ -28,6 +28,12 @@ export enum ArchiveFormat {
   ZIP = "zip",
   /** GZIP compressed single file */
   GZIP = "gzip",
+  /** POSIX tar archive with Zstandard compression */
+  TAR_ZST = "tar.zst",
+  /** POSIX tar archive with xz (LZMA2) compression */
+  TAR_XZ = "tar.xz",
+  /** POSIX tar archive with bzip2 compression */
+  TAR_BZ2 = "tar.bz2",
 }
 
 /**
@@ -58,6 +64,8 @@ export enum Operation {
   VERIFY = "verify",
   /** Get archive metadata without extraction */
   INFO = "info",
+  /** Compare the entries of two archives */
+  DIFF = "diff",
 }
 
 // ============================================================================
@@ -133,15 +141,16 @@ export interface FulpackError {
  * @see schemas/library/fulpack/v1.0.0/archive-info.schema.json
  */
 export interface ArchiveInfo {
-  readonly format: "tar" | "tar.gz" | "zip" | "gzip"; // Archive format from archive-formats taxonomy
+  readonly format: "tar" | "tar.gz" | "zip" | "gzip" | "tar.zst" | "tar.xz" | "tar.bz2"; // Archive format from archive-formats taxonomy
   readonly entry_count: number; // Total number of entries in the archive
   readonly total_size: number; // Total uncompressed size in bytes
   readonly compressed_size: number; // Compressed archive file size in bytes
-  readonly compression?: "gzip" | "deflate" | "none"; // Compression algorithm used
+  readonly compression?: "gzip" | "deflate" | "zstd" | "xz" | "bzip2" | "none"; // Compression algorithm used
   readonly compression_ratio?: number; // Compression ratio (total_size / compressed_size)
   readonly has_checksums?: boolean; // Whether the archive contains checksums
   readonly checksum_algorithm?: "xxh3-128" | "sha256" | "sha512" | "sha1" | "md5"; // Checksum algorithm used from fulhash module (xxh3-128 and sha256 are standard, others may require optional extensions)
   readonly created?: string; // Archive creation timestamp (ISO 8601 format)
+  readonly warnings?: string[]; // Array of warning messages (e.g., symlinks skipped by formats that cannot store them)
 }
 
 /**
@@ -164,7 +173,7 @@ export interface ArchiveEntry {
  * @see schemas/library/fulpack/v1.0.0/archive-manifest.schema.json
  */
 export interface ArchiveManifest {
-  readonly format: "tar" | "tar.gz" | "zip" | "gzip"; // Archive format from archive-formats taxonomy
+  readonly format: "tar" | "tar.gz" | "zip" | "gzip" | "tar.zst" | "tar.xz" | "tar.bz2"; // Archive format from archive-formats taxonomy
   readonly version: string; // Manifest schema version (semantic versioning)
   readonly generated: string; // Manifest generation timestamp (ISO 8601 format)
   readonly entry_count: number; // Total number of entries in manifest
@@ -207,6 +216,32 @@ export interface ExtractResult {
   readonly total_bytes?: number; // Total bytes extracted
 }
 
+/**
+ * Entry-level comparison of a base archive and a target archive (from diff operation)
+ * @see schemas/library/fulpack/v1.0.0/archive-diff.schema.json
+ */
+export interface ArchiveDiff {
+  readonly base: string; // Path of the base (previous) archive
+  readonly target: string; // Path of the target (candidate) archive
+  readonly identical: boolean; // Whether the archives have the same entries with the same content, modes and symlink targets
+  readonly added: unknown[]; // Entries present only in the target archive
+  readonly removed: unknown[]; // Entries present only in the base archive
+  readonly modified: unknown[]; // Entries whose content checksum or entry type differs
+  readonly mode_changed: unknown[]; // Entries whose Unix permissions differ
+  readonly symlink_retargeted: unknown[]; // Symlinks whose target differs
+  readonly unchanged_count: number; // Number of entries identical in both archives
+}
+
+/**
+ * An entry present in both archives of a diff, with its metadata on each side
+ * @see schemas/library/fulpack/v1.0.0/entry-change.schema.json
+ */
+export interface EntryChange {
+  readonly path: string; // Normalized entry path within both archives
+  readonly before: unknown; // Entry metadata in the base archive
+  readonly after: unknown; // Entry metadata in the target archive
+}
+
 // ============================================================================
 // Options (Partial Interfaces)
 // ============================================================================
@@ -17,6 +17,9 @@ export interface TerminalCatalogConfig {
     env?: Record<string, string>;
     term_program?: string;
   };
+  capabilities?: {
+    color?: boolean;
+  };
   overrides: {
     emoji_width?: number;
     specific_chars?: Record<string, number>;
@@ -12,6 +12,9 @@ export interface TerminalConfig {
     env?: Record<string, string>;
     term_program?: string;
   };
+  capabilities?: {
+    color?: boolean;
+  };
   overrides: {
     emoji_width?: number;
     specific_chars?: Record<string, number>;
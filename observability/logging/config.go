@@ -0,0 +1,63 @@
+package logging
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/fulmenhq/crucible"
+	"gopkg.in/yaml.v3"
+)
+
+// serviceNamePattern is the serviceName pattern from definitions.schema.json;
+// every event carries the service, so the config must satisfy it.
+var serviceNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*[a-z0-9]$`)
+
+// DefaultConfig returns the configuration with every schema default applied.
+// Service has no default and must be set.
+func DefaultConfig() *LoggerConfig {
+	return &LoggerConfig{
+		Profile:      ProfileSimple,
+		Environment:  "development",
+		DefaultLevel: SeverityInfo,
+	}
+}
+
+// LoadConfig parses a YAML or JSON logger configuration, validates it
+// against the embedded logger-config schema, and overlays it on the
+// defaults.
+func LoadConfig(data []byte) (*LoggerConfig, error) {
+	if err := crucible.ValidateSchemaData(LoggerConfigSchemaPath, data); err != nil {
+		return nil, fmt.Errorf("invalid logger config: %w", err)
+	}
+
+	cfg := DefaultConfig()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse logger config: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks the configuration against the schema as well as
+// constraints the schema cannot express: the service must be a valid event
+// service name (lowercase alphanumeric with hyphens), loggerLevels keys must
+// match the Foundry logger-name pattern, and enabled throttling needs a
+// maxRate.
+func (c *LoggerConfig) Validate() error {
+	if err := crucible.ValidateSchemaValue(LoggerConfigSchemaPath, c); err != nil {
+		return fmt.Errorf("invalid logger config: %w", err)
+	}
+	if !serviceNamePattern.MatchString(c.Service) {
+		return fmt.Errorf("invalid logger config: service %q must be lowercase alphanumeric with hyphens", c.Service)
+	}
+	if err := validateLoggerLevels(c.LoggerLevels); err != nil {
+		return fmt.Errorf("invalid logger config: %w", err)
+	}
+	if t := c.Throttling; t != nil && t.Enabled && t.MaxRate == 0 {
+		return fmt.Errorf("invalid logger config: throttling.maxRate is required when throttling is enabled")
+	}
+	return nil
+}
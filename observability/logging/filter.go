@@ -0,0 +1,143 @@
+package logging
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/fulmenhq/crucible"
+	"gopkg.in/yaml.v3"
+)
+
+// Matches reports whether s satisfies the filter, comparing numeric
+// severity levels. Unknown severities and operators never match.
+func (f SeverityFilter) Matches(s Severity) bool {
+	got, want := s.Level(), f.Level.Level()
+	if got < 0 || want < 0 {
+		return false
+	}
+	switch f.Operator {
+	case OpGE:
+		return got >= want
+	case OpLE:
+		return got <= want
+	case OpEQ:
+		return got == want
+	case OpNE:
+		return got != want
+	case OpGT:
+		return got > want
+	case OpLT:
+		return got < want
+	}
+	return false
+}
+
+// foundryPatterns compiles the regex entries of the Foundry pattern catalog
+// (config/library/foundry/patterns.yaml) by id, honoring their Go flags.
+var foundryPatterns = sync.OnceValues(func() (map[string]*regexp.Regexp, error) {
+	var catalog struct {
+		Patterns []struct {
+			ID      string `yaml:"id"`
+			Kind    string `yaml:"kind"`
+			Pattern string `yaml:"pattern"`
+			Flags   struct {
+				Go struct {
+					IgnoreCase bool `yaml:"ignoreCase"`
+				} `yaml:"go"`
+			} `yaml:"flags"`
+		} `yaml:"patterns"`
+	}
+	data, err := crucible.ConfigRegistry.Library().Foundry().Patterns()
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse pattern catalog: %w", err)
+	}
+	out := map[string]*regexp.Regexp{}
+	for _, p := range catalog.Patterns {
+		if p.Kind != "regex" {
+			continue
+		}
+		expr := p.Pattern
+		if p.Flags.Go.IgnoreCase {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %s: %w", p.ID, err)
+		}
+		out[p.ID] = re
+	}
+	return out, nil
+})
+
+// foundryPattern returns the compiled catalog pattern with id.
+func foundryPattern(id string) (*regexp.Regexp, error) {
+	patterns, err := foundryPatterns()
+	if err != nil {
+		return nil, err
+	}
+	re, ok := patterns[id]
+	if !ok {
+		return nil, fmt.Errorf("pattern %s not found in the Foundry catalog", id)
+	}
+	return re, nil
+}
+
+// validateLoggerLevels checks LoggerLevels keys against the Foundry
+// logger-name pattern.
+func validateLoggerLevels(levels map[string]Severity) error {
+	if len(levels) == 0 {
+		return nil
+	}
+	re, err := foundryPattern("logger-name")
+	if err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(levels)) {
+		if !re.MatchString(name) {
+			return fmt.Errorf("loggerLevels: %q is not a dot-separated logger name", name)
+		}
+	}
+	return nil
+}
+
+// loggerLevel is a LoggerLevels entry.
+type loggerLevel struct {
+	name string
+	min  slog.Level
+}
+
+// newLoggerLevels orders the entries longest name first, so the first
+// match is the most specific.
+func newLoggerLevels(levels map[string]Severity) []loggerLevel {
+	out := make([]loggerLevel, 0, len(levels))
+	for name, sev := range levels {
+		out = append(out, loggerLevel{name, sev.SlogLevel()})
+	}
+	slices.SortFunc(out, func(a, b loggerLevel) int {
+		return cmp.Or(cmp.Compare(len(b.name), len(a.name)), cmp.Compare(a.name, b.name))
+	})
+	return out
+}
+
+// levelFor returns the override for logger: the entry naming it or its
+// nearest dotted ancestor.
+func levelFor(levels []loggerLevel, logger string) (slog.Level, bool) {
+	if logger == "" {
+		return 0, false
+	}
+	for _, l := range levels {
+		if logger == l.name || strings.HasPrefix(logger, l.name+".") {
+			return l.min, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoggerLevels(t *testing.T) {
+	buf := captureStderr(t)
+	cfg := DefaultConfig()
+	cfg.Service = "svc"
+	cfg.LoggerLevels = map[string]Severity{"app.db": SeverityDebug, "app.http": SeverityError, "app.http.admin": SeverityInfo}
+	cfg.Sinks = []SinkConfig{{Type: SinkConsole}, {Type: SinkConsole, Level: SeverityWarn, Format: FormatText}}
+	log := slog.New(newTestHandler(t, cfg))
+
+	log.Debug("pool grown", "logger", "app.db.pool")
+	log.Debug("dropped", "logger", "app.dbx")
+	log.Warn("dropped", "logger", "app.http.router")
+	log.Info("login", "logger", "app.http.admin")
+	log.Info("started")
+
+	var got []string
+	for _, ev := range events(t, []byte(jsonLines(buf.String()))) {
+		got = append(got, ev["message"].(string))
+	}
+	if strings.Join(got, ",") != "pool grown,login,started" {
+		t.Errorf("json sink got %q", got)
+	}
+	if strings.Contains(buf.String(), " DEBUG ") || strings.Contains(buf.String(), " INFO ") {
+		t.Errorf("text sink ignored its own level: %q", buf.String())
+	}
+
+	for _, name := range []string{"app..db", "1app", "app.db-pool"} {
+		cfg.LoggerLevels = map[string]Severity{name: SeverityDebug}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("logger name %q: expected an error", name)
+		}
+	}
+}
+
+// jsonLines drops the text-format lines from mixed console output.
+func jsonLines(s string) string {
+	var b strings.Builder
+	for line := range strings.Lines(s) {
+		if strings.HasPrefix(line, "{") {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+func TestThrottle(t *testing.T) {
+	buf := captureStderr(t)
+	cfg := DefaultConfig()
+	cfg.Service = "svc"
+	cfg.Throttling = &ThrottlingConfig{
+		Enabled: true, MaxRate: 1, BurstSize: 2, WindowSize: 3600,
+		SeverityFilter: &SeverityFilter{Operator: OpLT, Level: SeverityError},
+	}
+	h := newTestHandler(t, cfg)
+	now, advance := fakeClock()
+	h.core.throttle.now = now
+	log := slog.New(h).With("logger", "app.worker")
+
+	for range 5 {
+		log.Info("retrying")
+		log.Error("disk full")
+	}
+	log.Info("other message")
+	advance(time.Second)
+	log.Info("retrying")
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	count := map[string]int{}
+	var summary map[string]any
+	for _, ev := range events(t, buf.Bytes()) {
+		count[ev["message"].(string)]++
+		if ev["message"] == "3 messages suppressed" {
+			summary = ev
+		}
+	}
+	if count["retrying"] != 3 || count["disk full"] != 5 || count["other message"] != 1 {
+		t.Errorf("event counts = %v", count)
+	}
+	if summary == nil || summary["severity"] != "WARN" || summary["logger"] != "app.worker" {
+		t.Fatalf("summary = %v", summary)
+	}
+	if ctx := summary["context"].(map[string]any); ctx["suppressedMessage"] != "retrying" || ctx["suppressed"] != 3.0 {
+		t.Errorf("summary context = %v", ctx)
+	}
+
+	cfg.Throttling = &ThrottlingConfig{Enabled: true}
+	if err := cfg.Validate(); err == nil {
+		t.Error("enabled throttling without maxRate: expected an error")
+	}
+}
+
+func TestSeverityFilter(t *testing.T) {
+	for _, tc := range []struct {
+		op   Operator
+		sev  Severity
+		want bool
+	}{
+		{OpGE, SeverityWarn, true}, {OpGE, SeverityInfo, false}, {OpGT, SeverityWarn, false},
+		{OpLT, SeverityInfo, true}, {OpLE, SeverityWarn, true}, {OpEQ, SeverityWarn, true},
+		{OpNE, SeverityWarn, false}, {OpEQ, "LOUD", false}, {"ABOUT", SeverityWarn, false},
+	} {
+		if got := (SeverityFilter{Operator: tc.op, Level: SeverityWarn}).Matches(tc.sev); got != tc.want {
+			t.Errorf("%s WARN matches %s = %v, want %v", tc.op, tc.sev, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,520 @@
+package logging
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits from log-event.schema.json and definitions.schema.json.
+const (
+	maxMessageBytes = 32768
+	maxStackBytes   = 65536
+	maxContextKeys  = 100
+	maxTags         = 50
+)
+
+var (
+	componentPattern = serviceNamePattern
+	uuidV7Pattern    = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+)
+
+// Handler is an slog.Handler that renders records as LogEvent envelopes and
+// writes them to the configured sinks. Records first pass through the
+// configured middleware, in priority order.
+//
+// Top-level attributes whose keys name envelope fields (component, logger,
+// contextId, requestId, correlationId, traceId, spanId, parentSpanId, tags,
+// eventId, operation, durationMs, userId) are promoted to those fields when
+// their value is valid for the field; an error-valued "error" or "err"
+// attribute becomes the error object. Everything else, including attributes
+// inside groups, goes to context. StaticFields are redacted by the
+// middleware like attributes added with With, then added to every event.
+type Handler struct {
+	core *core
+	head slog.Handler // Middleware chain ending in an emitter
+}
+
+// emitter renders records and writes them to the sinks.
+type emitter struct {
+	core *core
+	goas []groupOrAttrs
+}
+
+// core is the state shared by a Handler, its emitters and With* derivatives.
+type core struct {
+	cfg      *LoggerConfig
+	outputs  []*output
+	min      slog.Level // lowest level any output or override accepts
+	levels   []loggerLevel
+	throttle *throttle
+	static   []groupOrAttrs // StaticFields, the first attributes of every emitter
+}
+
+// attrRecorder is a handler that only collects the attributes middleware
+// passes on from WithAttrs.
+type attrRecorder struct {
+	attrs []slog.Attr
+}
+
+func (h *attrRecorder) Enabled(context.Context, slog.Level) bool  { return false }
+func (h *attrRecorder) Handle(context.Context, slog.Record) error { return nil }
+func (h *attrRecorder) WithGroup(string) slog.Handler             { return h }
+
+func (h *attrRecorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h.attrs = append(h.attrs, attrs...)
+	return h
+}
+
+// groupOrAttrs is a WithGroup or WithAttrs step.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// NewHandler validates cfg and opens its sinks. With no sinks configured,
+// events go to stderr as JSON. Close the handler to release the sinks.
+func NewHandler(cfg *LoggerConfig) (*Handler, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	mws, err := NewPipeline(cfg.Middleware)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &core{cfg: cfg, min: LevelNone}
+	sinks := cfg.Sinks
+	if len(sinks) == 0 {
+		sinks = []SinkConfig{{Type: SinkConsole}}
+	}
+	for i, sc := range sinks {
+		out, err := newOutput(sc, cfg.DefaultLevel)
+		if err != nil {
+			c.close()
+			return nil, fmt.Errorf("sinks[%d]: %w", i, err)
+		}
+		c.outputs = append(c.outputs, out)
+		c.min = min(c.min, out.min)
+	}
+	c.levels = newLoggerLevels(cfg.LoggerLevels)
+	for _, l := range c.levels {
+		c.min = min(c.min, l.min)
+	}
+
+	if len(cfg.StaticFields) > 0 {
+		attrs := make([]slog.Attr, 0, len(cfg.StaticFields))
+		for k, v := range cfg.StaticFields {
+			attrs = append(attrs, slog.Any(k, v))
+		}
+		slices.SortFunc(attrs, func(a, b slog.Attr) int { return cmp.Compare(a.Key, b.Key) })
+		// Throttle summaries bypass the middleware, so the fields are
+		// redacted once here rather than on every event.
+		rec := &attrRecorder{}
+		Chain(rec, mws...).WithAttrs(attrs)
+		c.static = []groupOrAttrs{{attrs: rec.attrs}}
+	}
+	if c.throttle = newThrottle(cfg.Throttling, c.summarize); c.throttle != nil {
+		c.throttle.start()
+	}
+	return &Handler{core: c, head: Chain(&emitter{core: c, goas: c.static}, mws...)}, nil
+}
+
+// Close reports pending throttle summaries, then flushes and closes every
+// sink.
+func (h *Handler) Close() error {
+	return h.core.close()
+}
+
+// Sink returns the sink configured with name, for example a memory sink's
+// *RingBuffer to serve from a /debug endpoint.
+func (h *Handler) Sink(name string) (Sink, bool) {
+	for _, out := range h.core.outputs {
+		if name != "" && out.name == name {
+			return out.sink, true
+		}
+	}
+	return nil, false
+}
+
+func (c *core) close() error {
+	c.throttle.close()
+	var errs []error
+	for _, out := range c.outputs {
+		errs = append(errs, out.close())
+	}
+	return errors.Join(errs...)
+}
+
+// Enabled reports whether any sink or LoggerLevels entry accepts level and
+// the middleware does not rule it out.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.head.Enabled(ctx, level)
+}
+
+// Handle passes r through the middleware, then renders it and writes it to
+// every sink that accepts its level for its logger, subject to throttling.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	return h.head.Handle(ctx, r)
+}
+
+// WithAttrs returns a handler that adds attrs to every event.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return &Handler{core: h.core, head: h.head.WithAttrs(attrs)}
+}
+
+// WithGroup returns a handler that nests subsequent attributes under name in
+// the event context.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &Handler{core: h.core, head: h.head.WithGroup(name)}
+}
+
+func (e *emitter) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= e.core.min
+}
+
+func (e *emitter) Handle(_ context.Context, r slog.Record) error {
+	ev := e.event(r)
+	outs := e.core.targets(ev.Logger, r.Level)
+	if len(outs) == 0 || !e.core.throttle.allow(ev) {
+		return nil
+	}
+	return write(outs, ev)
+}
+
+// targets returns the outputs accepting an event from logger at level.
+func (c *core) targets(logger string, level slog.Level) []*output {
+	override, overridden := levelFor(c.levels, logger)
+	var outs []*output
+	for _, out := range c.outputs {
+		if out.accepts(level, override, overridden) {
+			outs = append(outs, out)
+		}
+	}
+	return outs
+}
+
+func write(outs []*output, ev *LogEvent) error {
+	var errs []error
+	for _, out := range outs {
+		errs = append(errs, out.write(ev))
+	}
+	return errors.Join(errs...)
+}
+
+// summarize writes a WARN event reporting that throttling dropped
+// suppressed events with message from logger.
+func (c *core) summarize(logger, message string, suppressed int) {
+	r := slog.NewRecord(time.Now(), slog.LevelWarn, fmt.Sprintf("%d messages suppressed", suppressed), 0)
+	if logger != "" {
+		r.AddAttrs(slog.String("logger", logger))
+	}
+	r.AddAttrs(slog.String("suppressedMessage", message), slog.Int("suppressed", suppressed))
+	ev := (&emitter{core: c, goas: c.static}).event(r)
+	write(c.targets(logger, r.Level), ev)
+}
+
+func (e *emitter) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return e
+	}
+	return e.with(groupOrAttrs{attrs: attrs})
+}
+
+func (e *emitter) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return e
+	}
+	return e.with(groupOrAttrs{group: name})
+}
+
+func (e *emitter) with(g groupOrAttrs) *emitter {
+	return &emitter{core: e.core, goas: append(slices.Clip(e.goas), g)}
+}
+
+// event renders r as a LogEvent.
+func (e *emitter) event(r slog.Record) *LogEvent {
+	cfg := e.core.cfg
+	ts := r.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	sev := SeverityOf(r.Level)
+	ev := &LogEvent{
+		Timestamp:     ts.UTC().Format(time.RFC3339Nano),
+		Severity:      sev,
+		SeverityLevel: sev.Level(),
+		Message:       truncate(r.Message, maxMessageBytes),
+		Service:       cfg.Service,
+		Environment:   cfg.Environment,
+		Context:       map[string]any{},
+	}
+	if ev.Message == "" {
+		ev.Message = "(no message)"
+	}
+
+	// Walk the With* steps, then the record's own attributes. Attributes
+	// are top level until the first group.
+	ctx := ev.Context
+	top := true
+	for _, g := range e.goas {
+		if g.group != "" {
+			sub := map[string]any{}
+			ctx[g.group] = sub
+			ctx, top = sub, false
+			continue
+		}
+		for _, a := range g.attrs {
+			addAttr(ev, ctx, a, top)
+		}
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(ev, ctx, a, top)
+		return true
+	})
+	pruneEmptyGroups(ev.Context)
+	limitContext(ev.Context)
+
+	if cfg.EnableCaller && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		if frame.File != "" {
+			ev.Caller = fmt.Sprintf("%s/%s:%d", filepath.Base(filepath.Dir(frame.File)), filepath.Base(frame.File), frame.Line)
+		}
+	}
+	if cfg.EnableStacktrace && r.Level >= slog.LevelError {
+		if ev.Error == nil {
+			ev.Error = &EventError{Message: ev.Message}
+		}
+		ev.Error.Stack = truncate(string(debug.Stack()), maxStackBytes)
+	}
+	return ev
+}
+
+// addAttr adds a to ctx, promoting it into ev first when top is set and the
+// key names an envelope field.
+func addAttr(ev *LogEvent, ctx map[string]any, a slog.Attr, top bool) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if top && promote(ev, a) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return
+		}
+		if a.Key == "" { // Inline an unnamed group
+			for _, ga := range attrs {
+				addAttr(ev, ctx, ga, top)
+			}
+			return
+		}
+		sub, _ := ctx[a.Key].(map[string]any)
+		if sub == nil {
+			sub = map[string]any{}
+			ctx[a.Key] = sub
+		}
+		for _, ga := range attrs {
+			addAttr(ev, sub, ga, false)
+		}
+		return
+	}
+	ctx[a.Key] = contextValue(a.Value)
+}
+
+// promote sets the envelope field named by a.Key and reports whether it did.
+// Values that would not validate against the event schema stay in context.
+func promote(ev *LogEvent, a slog.Attr) bool {
+	v := a.Value
+	str := func(maxLen int) (string, bool) {
+		if v.Kind() != slog.KindString {
+			return "", false
+		}
+		s := v.String()
+		return s, s != "" && utf8.RuneCountInString(s) <= maxLen
+	}
+	set := func(dst *string, maxLen int) bool {
+		s, ok := str(maxLen)
+		if ok {
+			*dst = s
+		}
+		return ok
+	}
+
+	switch a.Key {
+	case "component":
+		if s, ok := str(256); ok && componentPattern.MatchString(s) {
+			ev.Component = s
+			return true
+		}
+	case "logger":
+		return set(&ev.Logger, 256)
+	case "contextId":
+		return set(&ev.ContextID, 128)
+	case "requestId":
+		return set(&ev.RequestID, 128)
+	case "correlationId":
+		if s, ok := str(36); ok && uuidV7Pattern.MatchString(s) {
+			ev.CorrelationID = s
+			return true
+		}
+	case "traceId":
+		return set(&ev.TraceID, 128)
+	case "spanId":
+		return set(&ev.SpanID, 128)
+	case "parentSpanId":
+		return set(&ev.ParentSpanID, 128)
+	case "eventId":
+		return set(&ev.EventID, 128)
+	case "operation":
+		return set(&ev.Operation, 128)
+	case "userId":
+		return set(&ev.UserID, 128)
+	case "durationMs":
+		if ms, ok := durationMs(v); ok {
+			ev.DurationMs = &ms
+			return true
+		}
+	case "tags":
+		if tags, ok := v.Any().([]string); ok && validTags(tags) {
+			ev.Tags = tags
+			return true
+		}
+	case "error", "err":
+		if err, ok := v.Any().(error); ok && err != nil {
+			ev.Error = &EventError{
+				Message: truncate(nonEmpty(err.Error(), "error"), 8192),
+				Type:    truncate(errorType(err), 256),
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// errorType names the type of err, or of the error a redaction middleware
+// replaced.
+func errorType(err error) string {
+	if t, ok := err.(interface{ errorType() string }); ok {
+		return t.errorType()
+	}
+	return fmt.Sprintf("%T", err)
+}
+
+func durationMs(v slog.Value) (float64, bool) {
+	var ms float64
+	switch v.Kind() {
+	case slog.KindDuration:
+		ms = float64(v.Duration()) / float64(time.Millisecond)
+	case slog.KindInt64:
+		ms = float64(v.Int64())
+	case slog.KindUint64:
+		ms = float64(v.Uint64())
+	case slog.KindFloat64:
+		ms = v.Float64()
+	default:
+		return 0, false
+	}
+	return ms, ms >= 0
+}
+
+func validTags(tags []string) bool {
+	if len(tags) > maxTags {
+		return false
+	}
+	seen := map[string]bool{}
+	for _, t := range tags {
+		if t == "" || utf8.RuneCountInString(t) > 128 || seen[t] {
+			return false
+		}
+		seen[t] = true
+	}
+	return true
+}
+
+// contextValue converts an attribute value into a JSON-friendly value.
+func contextValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+	case slog.KindInt64:
+		return v.Int64()
+	case slog.KindUint64:
+		return v.Uint64()
+	case slog.KindFloat64:
+		return v.Float64()
+	case slog.KindBool:
+		return v.Bool()
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindTime:
+		return v.Time().UTC().Format(time.RFC3339Nano)
+	default:
+		if err, ok := v.Any().(error); ok {
+			return err.Error()
+		}
+		return v.Any()
+	}
+}
+
+func pruneEmptyGroups(m map[string]any) {
+	for k, v := range m {
+		if sub, ok := v.(map[string]any); ok {
+			pruneEmptyGroups(sub)
+			if len(sub) == 0 {
+				delete(m, k)
+			}
+		}
+	}
+}
+
+// limitContext drops keys beyond the schema's 100-field limit, keeping the
+// lexically first ones.
+func limitContext(m map[string]any) {
+	if len(m) <= maxContextKeys {
+		return
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys[maxContextKeys:] {
+		delete(m, k)
+	}
+}
+
+// truncate cuts s to at most n bytes without splitting a rune.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func nonEmpty(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,213 @@
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fulmenhq/crucible"
+	"gopkg.in/yaml.v3"
+)
+
+// captureStderr redirects console sinks into a buffer for the test.
+func captureStderr(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	old := stderr
+	stderr = &buf
+	t.Cleanup(func() { stderr = old })
+	return &buf
+}
+
+// events decodes newline-delimited JSON events, validating each line
+// against log-event.schema.json.
+func events(t *testing.T, data []byte) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		line := sc.Bytes()
+		if err := crucible.ValidateSchemaData(LogEventSchemaPath, line); err != nil {
+			t.Errorf("line does not match log-event schema: %v\n%s", err, line)
+		}
+		var ev map[string]any
+		if err := json.Unmarshal(line, &ev); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, ev)
+	}
+	return out
+}
+
+func newTestHandler(t *testing.T, cfg *LoggerConfig) *Handler {
+	t.Helper()
+	h, err := NewHandler(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { h.Close() })
+	return h
+}
+
+func TestHandlerEvents(t *testing.T) {
+	buf := captureStderr(t)
+	cfg := DefaultConfig()
+	cfg.Service = "crucible-test"
+	cfg.DefaultLevel = SeverityTrace
+	cfg.StaticFields = map[string]any{"region": "us-east-1", "component": "pathfinder"}
+	cfg.EnableCaller = true
+	cfg.EnableStacktrace = true
+	log := slog.New(newTestHandler(t, cfg))
+
+	log.Info("scan completed",
+		"logger", "crucible.pathfinder",
+		"requestId", "req-1",
+		"correlationId", "018db318-8c7f-7bc1-b3ff-b2e0e689a001",
+		"durationMs", 2450*time.Millisecond,
+		"tags", []string{"scan", "success"},
+		"filesScanned", 1234,
+	)
+	log.With("correlationId", "not-a-uuid").WithGroup("http").Warn("slow", "status", 200, "latency", time.Second)
+	log.Error("read failed", "error", os.ErrPermission, "path", "/restricted")
+	log.Log(context.Background(), LevelTrace, "")
+	log.Log(context.Background(), LevelFatal, "giving up", slog.Group("empty"))
+
+	evs := events(t, buf.Bytes())
+	if len(evs) != 5 {
+		t.Fatalf("got %d events, want 5", len(evs))
+	}
+
+	info := evs[0]
+	for k, want := range map[string]any{
+		"severity": "INFO", "severityLevel": 20.0, "service": "crucible-test", "environment": "development",
+		"component": "pathfinder", "logger": "crucible.pathfinder", "requestId": "req-1",
+		"correlationId": "018db318-8c7f-7bc1-b3ff-b2e0e689a001", "durationMs": 2450.0,
+	} {
+		if info[k] != want {
+			t.Errorf("info %s = %v, want %v", k, info[k], want)
+		}
+	}
+	ctx := info["context"].(map[string]any)
+	if ctx["region"] != "us-east-1" || ctx["filesScanned"] != 1234.0 || len(ctx) != 2 {
+		t.Errorf("info context = %v", ctx)
+	}
+	if caller, _ := info["caller"].(string); !strings.HasPrefix(caller, "logging/handler_test.go:") {
+		t.Errorf("caller = %q", caller)
+	}
+
+	warn := evs[1]
+	if _, ok := warn["correlationId"]; ok {
+		t.Errorf("invalid correlationId promoted: %v", warn)
+	}
+	wctx := warn["context"].(map[string]any)
+	if wctx["correlationId"] != "not-a-uuid" || wctx["http"].(map[string]any)["latency"] != "1s" {
+		t.Errorf("warn context = %v", wctx)
+	}
+
+	errEv := evs[2]["error"].(map[string]any)
+	if errEv["message"] != "permission denied" || errEv["stack"] == "" {
+		t.Errorf("error = %v", errEv)
+	}
+	if evs[3]["severity"] != "TRACE" || evs[3]["message"] != "(no message)" {
+		t.Errorf("trace event = %v", evs[3])
+	}
+	if evs[4]["severity"] != "FATAL" || evs[4]["error"] == nil {
+		t.Errorf("fatal event = %v", evs[4])
+	}
+}
+
+func TestHandlerSinkLevels(t *testing.T) {
+	buf := captureStderr(t)
+	path := filepath.Join(t.TempDir(), "logs", "svc.log")
+	cfg := DefaultConfig()
+	cfg.Service = "svc"
+	cfg.Profile = ProfileStructured
+	cfg.DefaultLevel = SeverityDebug
+	cfg.Sinks = []SinkConfig{
+		{Type: SinkConsole, Level: SeverityWarn, Format: FormatText},
+		{Type: SinkFile, Path: path},
+		{Type: SinkConsole, Level: SeverityNone},
+	}
+	h := newTestHandler(t, cfg)
+	log := slog.New(h)
+
+	if h.Enabled(context.Background(), LevelTrace) {
+		t.Error("TRACE enabled below every sink level")
+	}
+	log.Debug("debug only in file")
+	log.Warn("warn everywhere", "k", "v w")
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	text := buf.String()
+	if strings.Contains(text, "debug only") || !strings.Contains(text, ` WARN warn everywhere service=svc environment=development k="v w"`) {
+		t.Errorf("console output = %q", text)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if evs := events(t, data); len(evs) != 2 {
+		t.Errorf("file sink got %d events, want 2", len(evs))
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig([]byte("profile: STRUCTURED\nservice: api-gateway\nsinks:\n  - type: file\n    path: logs/gateway.log\n    maxSize: 10\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Environment != "development" || cfg.DefaultLevel != SeverityInfo || cfg.Sinks[0].MaxSize != 10 {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+
+	data, err := os.ReadFile(filepath.Join("..", "..", "examples", "logging", "profiles.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var profiles map[string]any
+	if err := yaml.Unmarshal(data, &profiles); err != nil {
+		t.Fatal(err)
+	}
+	for name, profile := range profiles {
+		doc, _ := yaml.Marshal(profile)
+		if _, err := LoadConfig(doc); err != nil {
+			t.Errorf("examples/logging/profiles.yaml %s: %v", name, err)
+		}
+	}
+
+	for name, doc := range map[string]string{
+		"missing service":     "profile: SIMPLE\n",
+		"structured no sinks": "profile: STRUCTURED\nservice: api\n",
+		"bad level":           "service: api\ndefaultLevel: LOUD\n",
+		"unknown sink field":  "service: api\nsinks:\n  - type: console\n    path: x.log\n",
+		"bad service name":    "service: My Service\n",
+	} {
+		if _, err := LoadConfig([]byte(doc)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	for _, s := range []Severity{SeverityTrace, SeverityDebug, SeverityInfo, SeverityWarn, SeverityError, SeverityFatal} {
+		if got := SeverityOf(s.SlogLevel()); got != s {
+			t.Errorf("SeverityOf(%s.SlogLevel()) = %s", s, got)
+		}
+	}
+	if SeverityOf(slog.LevelInfo+2) != SeverityInfo || SeverityNone.Level() != 60 || Severity("LOUD").Level() != -1 {
+		t.Error("unexpected severity mapping")
+	}
+	if _, err := ParseSeverity("warn"); err == nil {
+		t.Error("severity names are case-sensitive")
+	}
+}
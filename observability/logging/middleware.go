@@ -0,0 +1,296 @@
+package logging
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Middleware wraps an slog.Handler to process records before they reach it.
+type Middleware func(next slog.Handler) slog.Handler
+
+// Chain wraps h in mws so that records pass through mws[0] first.
+func Chain(h slog.Handler, mws ...Middleware) slog.Handler {
+	for _, mw := range slices.Backward(mws) {
+		h = mw(h)
+	}
+	return h
+}
+
+// NewPipeline builds the enabled entries of cfgs, ordered by priority
+// (lower first, ties in configuration order).
+func NewPipeline(cfgs []MiddlewareConfig) ([]Middleware, error) {
+	order := make([]int, 0, len(cfgs))
+	for i, mc := range cfgs {
+		if mc.Enabled == nil || *mc.Enabled {
+			order = append(order, i)
+		}
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(cfgs[a].Priority, cfgs[b].Priority) })
+
+	mws := make([]Middleware, 0, len(order))
+	for _, i := range order {
+		mw, err := NewMiddleware(cfgs[i])
+		if err != nil {
+			return nil, fmt.Errorf("middleware[%d]: %w", i, err)
+		}
+		mws = append(mws, mw)
+	}
+	return mws, nil
+}
+
+// NewMiddleware builds the middleware mc configures, ignoring Enabled and
+// Priority. Custom middleware has no configuration-driven implementation;
+// wrap the handler with Chain instead.
+func NewMiddleware(mc MiddlewareConfig) (Middleware, error) {
+	switch mc.Type {
+	case MiddlewareRedaction:
+		r, err := newRedactor(cmp.Or(mc.Redaction, &RedactionConfig{}))
+		if err != nil {
+			return nil, err
+		}
+		return func(next slog.Handler) slog.Handler { return &redactHandler{next: next, r: r} }, nil
+	case MiddlewareFilter:
+		f := newEventFilter(cmp.Or(mc.Filter, &FilterConfig{}))
+		return func(next slog.Handler) slog.Handler { return &filterHandler{next: next, f: f} }, nil
+	case MiddlewareAugmentation:
+		return newAugmentation(cmp.Or(mc.Augmentation, &AugmentationConfig{}))
+	case MiddlewareSampling:
+		return newSampling(cmp.Or(mc.Sampling, &SamplingConfig{}))
+	default:
+		return nil, fmt.Errorf("%s middleware cannot be built from configuration", mc.Type)
+	}
+}
+
+// eventFilter is a compiled FilterConfig.
+type eventFilter struct {
+	min        *slog.Level
+	severity   *SeverityFilter
+	loggers    []string
+	components []string
+}
+
+func newEventFilter(fc *FilterConfig) *eventFilter {
+	f := &eventFilter{severity: fc.SeverityFilter}
+	if fc.MinLevel != "" {
+		min := fc.MinLevel.SlogLevel()
+		f.min = &min
+	}
+	if fc.Exclude != nil {
+		f.loggers, f.components = fc.Exclude.Loggers, fc.Exclude.Components
+	}
+	return f
+}
+
+// level reports whether events at l pass the severity rules.
+func (f *eventFilter) level(l slog.Level) bool {
+	if f.min != nil && l < *f.min {
+		return false
+	}
+	return f.severity == nil || f.severity.Matches(SeverityOf(l))
+}
+
+// excluded reports whether logger, or an ancestor of it, or component is
+// excluded.
+func (f *eventFilter) excluded(logger, component string) bool {
+	for _, name := range f.loggers {
+		if logger != "" && (logger == name || strings.HasPrefix(logger, name+".")) {
+			return true
+		}
+	}
+	return component != "" && slices.Contains(f.components, component)
+}
+
+// filterHandler keeps the events that pass its filter. The logger and
+// component are read from top-level attributes, as the Handler promotes
+// them.
+type filterHandler struct {
+	next      slog.Handler
+	f         *eventFilter
+	logger    string
+	component string
+	grouped   bool
+}
+
+func (h *filterHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.f.level(l) && h.next.Enabled(ctx, l)
+}
+
+func (h *filterHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.f.level(r.Level) {
+		return nil
+	}
+	logger, component := h.logger, h.component
+	if !h.grouped {
+		r.Attrs(func(a slog.Attr) bool {
+			logger, component = identity(a, logger, component)
+			return true
+		})
+	}
+	if h.f.excluded(logger, component) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *filterHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	if !h.grouped {
+		for _, a := range attrs {
+			h2.logger, h2.component = identity(a, h2.logger, h2.component)
+		}
+	}
+	return &h2
+}
+
+func (h *filterHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	h2.grouped = h.grouped || name != ""
+	return &h2
+}
+
+// identity updates logger or component from a top-level attribute.
+func identity(a slog.Attr, logger, component string) (string, string) {
+	if a.Value.Kind() != slog.KindString {
+		return logger, component
+	}
+	switch a.Key {
+	case "logger":
+		logger = a.Value.String()
+	case "component":
+		component = a.Value.String()
+	}
+	return logger, component
+}
+
+// FieldProvider computes attributes for each event; augmentation middleware
+// calls the providers named in its dynamic list.
+type FieldProvider func(ctx context.Context) []slog.Attr
+
+var fieldProviders = struct {
+	sync.RWMutex
+	m map[string]FieldProvider
+}{m: map[string]FieldProvider{}}
+
+// RegisterFieldProvider makes p available to augmentation middleware as
+// name. Register providers before building handlers that use them.
+func RegisterFieldProvider(name string, p FieldProvider) {
+	fieldProviders.Lock()
+	defer fieldProviders.Unlock()
+	fieldProviders.m[name] = p
+}
+
+// newAugmentation adds ac.Fields to every event as top-level attributes and
+// the dynamic providers' attributes alongside the record's own.
+func newAugmentation(ac *AugmentationConfig) (Middleware, error) {
+	attrs := make([]slog.Attr, 0, len(ac.Fields))
+	for _, k := range slices.Sorted(maps.Keys(ac.Fields)) {
+		attrs = append(attrs, slog.Any(k, ac.Fields[k]))
+	}
+	providers := make([]FieldProvider, 0, len(ac.Dynamic))
+	fieldProviders.RLock()
+	defer fieldProviders.RUnlock()
+	for _, name := range ac.Dynamic {
+		p, ok := fieldProviders.m[name]
+		if !ok {
+			return nil, fmt.Errorf("field provider %q is not registered", name)
+		}
+		providers = append(providers, p)
+	}
+
+	return func(next slog.Handler) slog.Handler {
+		if len(attrs) > 0 {
+			next = next.WithAttrs(attrs)
+		}
+		if len(providers) == 0 {
+			return next
+		}
+		return &augmentHandler{next: next, providers: providers}
+	}, nil
+}
+
+type augmentHandler struct {
+	next      slog.Handler
+	providers []FieldProvider
+}
+
+func (h *augmentHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.next.Enabled(ctx, l)
+}
+
+func (h *augmentHandler) Handle(ctx context.Context, r slog.Record) error {
+	r = r.Clone()
+	for _, p := range h.providers {
+		r.AddAttrs(p(ctx)...)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *augmentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &augmentHandler{next: h.next.WithAttrs(attrs), providers: h.providers}
+}
+
+func (h *augmentHandler) WithGroup(name string) slog.Handler {
+	return &augmentHandler{next: h.next.WithGroup(name), providers: h.providers}
+}
+
+// sampleFloat draws the number compared with a sampling rate; tests
+// replace it.
+var sampleFloat = rand.Float64
+
+// newSampling keeps each event with the probability set for its severity in
+// ByLevel, or Rate, or 1.
+func newSampling(sc *SamplingConfig) (Middleware, error) {
+	rate := 1.0
+	if sc.Rate != nil {
+		rate = *sc.Rate
+	}
+	byLevel := map[Severity]float64{}
+	for name, r := range sc.ByLevel {
+		sev, err := ParseSeverity(name)
+		if err != nil {
+			return nil, fmt.Errorf("sampling byLevel: %w", err)
+		}
+		byLevel[sev] = r
+	}
+	return func(next slog.Handler) slog.Handler {
+		return &sampleHandler{next: next, rate: rate, byLevel: byLevel}
+	}, nil
+}
+
+type sampleHandler struct {
+	next    slog.Handler
+	rate    float64
+	byLevel map[Severity]float64
+}
+
+func (h *sampleHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.next.Enabled(ctx, l)
+}
+
+func (h *sampleHandler) Handle(ctx context.Context, r slog.Record) error {
+	rate, ok := h.byLevel[SeverityOf(r.Level)]
+	if !ok {
+		rate = h.rate
+	}
+	if rate < 1 && sampleFloat() >= rate {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *sampleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &sampleHandler{next: h.next.WithAttrs(attrs), rate: h.rate, byLevel: h.byLevel}
+}
+
+func (h *sampleHandler) WithGroup(name string) slog.Handler {
+	return &sampleHandler{next: h.next.WithGroup(name), rate: h.rate, byLevel: h.byLevel}
+}
@@ -0,0 +1,247 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// defaultReplacement is the redactionConfig replacement default.
+const defaultReplacement = "[REDACTED]"
+
+// redactionPresets maps RedactionConfig presets to Foundry pattern ids and
+// an optional check a match must pass.
+var redactionPresets = map[string]struct {
+	id    string
+	check func(string) bool
+}{
+	"email":        {id: "ansi-email"},
+	"credit-card":  {id: "credit-card", check: luhn},
+	"bearer-token": {id: "bearer-token"},
+}
+
+type redactRule struct {
+	re    *regexp.Regexp
+	check func(string) bool
+}
+
+// redactor is a compiled RedactionConfig.
+type redactor struct {
+	rules       []redactRule
+	fields      map[string]bool // Lowercased field names
+	replacement string
+}
+
+func newRedactor(rc *RedactionConfig) (*redactor, error) {
+	r := &redactor{fields: map[string]bool{}, replacement: rc.Replacement}
+	if r.replacement == "" {
+		r.replacement = defaultReplacement
+	}
+	for _, name := range rc.Presets {
+		preset, ok := redactionPresets[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown redaction preset %q", name)
+		}
+		re, err := foundryPattern(preset.id)
+		if err != nil {
+			return nil, err
+		}
+		// Catalog patterns validate whole values; find them inside text.
+		re, err = regexp.Compile(unanchored(re.String()))
+		if err != nil {
+			return nil, fmt.Errorf("redaction preset %s: %w", name, err)
+		}
+		r.rules = append(r.rules, redactRule{re, preset.check})
+	}
+	for _, p := range rc.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", p, err)
+		}
+		r.rules = append(r.rules, redactRule{re: re})
+	}
+	for _, f := range rc.Fields {
+		r.fields[strings.ToLower(f)] = true
+	}
+	return r, nil
+}
+
+// unanchored strips the ^ and $ anchors of a whole-value pattern, keeping
+// a leading flag group such as (?i).
+func unanchored(expr string) string {
+	flags := ""
+	if strings.HasPrefix(expr, "(?") {
+		if end := strings.Index(expr, ")"); end > 0 && !strings.Contains(expr[:end], ":") {
+			flags, expr = expr[:end+1], expr[end+1:]
+		}
+	}
+	expr = strings.TrimPrefix(expr, "^")
+	if strings.HasSuffix(expr, "$") && !strings.HasSuffix(expr, `\$`) {
+		expr = expr[:len(expr)-1]
+	}
+	return flags + expr
+}
+
+// luhn reports whether the digits in s pass the Luhn checksum.
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func (r *redactor) field(name string) bool {
+	return r.fields[strings.ToLower(name)]
+}
+
+// text replaces every rule match in s.
+func (r *redactor) text(s string) string {
+	for _, rule := range r.rules {
+		s = rule.re.ReplaceAllStringFunc(s, func(m string) string {
+			if rule.check != nil && !rule.check(m) {
+				return m
+			}
+			return r.replacement
+		})
+	}
+	return s
+}
+
+// attr redacts a. Every value inside a group named by a redacted field, or
+// under masked, is replaced.
+func (r *redactor) attr(a slog.Attr, masked bool) slog.Attr {
+	a.Value = a.Value.Resolve()
+	v := a.Value
+	if v.Kind() == slog.KindGroup {
+		masked = masked || (a.Key != "" && r.field(a.Key))
+		group := v.Group()
+		out := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			out[i] = r.attr(ga, masked)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(out...)}
+	}
+	if masked || r.field(a.Key) {
+		return slog.String(a.Key, r.replacement)
+	}
+
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, r.text(v.String()))
+	case slog.KindInt64, slog.KindUint64:
+		// Card numbers are sometimes logged as integers.
+		if s := v.String(); r.text(s) != s {
+			return slog.String(a.Key, r.text(s))
+		}
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case error:
+			return slog.Any(a.Key, redactedError{msg: r.text(x.Error()), typ: fmt.Sprintf("%T", x)})
+		case []string:
+			out := make([]string, len(x))
+			for i, s := range x {
+				out[i] = r.text(s)
+			}
+			return slog.Any(a.Key, out)
+		default:
+			return slog.Any(a.Key, r.value(generic(x)))
+		}
+	}
+	return a
+}
+
+// value redacts a JSON-shaped value.
+func (r *redactor) value(v any) any {
+	switch x := v.(type) {
+	case string:
+		return r.text(x)
+	case map[string]any:
+		for k, sub := range x {
+			if r.field(k) {
+				x[k] = r.replacement
+			} else {
+				x[k] = r.value(sub)
+			}
+		}
+	case []any:
+		for i, sub := range x {
+			x[i] = r.value(sub)
+		}
+	case json.Number:
+		if s := x.String(); r.text(s) != s {
+			return r.text(s)
+		}
+	}
+	return v
+}
+
+// generic converts v to its JSON shape so nested fields can be redacted;
+// values that do not marshal are formatted with fmt.
+func generic(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return fmt.Sprint(v)
+	}
+	return out
+}
+
+// redactedError carries a redacted error message while reporting the
+// original error type.
+type redactedError struct{ msg, typ string }
+
+func (e redactedError) Error() string     { return e.msg }
+func (e redactedError) errorType() string { return e.typ }
+
+// redactHandler redacts messages and attributes before passing records on.
+type redactHandler struct {
+	next   slog.Handler
+	r      *redactor
+	masked bool // Inside a group named by a redacted field
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.next.Enabled(ctx, l)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, h.r.text(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.r.attr(a, h.masked))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		out[i] = h.r.attr(a, h.masked)
+	}
+	return &redactHandler{next: h.next.WithAttrs(out), r: h.r, masked: h.masked}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name), r: h.r, masked: h.masked || h.r.field(name)}
+}
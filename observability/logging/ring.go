@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"io"
+	"sync"
+)
+
+// defaultRingCapacity is the memory sink capacity default from
+// logger-config.schema.json.
+const defaultRingCapacity = 1000
+
+// RingBuffer is a Sink that keeps the most recent encoded events in memory,
+// for tests and /debug endpoints. It is safe for concurrent use; obtain a
+// configured one with Handler.Sink. The zero value holds the schema default
+// of 1000 events.
+type RingBuffer struct {
+	mu    sync.Mutex
+	lines [][]byte
+	start int // Index of the oldest line once the buffer is full
+}
+
+// NewRingBuffer returns a buffer holding up to capacity events, or the
+// schema default of 1000 when capacity is not positive.
+func NewRingBuffer(capacity int) *RingBuffer {
+	if capacity <= 0 {
+		capacity = defaultRingCapacity
+	}
+	return &RingBuffer{lines: make([][]byte, 0, capacity)}
+}
+
+// Write stores a copy of p, evicting the oldest event when full.
+func (b *RingBuffer) Write(p []byte) (int, error) {
+	line := append([]byte(nil), p...)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.lines == nil {
+		b.lines = make([][]byte, 0, defaultRingCapacity)
+	}
+	if len(b.lines) < cap(b.lines) {
+		b.lines = append(b.lines, line)
+	} else {
+		b.lines[b.start] = line
+		b.start = (b.start + 1) % len(b.lines)
+	}
+	return len(p), nil
+}
+
+// Lines returns the retained events, oldest first, each with its trailing
+// newline.
+func (b *RingBuffer) Lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]string, 0, len(b.lines))
+	for i := range b.lines {
+		out = append(out, string(b.lines[(b.start+i)%len(b.lines)]))
+	}
+	return out
+}
+
+// WriteTo writes the retained events to w, oldest first.
+func (b *RingBuffer) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for _, line := range b.Lines() {
+		n, err := io.WriteString(w, line)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// Reset discards the retained events.
+func (b *RingBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lines = b.lines[:0]
+	b.start = 0
+}
+
+// Close is a no-op; the events remain readable.
+func (b *RingBuffer) Close() error { return nil }
@@ -0,0 +1,221 @@
+package logging
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat stamps rotated segments: app.log becomes
+// app-2026-01-02T15-04-05.000.log, or app-....log.gz once compressed.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// rotatingFile is the file and rolling-file sink. It appends to path and,
+// when the active segment would exceed maxSize or the rotation interval
+// elapses, renames it to a timestamped backup and starts a new one. After
+// each rotation, backups beyond maxBackups or older than maxAge are removed
+// and the remainder gzipped when compress is set; that runs in the
+// background and Close waits for it. A zero limit disables that limit.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	compress   bool
+	interval   time.Duration
+	now        func() time.Time
+
+	file *os.File
+	size int64
+	next time.Time // Next interval rotation; zero without an interval
+
+	mill sync.Mutex // Serializes retention passes
+	wg   sync.WaitGroup
+}
+
+func newRotatingFile(sc SinkConfig) (*rotatingFile, error) {
+	r := &rotatingFile{
+		path:       sc.Path,
+		maxSize:    int64(sc.MaxSize) << 20,
+		maxAge:     time.Duration(sc.MaxAge) * 24 * time.Hour,
+		maxBackups: sc.MaxBackups,
+		compress:   sc.Compress,
+		interval:   time.Duration(sc.RotationInterval) * time.Minute,
+		now:        time.Now,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the active segment. An existing segment last written before
+// the current interval began is rotated first.
+func (r *rotatingFile) open() error {
+	f, err := openLogFile(r.path)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.file, r.size = f, info.Size()
+	if r.interval > 0 {
+		now := r.now()
+		start := now.Truncate(r.interval)
+		r.next = start.Add(r.interval)
+		if r.size > 0 && info.ModTime().Before(start) {
+			return r.rotate()
+		}
+	}
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	due := r.interval > 0 && !r.now().Before(r.next)
+	full := r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize
+	if due || full {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate moves the active segment aside and opens a fresh one.
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	r.file = nil
+	if err := os.Rename(r.path, r.backupName(r.now())); err != nil {
+		return fmt.Errorf("failed to rotate %s: %w", r.path, err)
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+	now := r.now()
+	r.wg.Go(func() { r.retain(now) })
+	return nil
+}
+
+// backupName returns an unused backup path stamped with t.
+func (r *rotatingFile) backupName(t time.Time) string {
+	dir, prefix, ext := r.parts()
+	for {
+		name := filepath.Join(dir, prefix+t.UTC().Format(backupTimeFormat)+ext)
+		_, err := os.Lstat(name)
+		_, errGz := os.Lstat(name + ".gz")
+		if errors.Is(err, os.ErrNotExist) && errors.Is(errGz, os.ErrNotExist) {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+// parts splits path into its directory, the backup name prefix ("app-")
+// and the extension (".log").
+func (r *rotatingFile) parts() (dir, prefix, ext string) {
+	dir, base := filepath.Split(r.path)
+	ext = filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+type backup struct {
+	path string
+	when time.Time
+}
+
+// backups lists rotated segments, newest first.
+func (r *rotatingFile) backups() ([]backup, error) {
+	dir, prefix, ext := r.parts()
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return nil, err
+	}
+	var out []backup
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp, ok := strings.CutSuffix(strings.TrimSuffix(name, ".gz"), ext)
+		if !ok {
+			continue
+		}
+		t, err := time.Parse(backupTimeFormat, stamp[len(prefix):])
+		if err != nil {
+			continue
+		}
+		out = append(out, backup{filepath.Join(dir, name), t})
+	}
+	slices.SortFunc(out, func(a, b backup) int { return b.when.Compare(a.when) })
+	return out, nil
+}
+
+// retain applies maxBackups and maxAge as of now, then compresses what is
+// left. Failures are dropped: retention must never fail a log write.
+func (r *rotatingFile) retain(now time.Time) {
+	r.mill.Lock()
+	defer r.mill.Unlock()
+
+	backups, err := r.backups()
+	if err != nil {
+		return
+	}
+	cutoff := now.Add(-r.maxAge)
+	for i, b := range backups {
+		if (r.maxBackups > 0 && i >= r.maxBackups) || (r.maxAge > 0 && b.when.Before(cutoff)) {
+			os.Remove(b.path)
+			continue
+		}
+		if r.compress && !strings.HasSuffix(b.path, ".gz") {
+			gzipFile(b.path)
+		}
+	}
+}
+
+// gzipFile replaces path with path.gz.
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	err = errors.Join(err, zw.Close(), dst.Close())
+	if err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+	return os.Remove(path)
+}
+
+func (r *rotatingFile) Close() error {
+	var err error
+	if r.file != nil {
+		err = r.file.Close()
+		r.file = nil
+	}
+	r.wg.Wait()
+	return err
+}
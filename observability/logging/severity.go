@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+)
+
+// Severity is a log severity name.
+// See: schemas/observability/logging/v1.0.0/definitions.schema.json#/$defs/severityName
+type Severity string
+
+const (
+	SeverityTrace Severity = "TRACE"
+	SeverityDebug Severity = "DEBUG"
+	SeverityInfo  Severity = "INFO"
+	SeverityWarn  Severity = "WARN"
+	SeverityError Severity = "ERROR"
+	SeverityFatal Severity = "FATAL"
+	SeverityNone  Severity = "NONE"
+)
+
+// slog levels for the severities slog does not define.
+const (
+	LevelTrace slog.Level = -8
+	LevelFatal slog.Level = 12
+	LevelNone  slog.Level = math.MaxInt32 // Above every emitted level
+)
+
+var severityLevels = map[Severity]int{
+	SeverityTrace: 0,
+	SeverityDebug: 10,
+	SeverityInfo:  20,
+	SeverityWarn:  30,
+	SeverityError: 40,
+	SeverityFatal: 50,
+	SeverityNone:  60,
+}
+
+// ParseSeverity validates a severity name.
+func ParseSeverity(s string) (Severity, error) {
+	if _, ok := severityLevels[Severity(s)]; !ok {
+		return "", fmt.Errorf("unknown severity %q", s)
+	}
+	return Severity(s), nil
+}
+
+// Level returns the numeric severity level (TRACE=0 … NONE=60), or -1 for an
+// unknown name.
+func (s Severity) Level() int {
+	if n, ok := severityLevels[s]; ok {
+		return n
+	}
+	return -1
+}
+
+// SlogLevel returns the slog level for s. NONE maps to LevelNone, which no
+// record reaches.
+func (s Severity) SlogLevel() slog.Level {
+	switch s {
+	case SeverityTrace:
+		return LevelTrace
+	case SeverityDebug:
+		return slog.LevelDebug
+	case SeverityWarn:
+		return slog.LevelWarn
+	case SeverityError:
+		return slog.LevelError
+	case SeverityFatal:
+		return LevelFatal
+	case SeverityNone:
+		return LevelNone
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// SeverityOf maps an slog level onto the severity whose range contains it;
+// for example slog.LevelInfo+2 is INFO.
+func SeverityOf(l slog.Level) Severity {
+	switch {
+	case l < slog.LevelDebug:
+		return SeverityTrace
+	case l < slog.LevelInfo:
+		return SeverityDebug
+	case l < slog.LevelWarn:
+		return SeverityInfo
+	case l < slog.LevelError:
+		return SeverityWarn
+	case l < LevelFatal:
+		return SeverityError
+	default:
+		return SeverityFatal
+	}
+}
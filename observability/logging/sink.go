@@ -0,0 +1,252 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fulmenhq/crucible"
+)
+
+// Sink is the destination of encoded log events. Each Write receives one
+// complete, newline-terminated event.
+type Sink interface {
+	io.Writer
+	Close() error
+}
+
+// stderr is the console sink destination; tests replace it.
+var stderr io.Writer = os.Stderr
+
+// consoleColor reports whether console sinks may emit ANSI colors: stderr
+// is a terminal the catalog marks color-capable and NO_COLOR is unset.
+// Tests replace it.
+var consoleColor = func() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := stderr.(*os.File)
+	if !ok {
+		return false
+	}
+	if info, err := f.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	term, err := crucible.DetectTerminal()
+	return err == nil && term.Capabilities.Color
+}
+
+// output pairs a sink with its level and encoding.
+type output struct {
+	name     string
+	min      slog.Level
+	explicit bool // min is the sink's own level rather than defaultLevel
+	format   Format
+	color    bool // ANSI severity colors; console format only
+	mu       sync.Mutex
+	sink     Sink
+}
+
+func newOutput(sc SinkConfig, defaultLevel Severity) (*output, error) {
+	level := sc.Level
+	if level == "" {
+		level = defaultLevel
+	}
+	format := sc.Format
+	if format == "" {
+		format = FormatJSON
+	}
+
+	var sink Sink
+	color := false
+	switch sc.Type {
+	case SinkConsole:
+		sink = nopCloser{stderr}
+		color = sc.Colorize && format == FormatConsole && consoleColor()
+	case SinkFile, SinkRollingFile:
+		f, err := newRotatingFile(sc)
+		if err != nil {
+			return nil, err
+		}
+		sink = f
+	case SinkMemory:
+		sink = NewRingBuffer(sc.Capacity)
+	default:
+		return nil, fmt.Errorf("sink type %q is not supported", sc.Type)
+	}
+	return &output{name: sc.Name, min: level.SlogLevel(), explicit: sc.Level != "", format: format, color: color, sink: sink}, nil
+}
+
+// accepts reports whether the sink takes an event at level. A LoggerLevels
+// override replaces defaultLevel and also bounds sinks with their own level.
+func (o *output) accepts(level, override slog.Level, overridden bool) bool {
+	threshold := o.min
+	if overridden {
+		threshold = override
+		if o.explicit {
+			threshold = max(o.min, override)
+		}
+	}
+	return level >= threshold
+}
+
+func (o *output) write(ev *LogEvent) error {
+	data := encode(ev, o.format, o.color)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	_, err := o.sink.Write(data)
+	return err
+}
+
+func (o *output) close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.sink.Close()
+}
+
+// openLogFile opens path for appending, creating it and its directory.
+func openLogFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// encode renders ev as one line in format. JSON is newline-delimited; text
+// and console render "timestamp SEVERITY message key=value...", with color
+// adding ANSI colors to the severity.
+func encode(ev *LogEvent, format Format, color bool) []byte {
+	if format == FormatJSON {
+		return encodeJSON(ev)
+	}
+	return encodeText(ev, color)
+}
+
+// severityColors are the ANSI SGR sequences for console severities.
+var severityColors = map[Severity]string{
+	SeverityTrace: "\x1b[90m",   // Bright black
+	SeverityDebug: "\x1b[36m",   // Cyan
+	SeverityInfo:  "\x1b[32m",   // Green
+	SeverityWarn:  "\x1b[33m",   // Yellow
+	SeverityError: "\x1b[31m",   // Red
+	SeverityFatal: "\x1b[1;31m", // Bold red
+}
+
+const ansiReset = "\x1b[0m"
+
+func encodeJSON(ev *LogEvent) []byte {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		// A context value that cannot be marshaled is rendered with fmt.
+		safe := *ev
+		safe.Context = stringify(ev.Context)
+		data, _ = json.Marshal(&safe)
+	}
+	return append(data, '\n')
+}
+
+func stringify(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		if sub, ok := v.(map[string]any); ok {
+			out[k] = stringify(sub)
+			continue
+		}
+		if _, err := json.Marshal(v); err != nil {
+			v = fmt.Sprint(v)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func encodeText(ev *LogEvent, color bool) []byte {
+	var b bytes.Buffer
+	b.WriteString(ev.Timestamp)
+	b.WriteByte(' ')
+	if color {
+		b.WriteString(severityColors[ev.Severity])
+		b.WriteString(string(ev.Severity))
+		b.WriteString(ansiReset)
+	} else {
+		b.WriteString(string(ev.Severity))
+	}
+	b.WriteByte(' ')
+	b.WriteString(ev.Message)
+
+	field := func(k string, v any) {
+		b.WriteByte(' ')
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(textValue(v))
+	}
+	for _, f := range []struct {
+		k, v string
+	}{
+		{"service", ev.Service}, {"component", ev.Component}, {"logger", ev.Logger},
+		{"environment", ev.Environment}, {"contextId", ev.ContextID}, {"requestId", ev.RequestID},
+		{"correlationId", ev.CorrelationID}, {"traceId", ev.TraceID}, {"spanId", ev.SpanID},
+		{"parentSpanId", ev.ParentSpanID}, {"eventId", ev.EventID}, {"operation", ev.Operation},
+		{"userId", ev.UserID}, {"caller", ev.Caller},
+	} {
+		if f.v != "" {
+			field(f.k, f.v)
+		}
+	}
+	if ev.DurationMs != nil {
+		field("durationMs", *ev.DurationMs)
+	}
+	if len(ev.Tags) > 0 {
+		field("tags", strings.Join(ev.Tags, ","))
+	}
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			if sub, ok := m[k].(map[string]any); ok {
+				walk(prefix+k+".", sub)
+				continue
+			}
+			field(prefix+k, m[k])
+		}
+	}
+	walk("", ev.Context)
+	if ev.Error != nil {
+		field("error", ev.Error.Message)
+		if ev.Error.Stack != "" {
+			b.WriteString("\n")
+			b.WriteString(strings.TrimRight(ev.Error.Stack, "\n"))
+		}
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+// textValue formats v, quoting it when it contains spaces, quotes or '='.
+func textValue(v any) string {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		s = fmt.Sprint(v)
+	}
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
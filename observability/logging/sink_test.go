@@ -0,0 +1,176 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock returns a clock starting at a fixed time and a function that
+// advances it.
+func fakeClock() (func() time.Time, func(time.Duration)) {
+	t := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	return func() time.Time { return t }, func(d time.Duration) { t = t.Add(d) }
+}
+
+func readLog(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = zr
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRotatingFileSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	now, advance := fakeClock()
+	r, err := newRotatingFile(SinkConfig{Type: SinkFile, Path: path, MaxSize: 1, MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.maxSize, r.now = 8, now
+
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n", "five\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+		advance(time.Second)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readLog(t, path); got != "five\n" {
+		t.Errorf("active segment = %q", got)
+	}
+	backups, err := r.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, b := range backups {
+		if !strings.HasSuffix(b.path, ".gz") {
+			t.Errorf("backup %s not compressed", b.path)
+		}
+		got = append(got, readLog(t, b.path))
+	}
+	if !slices.Equal(got, []string{"four\n", "three\n"}) {
+		t.Errorf("retained backups = %q", got)
+	}
+	if want := filepath.Join(dir, "app-2026-01-02T15-04-09.000.log.gz"); backups[0].path != want {
+		t.Errorf("newest backup = %s, want %s", backups[0].path, want)
+	}
+}
+
+func TestRotatingFileInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	now, advance := fakeClock()
+	sc := SinkConfig{Type: SinkRollingFile, Path: path, RotationInterval: 60, MaxAge: 1}
+	r, err := newRotatingFile(sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.now = now
+	r.next = now().Truncate(time.Hour).Add(time.Hour)
+
+	r.Write([]byte("first\n"))
+	advance(30 * time.Minute) // Still 15:34
+	r.Write([]byte("second\n"))
+	advance(30 * time.Minute) // Past 16:00
+	r.Write([]byte("third\n"))
+	advance(48 * time.Hour) // The first backup is now past maxAge
+	r.Write([]byte("fourth\n"))
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readLog(t, path); got != "fourth\n" {
+		t.Errorf("active segment = %q", got)
+	}
+	backups, _ := r.backups()
+	if len(backups) != 1 || readLog(t, backups[0].path) != "third\n" {
+		t.Errorf("backups = %v", backups)
+	}
+}
+
+func TestMemorySink(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Service = "svc"
+	cfg.Sinks = []SinkConfig{{Type: SinkMemory, Name: "debug", Capacity: 2, Format: FormatText}}
+	h := newTestHandler(t, cfg)
+	log := slog.New(h)
+	for _, msg := range []string{"one", "two", "three"} {
+		log.Info(msg)
+	}
+
+	sink, ok := h.Sink("debug")
+	if !ok {
+		t.Fatal("memory sink not found")
+	}
+	ring := sink.(*RingBuffer)
+	lines := ring.Lines()
+	if len(lines) != 2 || !strings.Contains(lines[0], " INFO two ") || !strings.Contains(lines[1], " INFO three ") {
+		t.Errorf("lines = %q", lines)
+	}
+	var b strings.Builder
+	if _, err := ring.WriteTo(&b); err != nil || b.String() != lines[0]+lines[1] {
+		t.Errorf("WriteTo = %q, %v", b.String(), err)
+	}
+	ring.Reset()
+	if len(ring.Lines()) != 0 {
+		t.Error("Reset kept events")
+	}
+	if _, ok := h.Sink("missing"); ok {
+		t.Error("found an unconfigured sink")
+	}
+
+	var zero RingBuffer
+	for i := range defaultRingCapacity + 1 {
+		fmt.Fprintf(&zero, "%d\n", i)
+	}
+	if lines := zero.Lines(); len(lines) != defaultRingCapacity || lines[0] != "1\n" {
+		t.Errorf("zero value kept %d lines starting %q", len(lines), lines[0])
+	}
+}
+
+func TestConsoleColor(t *testing.T) {
+	buf := captureStderr(t)
+	old := consoleColor
+	consoleColor = func() bool { return true }
+	t.Cleanup(func() { consoleColor = old })
+
+	cfg := DefaultConfig()
+	cfg.Service = "svc"
+	cfg.Sinks = []SinkConfig{
+		{Type: SinkConsole, Format: FormatConsole, Colorize: true},
+		{Type: SinkConsole, Format: FormatText, Colorize: true},
+	}
+	slog.New(newTestHandler(t, cfg)).Warn("careful")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], " \x1b[33mWARN\x1b[0m careful") || strings.Contains(lines[1], "\x1b") {
+		t.Errorf("console output = %q", lines)
+	}
+}
@@ -0,0 +1,151 @@
+package logging
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// defaultThrottleWindow is the summary interval when WindowSize is unset.
+const defaultThrottleWindow = time.Minute
+
+// throttle rate-limits events with a token bucket per message key (logger
+// and message). Events over budget are dropped, or with dropPolicy "block"
+// delayed until a token is available; the handler keeps no queue, so
+// drop-oldest and drop-newest both drop the event that exceeds the budget.
+// Every window, the counts of dropped events are reported through emit.
+type throttle struct {
+	rate   float64 // Tokens per second
+	burst  float64
+	window time.Duration
+	block  bool
+	filter *SeverityFilter
+	now    func() time.Time
+	emit   func(logger, message string, suppressed int)
+
+	mu      sync.Mutex
+	buckets map[throttleKey]*bucket
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+type throttleKey struct{ logger, message string }
+
+type bucket struct {
+	tokens     float64
+	last       time.Time
+	suppressed int
+}
+
+// newThrottle returns nil when cfg does not enable throttling.
+func newThrottle(cfg *ThrottlingConfig, emit func(logger, message string, suppressed int)) *throttle {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	t := &throttle{
+		rate:    float64(cfg.MaxRate),
+		burst:   float64(cmp.Or(cfg.BurstSize, cfg.MaxRate)),
+		window:  defaultThrottleWindow,
+		block:   cfg.DropPolicy == "block",
+		filter:  cfg.SeverityFilter,
+		now:     time.Now,
+		emit:    emit,
+		buckets: map[throttleKey]*bucket{},
+	}
+	if cfg.WindowSize > 0 {
+		t.window = time.Duration(cfg.WindowSize) * time.Second
+	}
+	return t
+}
+
+// start reports suppressed counts every window until close.
+func (t *throttle) start() {
+	t.stop, t.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(t.done)
+		tick := time.NewTicker(t.window)
+		defer tick.Stop()
+		for {
+			select {
+			case <-tick.C:
+				t.flush()
+			case <-t.stop:
+				return
+			}
+		}
+	}()
+}
+
+// allow reports whether ev is within its key's budget, blocking for a token
+// under the block policy. A nil throttle allows everything.
+func (t *throttle) allow(ev *LogEvent) bool {
+	if t == nil || (t.filter != nil && !t.filter.Matches(ev.Severity)) {
+		return true
+	}
+	key := throttleKey{ev.Logger, ev.Message}
+	for {
+		t.mu.Lock()
+		now := t.now()
+		b := t.buckets[key]
+		if b == nil {
+			b = &bucket{tokens: t.burst, last: now}
+			t.buckets[key] = b
+		}
+		b.tokens = min(t.burst, b.tokens+now.Sub(b.last).Seconds()*t.rate)
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			t.mu.Unlock()
+			return true
+		}
+		if !t.block {
+			b.suppressed++
+			t.mu.Unlock()
+			return false
+		}
+		wait := time.Duration((1 - b.tokens) / t.rate * float64(time.Second))
+		t.mu.Unlock()
+		time.Sleep(wait)
+	}
+}
+
+// flush emits a summary for every key with suppressed events and forgets
+// idle keys whose bucket has refilled.
+func (t *throttle) flush() {
+	type summary struct {
+		key throttleKey
+		n   int
+	}
+	var out []summary
+	t.mu.Lock()
+	now := t.now()
+	for key, b := range t.buckets {
+		if b.suppressed > 0 {
+			out = append(out, summary{key, b.suppressed})
+			b.suppressed = 0
+		} else if b.tokens+now.Sub(b.last).Seconds()*t.rate >= t.burst {
+			delete(t.buckets, key)
+		}
+	}
+	t.mu.Unlock()
+	slices.SortFunc(out, func(a, b summary) int {
+		return cmp.Or(cmp.Compare(a.key.logger, b.key.logger), cmp.Compare(a.key.message, b.key.message))
+	})
+	for _, s := range out {
+		t.emit(s.key.logger, s.key.message, s.n)
+	}
+}
+
+// close stops the summary loop and reports what is still pending.
+func (t *throttle) close() {
+	if t == nil {
+		return
+	}
+	if t.stop != nil {
+		close(t.stop)
+		<-t.done
+		t.stop = nil
+	}
+	t.flush()
+}
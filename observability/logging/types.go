@@ -0,0 +1,212 @@
+// Package logging builds log/slog handlers from the Fulmen logger
+// configuration and emits events shaped like log-event.schema.json.
+//
+// See: docs/standards/observability/logging.md
+package logging
+
+// Embedded schemas describing the logging data structures, relative to
+// schemas/ for use with crucible.ValidateSchemaValue.
+const (
+	LoggerConfigSchemaPath     = "observability/logging/v1.0.0/logger-config.schema.json"
+	LogEventSchemaPath         = "observability/logging/v1.0.0/log-event.schema.json"
+	MiddlewareConfigSchemaPath = "observability/logging/v1.0.0/middleware-config.schema.json"
+	SeverityFilterSchemaPath   = "observability/logging/v1.0.0/severity-filter.schema.json"
+)
+
+// Profile selects the logging complexity tier.
+type Profile string
+
+const (
+	ProfileSimple     Profile = "SIMPLE"
+	ProfileStructured Profile = "STRUCTURED"
+	ProfileEnterprise Profile = "ENTERPRISE"
+	ProfileCustom     Profile = "CUSTOM"
+)
+
+// SinkType identifies a sink implementation.
+type SinkType string
+
+const (
+	SinkConsole     SinkType = "console"
+	SinkFile        SinkType = "file"
+	SinkRollingFile SinkType = "rolling-file"
+	SinkMemory      SinkType = "memory"
+	SinkExternal    SinkType = "external"
+)
+
+// Format is the output encoding of a sink.
+type Format string
+
+const (
+	FormatJSON    Format = "json"
+	FormatText    Format = "text"
+	FormatConsole Format = "console"
+)
+
+// LoggerConfig is the progressive logger configuration.
+// See: schemas/observability/logging/v1.0.0/logger-config.schema.json
+type LoggerConfig struct {
+	Profile          Profile             `yaml:"profile,omitempty"          json:"profile,omitempty"`
+	Service          string              `yaml:"service"                    json:"service"`
+	Environment      string              `yaml:"environment,omitempty"      json:"environment,omitempty"`
+	PolicyFile       string              `yaml:"policyFile,omitempty"       json:"policyFile,omitempty"`
+	DefaultLevel     Severity            `yaml:"defaultLevel,omitempty"     json:"defaultLevel,omitempty"`
+	LoggerLevels     map[string]Severity `yaml:"loggerLevels,omitempty"     json:"loggerLevels,omitempty"` // Logger name -> minimum level
+	Sinks            []SinkConfig        `yaml:"sinks,omitempty"            json:"sinks,omitempty"`
+	Middleware       []MiddlewareConfig  `yaml:"middleware,omitempty"       json:"middleware,omitempty"`
+	Throttling       *ThrottlingConfig   `yaml:"throttling,omitempty"       json:"throttling,omitempty"`
+	StaticFields     map[string]any      `yaml:"staticFields,omitempty"     json:"staticFields,omitempty"`
+	EnableCaller     bool                `yaml:"enableCaller,omitempty"     json:"enableCaller,omitempty"`
+	EnableStacktrace bool                `yaml:"enableStacktrace,omitempty" json:"enableStacktrace,omitempty"`
+	CustomConfig     map[string]any      `yaml:"customConfig,omitempty"     json:"customConfig,omitempty"`
+}
+
+// SinkConfig configures one log output. Which fields apply depends on Type.
+type SinkConfig struct {
+	Type   SinkType `yaml:"type"             json:"type"`
+	Name   string   `yaml:"name,omitempty"   json:"name,omitempty"`
+	Level  Severity `yaml:"level,omitempty"  json:"level,omitempty"`  // Overrides LoggerConfig.DefaultLevel
+	Format Format   `yaml:"format,omitempty" json:"format,omitempty"` // Default json
+
+	// console
+	Stream   string `yaml:"stream,omitempty"   json:"stream,omitempty"` // Always stderr
+	Colorize bool   `yaml:"colorize,omitempty" json:"colorize,omitempty"`
+
+	// file, rolling-file
+	Path             string `yaml:"path,omitempty"             json:"path,omitempty"`
+	MaxSize          int    `yaml:"maxSize,omitempty"          json:"maxSize,omitempty"`    // Megabytes
+	MaxAge           int    `yaml:"maxAge,omitempty"           json:"maxAge,omitempty"`     // Days
+	MaxBackups       int    `yaml:"maxBackups,omitempty"       json:"maxBackups,omitempty"` // Rotated files to retain
+	Compress         bool   `yaml:"compress,omitempty"         json:"compress,omitempty"`
+	RotationInterval int    `yaml:"rotationInterval,omitempty" json:"rotationInterval,omitempty"` // Minutes
+
+	// memory
+	Capacity int `yaml:"capacity,omitempty" json:"capacity,omitempty"` // Events retained, default 1000
+
+	// external
+	Endpoint      string            `yaml:"endpoint,omitempty"      json:"endpoint,omitempty"`
+	Method        string            `yaml:"method,omitempty"        json:"method,omitempty"`
+	Headers       map[string]string `yaml:"headers,omitempty"       json:"headers,omitempty"`
+	BatchSize     int               `yaml:"batchSize,omitempty"     json:"batchSize,omitempty"`
+	FlushInterval int               `yaml:"flushInterval,omitempty" json:"flushInterval,omitempty"` // Seconds
+	Timeout       int               `yaml:"timeout,omitempty"       json:"timeout,omitempty"`       // Seconds
+}
+
+// ThrottlingConfig bounds the rate of emitted events.
+type ThrottlingConfig struct {
+	Enabled        bool            `yaml:"enabled"                  json:"enabled"`
+	MaxRate        int             `yaml:"maxRate,omitempty"        json:"maxRate,omitempty"`    // Events per second
+	BurstSize      int             `yaml:"burstSize,omitempty"      json:"burstSize,omitempty"`  // Bucket capacity, default MaxRate
+	WindowSize     int             `yaml:"windowSize,omitempty"     json:"windowSize,omitempty"` // Seconds between summaries, default 60
+	DropPolicy     string          `yaml:"dropPolicy,omitempty"     json:"dropPolicy,omitempty"`
+	SeverityFilter *SeverityFilter `yaml:"severityFilter,omitempty" json:"severityFilter,omitempty"` // Throttled events; nil means all
+}
+
+// MiddlewareType identifies a middleware implementation.
+type MiddlewareType string
+
+const (
+	MiddlewareRedaction    MiddlewareType = "redaction"
+	MiddlewareFilter       MiddlewareType = "filter"
+	MiddlewareAugmentation MiddlewareType = "augmentation"
+	MiddlewareSampling     MiddlewareType = "sampling"
+	MiddlewareCustom       MiddlewareType = "custom"
+)
+
+// MiddlewareConfig configures one log event processor.
+// See: schemas/observability/logging/v1.0.0/middleware-config.schema.json
+type MiddlewareConfig struct {
+	Type         MiddlewareType      `yaml:"type"                   json:"type"`
+	Enabled      *bool               `yaml:"enabled,omitempty"      json:"enabled,omitempty"`  // Default true
+	Priority     int                 `yaml:"priority,omitempty"     json:"priority,omitempty"` // Lower runs first
+	Redaction    *RedactionConfig    `yaml:"redaction,omitempty"    json:"redaction,omitempty"`
+	Filter       *FilterConfig       `yaml:"filter,omitempty"       json:"filter,omitempty"`
+	Augmentation *AugmentationConfig `yaml:"augmentation,omitempty" json:"augmentation,omitempty"`
+	Sampling     *SamplingConfig     `yaml:"sampling,omitempty"     json:"sampling,omitempty"`
+}
+
+// RedactionConfig masks sensitive content.
+type RedactionConfig struct {
+	Patterns    []string `yaml:"patterns,omitempty"    json:"patterns,omitempty"`    // Regexes redacted in text
+	Presets     []string `yaml:"presets,omitempty"     json:"presets,omitempty"`     // email, credit-card, bearer-token
+	Fields      []string `yaml:"fields,omitempty"      json:"fields,omitempty"`      // Field names redacted completely
+	Replacement string   `yaml:"replacement,omitempty" json:"replacement,omitempty"` // Default "[REDACTED]"
+}
+
+// FilterConfig drops events.
+type FilterConfig struct {
+	MinLevel       Severity        `yaml:"minLevel,omitempty"       json:"minLevel,omitempty"` // Deprecated: use SeverityFilter
+	SeverityFilter *SeverityFilter `yaml:"severityFilter,omitempty" json:"severityFilter,omitempty"`
+	Exclude        *FilterExclude  `yaml:"exclude,omitempty"        json:"exclude,omitempty"`
+}
+
+// FilterExclude lists loggers and components whose events are dropped.
+type FilterExclude struct {
+	Loggers    []string `yaml:"loggers,omitempty"    json:"loggers,omitempty"`
+	Components []string `yaml:"components,omitempty" json:"components,omitempty"`
+}
+
+// AugmentationConfig adds fields to events.
+type AugmentationConfig struct {
+	Fields  map[string]any `yaml:"fields,omitempty"  json:"fields,omitempty"`
+	Dynamic []string       `yaml:"dynamic,omitempty" json:"dynamic,omitempty"` // Dynamic field provider names
+}
+
+// SamplingConfig keeps a fraction of events.
+type SamplingConfig struct {
+	Rate    *float64           `yaml:"rate,omitempty"    json:"rate,omitempty"`    // 0.0 to 1.0
+	ByLevel map[string]float64 `yaml:"byLevel,omitempty" json:"byLevel,omitempty"` // Per-severity rates
+}
+
+// SeverityFilter selects events by comparing their severity with Level.
+// See: schemas/observability/logging/v1.0.0/severity-filter.schema.json
+type SeverityFilter struct {
+	Operator Operator `yaml:"operator" json:"operator"`
+	Level    Severity `yaml:"level"    json:"level"`
+}
+
+// Operator is a severity comparison operator.
+type Operator string
+
+const (
+	OpGE Operator = "GE"
+	OpLE Operator = "LE"
+	OpEQ Operator = "EQ"
+	OpNE Operator = "NE"
+	OpGT Operator = "GT"
+	OpLT Operator = "LT"
+)
+
+// LogEvent is the structured log event envelope.
+// See: schemas/observability/logging/v1.0.0/log-event.schema.json
+type LogEvent struct {
+	Timestamp     string         `json:"timestamp"`               // RFC 3339 UTC with nanoseconds
+	Severity      Severity       `json:"severity"`                // Severity name
+	SeverityLevel int            `json:"severityLevel"`           // Numeric severity
+	Message       string         `json:"message"`                 // Log message text
+	Service       string         `json:"service"`                 // Service name
+	Component     string         `json:"component,omitempty"`     // Subsystem name
+	Logger        string         `json:"logger,omitempty"`        // Logger name
+	Environment   string         `json:"environment,omitempty"`   // Deployment environment
+	Context       map[string]any `json:"context"`                 // Contextual key-value pairs
+	ContextID     string         `json:"contextId,omitempty"`     // Execution context identifier
+	RequestID     string         `json:"requestId,omitempty"`     // Request identifier
+	CorrelationID string         `json:"correlationId,omitempty"` // Cross-service UUIDv7
+	Error         *EventError    `json:"error,omitempty"`         // Error information
+	TraceID       string         `json:"traceId,omitempty"`       // Distributed trace ID
+	SpanID        string         `json:"spanId,omitempty"`        // Span ID within trace
+	ParentSpanID  string         `json:"parentSpanId,omitempty"`  // Parent span ID
+	Tags          []string       `json:"tags,omitempty"`          // Searchable tags
+	EventID       string         `json:"eventId,omitempty"`       // Unique event identifier
+	Operation     string         `json:"operation,omitempty"`     // Logical operation name
+	DurationMs    *float64       `json:"durationMs,omitempty"`    // Operation duration in milliseconds
+	UserID        string         `json:"userId,omitempty"`        // Authenticated user identifier
+	Caller        string         `json:"caller,omitempty"`        // Source location ("dir/file.go:line"), when enabled
+}
+
+// EventError describes an error attached to an event.
+type EventError struct {
+	Message string `json:"message"`
+	Type    string `json:"type,omitempty"`
+	Stack   string `json:"stack,omitempty"`
+}
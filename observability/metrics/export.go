@@ -0,0 +1,20 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteNDJSON writes events to w as newline-delimited metrics-event
+// documents, one per line.
+func WriteNDJSON(w io.Writer, events []Event) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, ev := range events {
+		if err := enc.Encode(ev); err != nil {
+			return fmt.Errorf("failed to export metric %s: %w", ev.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,382 @@
+package metrics
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Name is a metric identifier from the metrics taxonomy.
+type Name string
+
+// Unit returns the taxonomy unit of n, or "" when n is not in the taxonomy.
+func (n Name) Unit() Unit { return units[n] }
+
+// Description returns the first sentence of the taxonomy description of n.
+func (n Name) Description() string { return descriptions[n] }
+
+// Known reports whether n is in the metrics taxonomy.
+func (n Name) Known() bool {
+	_, ok := units[n]
+	return ok
+}
+
+// Unit is a metric unit from the metrics taxonomy.
+type Unit string
+
+// Kind is the instrument type of a metric.
+type Kind string
+
+const (
+	KindCounter   Kind = "counter"
+	KindGauge     Kind = "gauge"
+	KindHistogram Kind = "histogram"
+)
+
+// Tags are the key/value dimensions of a series.
+type Tags map[string]string
+
+// DefaultBuckets returns the taxonomy's default histogram buckets for u, or
+// nil when it defines none.
+func DefaultBuckets(u Unit) []float64 {
+	switch u {
+	case UnitMilliseconds:
+		return slices.Clone(millisecondBuckets)
+	case UnitSeconds:
+		return slices.Clone(secondBuckets)
+	case UnitBytes:
+		return slices.Clone(byteBuckets)
+	}
+	return nil
+}
+
+// Registry holds the counters, gauges and histograms of a process. Each
+// taxonomy name is registered once, as one kind.
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[Name]*metric
+	now     func() time.Time
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{metrics: map[Name]*metric{}, now: time.Now}
+}
+
+var defaultRegistry atomic.Pointer[Registry]
+
+func init() {
+	defaultRegistry.Store(NewRegistry())
+}
+
+// Default returns the registry Crucible packages record their metrics in.
+func Default() *Registry { return defaultRegistry.Load() }
+
+// SetDefault makes r the registry returned by Default.
+func SetDefault(r *Registry) { defaultRegistry.Store(r) }
+
+// Counter returns the counter registered as name, registering it first if
+// needed.
+func (r *Registry) Counter(name Name) (*Counter, error) {
+	m, err := r.register(name, KindCounter, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Counter{m: m}, nil
+}
+
+// Gauge returns the gauge registered as name, registering it first if
+// needed.
+func (r *Registry) Gauge(name Name) (*Gauge, error) {
+	m, err := r.register(name, KindGauge, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Gauge{m: m}, nil
+}
+
+// Histogram returns the histogram registered as name, registering it first
+// if needed. Buckets are the upper bounds, in increasing order; a new
+// histogram without them uses the taxonomy default for the metric's unit.
+// Asking for an existing histogram with different buckets is an error.
+func (r *Registry) Histogram(name Name, buckets ...float64) (*Histogram, error) {
+	for i, b := range buckets {
+		if math.IsNaN(b) || math.IsInf(b, 0) || (i > 0 && b <= buckets[i-1]) {
+			return nil, fmt.Errorf("metric %s: buckets must be finite and increasing", name)
+		}
+	}
+	m, err := r.register(name, KindHistogram, buckets)
+	if err != nil {
+		return nil, err
+	}
+	return &Histogram{m: m}, nil
+}
+
+func (r *Registry) register(name Name, kind Kind, buckets []float64) (*metric, error) {
+	if !name.Known() {
+		return nil, fmt.Errorf("metric %q is not in the metrics taxonomy", name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if m, ok := r.metrics[name]; ok {
+		if m.kind != kind {
+			return nil, fmt.Errorf("metric %s is already registered as a %s", name, m.kind)
+		}
+		if buckets != nil && !slices.Equal(m.buckets, buckets) {
+			return nil, fmt.Errorf("metric %s is already registered with buckets %v", name, m.buckets)
+		}
+		return m, nil
+	}
+	if kind == KindHistogram && buckets == nil {
+		if buckets = DefaultBuckets(name.Unit()); buckets == nil {
+			return nil, fmt.Errorf("metric %s: no default buckets for unit %s", name, name.Unit())
+		}
+	}
+	m := &metric{name: name, kind: kind, unit: name.Unit(), buckets: slices.Clone(buckets), series: map[string]*series{}}
+	r.metrics[name] = m
+	return m, nil
+}
+
+// metric holds the series of a registered name, keyed by their tags.
+type metric struct {
+	name    Name
+	kind    Kind
+	unit    Unit
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	tags   Tags
+	value  float64  // Counter and gauge value
+	counts []uint64 // Histogram observations per bucket, the last past every bound
+	count  uint64
+	sum    float64
+}
+
+// update applies f to the series for tags.
+func (m *metric) update(tags Tags, f func(s *series)) {
+	key := tagKey(tags)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{tags: maps.Clone(tags)}
+		if m.kind == KindHistogram {
+			s.counts = make([]uint64, len(m.buckets)+1)
+		}
+		m.series[key] = s
+	}
+	f(s)
+}
+
+// tagKey identifies a tag set independently of map order.
+func tagKey(tags Tags) string {
+	var sb strings.Builder
+	for _, k := range slices.Sorted(maps.Keys(tags)) {
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(tags[k])
+		sb.WriteByte(0xff)
+	}
+	return sb.String()
+}
+
+// withTags returns base overlaid with tags, leaving both unchanged.
+func withTags(base, tags Tags) Tags {
+	out := make(Tags, len(base)+len(tags))
+	maps.Copy(out, base)
+	maps.Copy(out, tags)
+	return out
+}
+
+// Counter is a monotonically increasing metric.
+type Counter struct {
+	m    *metric
+	tags Tags
+}
+
+// With returns the counter for the series with tags added.
+func (c *Counter) With(tags Tags) *Counter {
+	return &Counter{m: c.m, tags: withTags(c.tags, tags)}
+}
+
+// Inc adds 1.
+func (c *Counter) Inc() { c.Add(1) }
+
+// Add adds delta. Negative, NaN and infinite deltas, and deltas that would
+// overflow the total, are ignored: like every recording method, Add drops
+// values it cannot record rather than failing the caller.
+func (c *Counter) Add(delta float64) {
+	if !finite(delta) || delta < 0 {
+		return
+	}
+	c.m.update(c.tags, func(s *series) { s.value = add(s.value, delta) })
+}
+
+// Gauge is a metric that can go up and down.
+type Gauge struct {
+	m    *metric
+	tags Tags
+}
+
+// With returns the gauge for the series with tags added.
+func (g *Gauge) With(tags Tags) *Gauge {
+	return &Gauge{m: g.m, tags: withTags(g.tags, tags)}
+}
+
+// Set sets the gauge to v. NaN and infinite values are ignored: JSON cannot
+// represent them, so one would break every later export of the registry.
+func (g *Gauge) Set(v float64) {
+	if !finite(v) {
+		return
+	}
+	g.m.update(g.tags, func(s *series) { s.value = v })
+}
+
+// Add adds delta, which may be negative. Like Set, it ignores NaN and
+// infinite values, and deltas that would overflow the value.
+func (g *Gauge) Add(delta float64) {
+	if !finite(delta) {
+		return
+	}
+	g.m.update(g.tags, func(s *series) { s.value = add(s.value, delta) })
+}
+
+// Inc adds 1.
+func (g *Gauge) Inc() { g.Add(1) }
+
+// Dec subtracts 1.
+func (g *Gauge) Dec() { g.Add(-1) }
+
+// Histogram counts observations into buckets.
+type Histogram struct {
+	m    *metric
+	tags Tags
+}
+
+// With returns the histogram for the series with tags added.
+func (h *Histogram) With(tags Tags) *Histogram {
+	return &Histogram{m: h.m, tags: withTags(h.tags, tags)}
+}
+
+// Observe records v. NaN and infinite values are ignored, as are values that
+// would overflow the sum.
+func (h *Histogram) Observe(v float64) {
+	if !finite(v) {
+		return
+	}
+	i, _ := slices.BinarySearch(h.m.buckets, v)
+	h.m.update(h.tags, func(s *series) {
+		sum := s.sum + v
+		if !finite(sum) {
+			return
+		}
+		s.counts[i]++
+		s.count++
+		s.sum = sum
+	})
+}
+
+// ObserveDuration records d in the metric's unit: milliseconds for ms
+// metrics and seconds otherwise.
+func (h *Histogram) ObserveDuration(d time.Duration) {
+	if h.m.unit == UnitMilliseconds {
+		h.Observe(float64(d) / float64(time.Millisecond))
+		return
+	}
+	h.Observe(d.Seconds())
+}
+
+// finite reports whether v can be recorded and exported.
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// add returns v+delta, or v when the sum overflows.
+func add(v, delta float64) float64 {
+	if sum := v + delta; finite(sum) {
+		return sum
+	}
+	return v
+}
+
+// EventSchemaPath locates the metrics-event schema for
+// crucible.ValidateSchemaData.
+const EventSchemaPath = "observability/metrics/v1.0.0/metrics-event.schema.json"
+
+// Event is a metrics-event document.
+type Event struct {
+	Timestamp string `json:"timestamp"`
+	Name      Name   `json:"name"`
+	Value     any    `json:"value"` // float64, or *HistogramValue for histograms
+	Tags      Tags   `json:"tags,omitempty"`
+	Unit      Unit   `json:"unit,omitempty"`
+	Kind      Kind   `json:"-"`
+}
+
+// HistogramValue is the value of a histogram event.
+type HistogramValue struct {
+	Count   uint64   `json:"count"`
+	Sum     float64  `json:"sum"`
+	Buckets []Bucket `json:"buckets"`
+}
+
+// Bucket is a histogram bucket with its cumulative count.
+type Bucket struct {
+	LE    float64 `json:"le"`
+	Count uint64  `json:"count"`
+}
+
+// Export returns an event per series, ordered by name and then tags.
+// Counter and histogram values are cumulative since registration or the
+// last Flush.
+func (r *Registry) Export() []Event { return r.collect(false) }
+
+// Flush returns the same events as Export and clears every series, so the
+// next export reports only what was recorded after it.
+func (r *Registry) Flush() []Event { return r.collect(true) }
+
+func (r *Registry) collect(reset bool) []Event {
+	r.mu.Lock()
+	ms := slices.SortedFunc(maps.Values(r.metrics), func(a, b *metric) int { return cmp.Compare(a.name, b.name) })
+	ts := r.now().UTC().Format(time.RFC3339Nano)
+	r.mu.Unlock()
+
+	var events []Event
+	for _, m := range ms {
+		m.mu.Lock()
+		for _, key := range slices.Sorted(maps.Keys(m.series)) {
+			events = append(events, m.event(ts, m.series[key]))
+		}
+		if reset {
+			clear(m.series)
+		}
+		m.mu.Unlock()
+	}
+	return events
+}
+
+func (m *metric) event(ts string, s *series) Event {
+	ev := Event{Timestamp: ts, Name: m.name, Tags: maps.Clone(s.tags), Unit: m.unit, Kind: m.kind}
+	if m.kind != KindHistogram {
+		ev.Value = s.value
+		return ev
+	}
+	hv := &HistogramValue{Count: s.count, Sum: s.sum, Buckets: make([]Bucket, len(m.buckets))}
+	var n uint64
+	for i, le := range m.buckets {
+		n += s.counts[i]
+		hv.Buckets[i] = Bucket{LE: le, Count: n}
+	}
+	ev.Value = hv
+	return ev
+}
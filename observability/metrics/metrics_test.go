@@ -0,0 +1,212 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fulmenhq/crucible"
+	"gopkg.in/yaml.v3"
+)
+
+func newTestRegistry() *Registry {
+	r := NewRegistry()
+	r.now = func() time.Time { return time.Date(2025, 11, 6, 12, 34, 56, 789e6, time.UTC) }
+	return r
+}
+
+// exportLines writes events as NDJSON and decodes each line, validating it
+// against metrics-event.schema.json.
+func exportLines(t *testing.T, events []Event) []map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteNDJSON(&buf, events); err != nil {
+		t.Fatal(err)
+	}
+	var out []map[string]any
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		line := sc.Bytes()
+		if err := crucible.ValidateSchemaData(EventSchemaPath, line); err != nil {
+			t.Errorf("line does not match metrics-event schema: %v\n%s", err, line)
+		}
+		var ev map[string]any
+		if err := json.Unmarshal(line, &ev); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, ev)
+	}
+	return out
+}
+
+func TestTaxonomyConstants(t *testing.T) {
+	data, err := crucible.ConfigRegistry.Taxonomy().Metrics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var taxonomy struct {
+		Version string `yaml:"version"`
+		Metrics []struct {
+			Name string `yaml:"name"`
+			Unit string `yaml:"unit"`
+		} `yaml:"metrics"`
+	}
+	if err := yaml.Unmarshal(data, &taxonomy); err != nil {
+		t.Fatal(err)
+	}
+	if taxonomy.Version != TaxonomyVersion {
+		t.Errorf("TaxonomyVersion = %s, taxonomy is %s; run make codegen-metrics", TaxonomyVersion, taxonomy.Version)
+	}
+	if len(units) != len(taxonomy.Metrics) {
+		t.Errorf("%d generated metrics, taxonomy has %d; run make codegen-metrics", len(units), len(taxonomy.Metrics))
+	}
+	for _, m := range taxonomy.Metrics {
+		if got := Name(m.Name).Unit(); string(got) != m.Unit {
+			t.Errorf("%s: unit %q, taxonomy says %q", m.Name, got, m.Unit)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := newTestRegistry()
+	if _, err := r.Counter("schema_validation_total"); err == nil {
+		t.Error("expected an error for a name missing from the taxonomy")
+	}
+	if _, err := r.Histogram(FulencodeExpansionRatioPercent); err == nil {
+		t.Error("expected an error for a percent histogram without buckets")
+	}
+	if _, err := r.Histogram(ConfigLoadMs, 10, 5); err == nil {
+		t.Error("expected an error for decreasing buckets")
+	}
+
+	c, err := r.Counter(SchemaValidations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Gauge(SchemaValidations); err == nil {
+		t.Error("expected an error registering a counter name as a gauge")
+	}
+	again, err := r.Counter(SchemaValidations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Inc()
+	again.Add(2)
+	c.With(Tags{"module": "config"}).Inc()
+
+	h, err := r.Histogram(FulencodeExpansionRatioPercent, 100, 200, 400)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Histogram(FulencodeExpansionRatioPercent); err != nil {
+		t.Errorf("existing histogram without buckets: %v", err)
+	}
+	if _, err := r.Histogram(FulencodeExpansionRatioPercent, 100, 300); err == nil {
+		t.Error("expected an error for different buckets")
+	}
+	h.Observe(250)
+}
+
+func TestExport(t *testing.T) {
+	r := newTestRegistry()
+	c, _ := r.Counter(SchemaValidations)
+	g, _ := r.Gauge(PrometheusExporterRefreshInflight)
+	h, _ := r.Histogram(ConfigLoadMs)
+
+	c.With(Tags{"module": "config"}).Add(3)
+	c.Inc()
+	g.With(Tags{"phase": "collect"}).Set(4)
+	g.With(Tags{"phase": "collect"}).Dec()
+	for _, v := range []float64{0.5, 5, 42, 20000} {
+		h.With(Tags{"format": "yaml"}).Observe(v)
+	}
+	h.With(Tags{"format": "yaml"}).ObserveDuration(3 * time.Millisecond)
+
+	evs := exportLines(t, r.Export())
+	var got []string
+	for _, ev := range evs {
+		data, _ := json.Marshal(ev)
+		got = append(got, string(data))
+	}
+	want := []string{
+		`{"name":"config_load_ms","tags":{"format":"yaml"},"timestamp":"2025-11-06T12:34:56.789Z","unit":"ms","value":{"buckets":[{"count":1,"le":1},{"count":3,"le":5},{"count":3,"le":10},{"count":4,"le":50},{"count":4,"le":100},{"count":4,"le":500},{"count":4,"le":1000},{"count":4,"le":5000},{"count":4,"le":10000}],"count":5,"sum":20050.5}}`,
+		`{"name":"prometheus_exporter_refresh_inflight","tags":{"phase":"collect"},"timestamp":"2025-11-06T12:34:56.789Z","unit":"count","value":3}`,
+		`{"name":"schema_validations","timestamp":"2025-11-06T12:34:56.789Z","unit":"count","value":1}`,
+		`{"name":"schema_validations","tags":{"module":"config"},"timestamp":"2025-11-06T12:34:56.789Z","unit":"count","value":3}`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("exported\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if n := len(r.Flush()); n != 4 {
+		t.Errorf("Flush returned %d events, want 4", n)
+	}
+	if evs := r.Export(); len(evs) != 0 {
+		t.Errorf("events after Flush: %+v", evs)
+	}
+	c.Inc()
+	if evs := exportLines(t, r.Export()); len(evs) != 1 || evs[0]["value"] != 1.0 {
+		t.Errorf("counter did not restart after Flush: %+v", evs)
+	}
+}
+
+func TestInvalidValues(t *testing.T) {
+	r := newTestRegistry()
+	c, _ := r.Counter(SchemaValidations)
+	g, _ := r.Gauge(PrometheusExporterRefreshInflight)
+	h, _ := r.Histogram(ConfigLoadMs)
+
+	c.Add(2)
+	g.Set(1)
+	h.Observe(7)
+	for _, v := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		c.Add(v)
+		g.Set(v)
+		g.Add(v)
+		h.Observe(v)
+	}
+	c.Add(-1)
+	c.Add(math.MaxFloat64)
+	c.Add(math.MaxFloat64)
+	g.Add(-math.MaxFloat64)
+	g.Add(-math.MaxFloat64)
+	h.Observe(math.MaxFloat64)
+	h.Observe(math.MaxFloat64)
+
+	evs := exportLines(t, r.Export())
+	if len(evs) != 3 {
+		t.Fatalf("exported %+v", evs)
+	}
+	if got := evs[0]["value"].(map[string]any)["count"]; got != 2.0 {
+		t.Errorf("histogram count = %v, want 2", got)
+	}
+	if got := evs[1]["value"]; got != 1-math.MaxFloat64 {
+		t.Errorf("gauge = %v", got)
+	}
+	if got := evs[2]["value"]; got != 2+math.MaxFloat64 {
+		t.Errorf("counter = %v", got)
+	}
+}
+
+func TestFixtures(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "tests", "fixtures", "metrics", "*", "*.json"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no metrics fixtures found: %v", err)
+	}
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = crucible.ValidateSchemaData(EventSchemaPath, data)
+		if invalid := strings.HasPrefix(filepath.Base(p), "invalid-"); invalid != (err != nil) {
+			t.Errorf("%s: invalid=%v, validation error: %v", p, invalid, err)
+		}
+	}
+}
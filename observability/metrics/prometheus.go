@@ -0,0 +1,298 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrometheusContentType is the content type of the Prometheus text
+// exposition format, version 0.0.4.
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultPrometheusPath is the path label a PrometheusHandler records unless
+// its Path is set.
+const DefaultPrometheusPath = "/metrics"
+
+// PrometheusName returns the exposition name of a metric: ms metrics are
+// exported in seconds with their ms segment renamed, the unit (seconds,
+// bytes, percent) is appended when the name lacks it, and counters end in
+// _total.
+func PrometheusName(name Name, kind Kind) string {
+	words := strings.Split(string(name), "_")
+	var unit string
+	switch name.Unit() {
+	case UnitMilliseconds:
+		if i := slices.Index(words, "ms"); i >= 0 {
+			words[i] = "seconds"
+		}
+		unit = "seconds"
+	case UnitSeconds:
+		unit = "seconds"
+	case UnitBytes:
+		unit = "bytes"
+	case UnitPercent:
+		unit = "percent"
+	}
+	if kind == KindCounter {
+		words = slices.DeleteFunc(words, func(w string) bool { return w == "total" })
+	}
+	if unit != "" && !slices.Contains(words, unit) {
+		words = append(words, unit)
+	}
+	if kind == KindCounter {
+		words = append(words, "total")
+	}
+	return strings.Join(words, "_")
+}
+
+// prometheusScale converts values in u to Prometheus base units.
+func prometheusScale(u Unit) float64 {
+	if u == UnitMilliseconds {
+		return 1e-3
+	}
+	return 1
+}
+
+// WritePrometheus renders events, as returned by Export, in the Prometheus
+// text exposition format. Histograms become cumulative _bucket series with a
+// final le="+Inf" bucket, plus _sum and _count; a histogram tag named le is
+// exported as exported_le so it cannot collide with the bucket label.
+func WritePrometheus(w io.Writer, events []Event) error {
+	bw := bufio.NewWriter(w)
+	var family string
+	for _, ev := range events {
+		name := PrometheusName(ev.Name, ev.Kind)
+		if name != family {
+			family = name
+			fmt.Fprintf(bw, "# HELP %s %s\n", name, escapeHelp(ev.Name.Description()))
+			fmt.Fprintf(bw, "# TYPE %s %s\n", name, ev.Kind)
+		}
+		scale := prometheusScale(ev.Name.Unit())
+		switch v := ev.Value.(type) {
+		case float64:
+			writeSample(bw, name, ev.Tags, "", "", v*scale)
+		case *HistogramValue:
+			tags := ev.Tags
+			if le, ok := tags["le"]; ok {
+				tags = maps.Clone(tags)
+				delete(tags, "le")
+				tags["exported_le"] = le
+			}
+			for _, b := range v.Buckets {
+				writeSample(bw, name+"_bucket", tags, "le", formatFloat(b.LE*scale), float64(b.Count))
+			}
+			writeSample(bw, name+"_bucket", tags, "le", "+Inf", float64(v.Count))
+			writeSample(bw, name+"_sum", tags, "", "", v.Sum*scale)
+			writeSample(bw, name+"_count", tags, "", "", float64(v.Count))
+		default:
+			return fmt.Errorf("metric %s: unsupported value %T", ev.Name, ev.Value)
+		}
+	}
+	return bw.Flush()
+}
+
+// writeSample writes a sample line; extra is an additional label, such as le.
+func writeSample(w *bufio.Writer, name string, tags Tags, extra, extraValue string, v float64) {
+	w.WriteString(name)
+	keys := slices.Sorted(maps.Keys(tags))
+	if len(keys) > 0 || extra != "" {
+		w.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			writeLabel(w, labelName(k), tags[k])
+		}
+		if extra != "" {
+			if len(keys) > 0 {
+				w.WriteByte(',')
+			}
+			writeLabel(w, extra, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+func writeLabel(w *bufio.Writer, name, value string) {
+	w.WriteString(name)
+	w.WriteString(`="`)
+	w.WriteString(labelEscaper.Replace(value))
+	w.WriteByte('"')
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeHelp(s string) string { return helpEscaper.Replace(s) }
+
+// labelName maps a tag key onto the label name alphabet
+// [a-zA-Z_][a-zA-Z0-9_]*, replacing other characters with underscores.
+func labelName(k string) string {
+	b := []byte(k)
+	for i, c := range b {
+		if !(c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || i > 0 && '0' <= c && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	if len(b) == 0 {
+		return "_"
+	}
+	return string(b)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// exporterBuckets are the ADR-0007 buckets in seconds, as the taxonomy
+// specifies for prometheus_exporter_refresh_duration_seconds.
+var exporterBuckets = func() []float64 {
+	out := make([]float64, len(millisecondBuckets))
+	for i, b := range millisecondBuckets {
+		out[i] = b / 1000
+	}
+	return out
+}()
+
+// PrometheusHandler serves a registry in the Prometheus text exposition
+// format. Each scrape is recorded in the same registry with the
+// prometheus_exporter_* taxonomy metrics: a refresh (collect, convert and
+// export phases) and an HTTP request labelled with status and path.
+type PrometheusHandler struct {
+	// Path is the path label of the HTTP request metrics, DefaultPrometheusPath
+	// when empty. It is fixed rather than taken from the request URL, so
+	// clients cannot create a series per URL when the handler serves a
+	// subtree such as "/metrics/".
+	Path string
+
+	reg           *Registry
+	now           func() time.Time
+	duration      *Histogram
+	refreshes     *Counter
+	refreshErrors *Counter
+	inflight      *Gauge
+	requests      *Counter
+	httpErrors    *Counter
+}
+
+// NewPrometheusHandler returns a handler serving r. It fails when r already
+// registers an exporter metric as another kind.
+func NewPrometheusHandler(r *Registry) (*PrometheusHandler, error) {
+	h := &PrometheusHandler{reg: r, now: time.Now}
+	var err error
+	if h.duration, err = r.Histogram(PrometheusExporterRefreshDurationSeconds, exporterBuckets...); err != nil {
+		return nil, err
+	}
+	if h.refreshes, err = r.Counter(PrometheusExporterRefreshTotal); err != nil {
+		return nil, err
+	}
+	if h.refreshErrors, err = r.Counter(PrometheusExporterRefreshErrorsTotal); err != nil {
+		return nil, err
+	}
+	if h.inflight, err = r.Gauge(PrometheusExporterRefreshInflight); err != nil {
+		return nil, err
+	}
+	if h.requests, err = r.Counter(PrometheusExporterHTTPRequestsTotal); err != nil {
+		return nil, err
+	}
+	if h.httpErrors, err = r.Counter(PrometheusExporterHTTPErrorsTotal); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *PrometheusHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	status := http.StatusOK
+	defer func() {
+		path := h.Path
+		if path == "" {
+			path = DefaultPrometheusPath
+		}
+		tags := Tags{"status": strconv.Itoa(status), "path": path}
+		h.requests.With(tags).Inc()
+		if status >= 500 {
+			h.httpErrors.With(tags).Inc()
+		}
+	}()
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		status = http.StatusMethodNotAllowed
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	h.inflight.Inc()
+	defer h.inflight.Dec()
+
+	start := h.now()
+	events := h.reg.Export()
+	h.phase("collect", start, nil)
+
+	start = h.now()
+	var buf bytes.Buffer
+	err := WritePrometheus(&buf, events)
+	h.phase("convert", start, err)
+	if err != nil {
+		h.refresh(err, "convert", "validation")
+		status = http.StatusInternalServerError
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	start = h.now()
+	w.Header().Set("Content-Type", PrometheusContentType)
+	_, err = w.Write(buf.Bytes())
+	if err == nil {
+		err = req.Context().Err()
+	}
+	h.phase("export", start, err)
+	errType := "io"
+	if errors.Is(err, context.DeadlineExceeded) {
+		errType = "timeout"
+	}
+	h.refresh(err, "export", errType)
+}
+
+// phase records the duration of a refresh phase.
+func (h *PrometheusHandler) phase(name string, start time.Time, err error) {
+	h.duration.With(Tags{"phase": name, "result": result(err)}).ObserveDuration(h.now().Sub(start))
+}
+
+// refresh counts a refresh, and a failed one by error type and phase.
+func (h *PrometheusHandler) refresh(err error, phase, errType string) {
+	h.refreshes.With(Tags{"result": result(err)}).Inc()
+	if err != nil {
+		h.refreshErrors.With(Tags{"error_type": errType, "phase": phase}).Inc()
+	}
+}
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
@@ -0,0 +1,133 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusName(t *testing.T) {
+	cases := []struct {
+		name Name
+		kind Kind
+		want string
+	}{
+		{SchemaValidations, KindCounter, "schema_validations_total"},
+		{ConfigLoadMs, KindHistogram, "config_load_seconds"},
+		{FoundryMIMEDetectionMsJSON, KindHistogram, "foundry_mime_detection_seconds_json"},
+		{FoundryMIMEDetectionsTotalJSON, KindCounter, "foundry_mime_detections_json_total"},
+		{HTTPRequestsTotal, KindCounter, "http_requests_total"},
+		{HTTPRequestDurationSeconds, KindHistogram, "http_request_duration_seconds"},
+		{FulhashBytesHashedTotal, KindCounter, "fulhash_bytes_hashed_total"},
+		{PrometheusExporterRefreshInflight, KindGauge, "prometheus_exporter_refresh_inflight"},
+	}
+	for _, c := range cases {
+		if got := PrometheusName(c.name, c.kind); got != c.want {
+			t.Errorf("PrometheusName(%s, %s) = %s, want %s", c.name, c.kind, got, c.want)
+		}
+	}
+}
+
+func TestPrometheusHandler(t *testing.T) {
+	r := newTestRegistry()
+	c, _ := r.Counter(SchemaValidations)
+	h, _ := r.Histogram(ConfigLoadMs, 5, 50)
+	c.With(Tags{"module": "config", "source-file": `a"b\c`}).Add(2)
+	h.Observe(3)
+	h.ObserveDuration(40 * time.Millisecond)
+	h.Observe(500)
+
+	handler, err := NewPrometheusHandler(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler.now = func() time.Time { return time.Unix(0, 0) }
+	scrape := func() string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != PrometheusContentType {
+			t.Fatalf("status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+		}
+		return rec.Body.String()
+	}
+
+	want := `# HELP config_load_seconds Duration of configuration load operations.
+# TYPE config_load_seconds histogram
+config_load_seconds_bucket{le="0.005"} 1
+config_load_seconds_bucket{le="0.05"} 2
+config_load_seconds_bucket{le="+Inf"} 3
+config_load_seconds_sum 0.543
+config_load_seconds_count 3
+# HELP prometheus_exporter_refresh_inflight Number of concurrent refresh operations currently running.
+# TYPE prometheus_exporter_refresh_inflight gauge
+prometheus_exporter_refresh_inflight 1
+# HELP schema_validations_total Total schema validation attempts.
+# TYPE schema_validations_total counter
+schema_validations_total{module="config",source_file="a\"b\\c"} 2
+`
+	if got := scrape(); got != want {
+		t.Errorf("first scrape:\n%s\nwant:\n%s", got, want)
+	}
+
+	got := scrape()
+	for _, line := range []string{
+		"# TYPE prometheus_exporter_http_requests_total counter",
+		`prometheus_exporter_http_requests_total{path="/metrics",status="200"} 1`,
+		`prometheus_exporter_refresh_total{result="success"} 1`,
+		`prometheus_exporter_refresh_duration_seconds_bucket{phase="collect",result="success",le="0.001"} 1`,
+		`prometheus_exporter_refresh_duration_seconds_count{phase="export",result="success"} 1`,
+	} {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("second scrape lacks %q:\n%s", line, got)
+		}
+	}
+	if strings.Contains(got, "prometheus_exporter_http_errors_total") {
+		t.Errorf("unexpected HTTP errors:\n%s", got)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics/anything?x=1", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: status %d", rec.Code)
+	}
+	if got := scrape(); !strings.Contains(got, `prometheus_exporter_http_requests_total{path="/metrics",status="405"} 1`) {
+		t.Errorf("POST was not counted:\n%s", got)
+	}
+	handler.Path = "/internal/metrics"
+	scrape()
+	if got := scrape(); !strings.Contains(got, `prometheus_exporter_http_requests_total{path="/internal/metrics",status="200"} 1`) {
+		t.Errorf("Path was not used:\n%s", got)
+	}
+
+	if _, err := NewPrometheusHandler(func() *Registry {
+		r := NewRegistry()
+		r.Gauge(PrometheusExporterRefreshTotal)
+		return r
+	}()); err == nil {
+		t.Error("expected an error when an exporter metric has another kind")
+	}
+}
+
+func TestWritePrometheusLeTag(t *testing.T) {
+	r := newTestRegistry()
+	h, _ := r.Histogram(ConfigLoadMs, 5)
+	h.With(Tags{"le": "user"}).Observe(3)
+
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, r.Export()); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`config_load_seconds_bucket{exported_le="user",le="0.005"} 1`,
+		`config_load_seconds_bucket{exported_le="user",le="+Inf"} 1`,
+		`config_load_seconds_count{exported_le="user"} 1`,
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("output lacks %q:\n%s", line, buf.String())
+		}
+	}
+}
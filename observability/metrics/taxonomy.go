@@ -0,0 +1,263 @@
+// Package metrics records counters, gauges and histograms named by the Fulmen
+// metrics taxonomy and exports them as metrics-event documents.
+//
+// This file is AUTO-GENERATED from the Fulmen metrics taxonomy.
+// DO NOT EDIT MANUALLY - run `make codegen-metrics` instead.
+//
+// Taxonomy Version: 0.4.15
+// Source: config/taxonomy/metrics.yaml
+//
+// See: https://github.com/fulmenhq/crucible/blob/main/docs/standards/library/modules/telemetry-metrics.md
+package metrics
+
+// TaxonomyVersion is the metrics taxonomy version these constants were
+// generated from.
+const TaxonomyVersion = "0.4.15"
+
+// Metric units
+const (
+	UnitCount        Unit = "count"
+	UnitMilliseconds Unit = "ms"
+	UnitBytes        Unit = "bytes"
+	UnitPercent      Unit = "percent"
+	UnitSeconds      Unit = "s"
+)
+
+// Metric names
+const (
+	// Total schema validation attempts.
+	SchemaValidations Name = "schema_validations"
+	// Failed schema validation attempts.
+	SchemaValidationErrors Name = "schema_validation_errors"
+	// Duration of configuration load operations.
+	ConfigLoadMs Name = "config_load_ms"
+	// Failed configuration load attempts.
+	ConfigLoadErrors Name = "config_load_errors"
+	// Duration of pathfinder file discovery operations.
+	PathfinderFindMs Name = "pathfinder_find_ms"
+	// Failed pathfinder validation attempts.
+	PathfinderValidationErrors Name = "pathfinder_validation_errors"
+	// Pathfinder security warnings (e.g., path traversal attempts).
+	PathfinderSecurityWarnings Name = "pathfinder_security_warnings"
+	// Number of catalog lookups performed.
+	FoundryLookupCount Name = "foundry_lookup_count"
+	// Total structured log events emitted.
+	LoggingEmitCount Name = "logging_emit_count"
+	// Time spent emitting structured log events.
+	LoggingEmitLatencyMs Name = "logging_emit_latency_ms"
+	// Duration of goneat command execution (instrumentation helper).
+	GoneatCommandDurationMs Name = "goneat_command_duration_ms"
+	// Time to refresh/export registry data into Prometheus collectors.
+	PrometheusExporterRefreshDurationSeconds Name = "prometheus_exporter_refresh_duration_seconds"
+	// Total refresh cycles attempted.
+	PrometheusExporterRefreshTotal Name = "prometheus_exporter_refresh_total"
+	// Failed refresh cycles with detailed error classification.
+	PrometheusExporterRefreshErrorsTotal Name = "prometheus_exporter_refresh_errors_total"
+	// Number of concurrent refresh operations currently running.
+	PrometheusExporterRefreshInflight Name = "prometheus_exporter_refresh_inflight"
+	// HTTP scrape/exposition requests handled by exporter.
+	PrometheusExporterHTTPRequestsTotal Name = "prometheus_exporter_http_requests_total"
+	// HTTP exposition failures (5xx, auth failures, timeouts).
+	PrometheusExporterHTTPErrorsTotal Name = "prometheus_exporter_http_errors_total"
+	// Exporter refresh loop or HTTP server restarts.
+	PrometheusExporterRestartsTotal Name = "prometheus_exporter_restarts_total"
+	// Total JSON MIME type detections performed by the Foundry module.
+	FoundryMIMEDetectionsTotalJSON Name = "foundry_mime_detections_total_json"
+	// Total XML MIME type detections performed by the Foundry module.
+	FoundryMIMEDetectionsTotalXML Name = "foundry_mime_detections_total_xml"
+	// Total YAML MIME type detections performed by the Foundry module.
+	FoundryMIMEDetectionsTotalYaml Name = "foundry_mime_detections_total_yaml"
+	// Total CSV MIME type detections performed by the Foundry module.
+	FoundryMIMEDetectionsTotalCsv Name = "foundry_mime_detections_total_csv"
+	// Total plain text MIME type detections performed by the Foundry module.
+	FoundryMIMEDetectionsTotalPlainText Name = "foundry_mime_detections_total_plain_text"
+	// Total unknown/binary MIME type detections performed by the Foundry module.
+	FoundryMIMEDetectionsTotalUnknown Name = "foundry_mime_detections_total_unknown"
+	// JSON MIME type detection latency in milliseconds.
+	FoundryMIMEDetectionMsJSON Name = "foundry_mime_detection_ms_json"
+	// XML MIME type detection latency in milliseconds.
+	FoundryMIMEDetectionMsXML Name = "foundry_mime_detection_ms_xml"
+	// YAML MIME type detection latency in milliseconds.
+	FoundryMIMEDetectionMsYaml Name = "foundry_mime_detection_ms_yaml"
+	// CSV MIME type detection latency in milliseconds.
+	FoundryMIMEDetectionMsCsv Name = "foundry_mime_detection_ms_csv"
+	// Plain text MIME type detection latency in milliseconds.
+	FoundryMIMEDetectionMsPlainText Name = "foundry_mime_detection_ms_plain_text"
+	// Unknown/binary MIME type detection latency in milliseconds.
+	FoundryMIMEDetectionMsUnknown Name = "foundry_mime_detection_ms_unknown"
+	// Total error wrap operations performed by the Error Handling module.
+	ErrorHandlingWrapsTotal Name = "error_handling_wraps_total"
+	// Error wrap operation latency in milliseconds.
+	ErrorHandlingWrapMs Name = "error_handling_wrap_ms"
+	// Total XXH3-128 hash operations performed by the FulHash module.
+	FulhashOperationsTotalXxh3128 Name = "fulhash_operations_total_xxh3_128"
+	// Total SHA256 hash operations performed by the FulHash module.
+	FulhashOperationsTotalSha256 Name = "fulhash_operations_total_sha256"
+	// Total string hash operations performed by the FulHash module (any algorithm).
+	FulhashHashStringTotal Name = "fulhash_hash_string_total"
+	// Total bytes processed across all hash operations by the FulHash module.
+	FulhashBytesHashedTotal Name = "fulhash_bytes_hashed_total"
+	// Hash operation latency in milliseconds for all algorithms.
+	FulhashOperationMs Name = "fulhash_operation_ms"
+	// Total Fulencode operations executed.
+	FulencodeOperationTotal Name = "fulencode_operation_total"
+	// Fulencode operation duration in seconds.
+	FulencodeOperationDurationSeconds Name = "fulencode_operation_duration_seconds"
+	// Total bytes processed by Fulencode.
+	FulencodeBytesProcessedTotal Name = "fulencode_bytes_processed_total"
+	// Expansion ratio (output/input) expressed as a percentage for encode/decode operations.
+	FulencodeExpansionRatioPercent Name = "fulencode_expansion_ratio_percent"
+	// Total encoding detection outcomes.
+	FulencodeDetectResultTotal Name = "fulencode_detect_result_total"
+	// Encoding detection duration in seconds.
+	FulencodeDetectDurationSeconds Name = "fulencode_detect_duration_seconds"
+	// Total normalization operations executed.
+	FulencodeNormalizeTotal Name = "fulencode_normalize_total"
+	// Total semantic changes detected during normalization (typically NFKC/NFKD).
+	FulencodeNormalizeSemanticChangesTotal Name = "fulencode_normalize_semantic_changes_total"
+	// Total security violations detected by Fulencode.
+	FulencodeSecurityViolationsTotal Name = "fulencode_security_violations_total"
+	// Total corrections applied in non-strict modes (replace/fallback).
+	FulencodeCorrectionsTotal Name = "fulencode_corrections_total"
+	// Total BOM operations executed.
+	FulencodeBomOperationsTotal Name = "fulencode_bom_operations_total"
+	// Total BOM mismatch events detected.
+	FulencodeBomMismatchesTotal Name = "fulencode_bom_mismatches_total"
+	// Total Fulencode errors by canonical error code.
+	FulencodeErrorsTotal Name = "fulencode_errors_total"
+	// Total HTTP server requests received.
+	HTTPRequestsTotal Name = "http_requests_total"
+	// HTTP server request duration in seconds.
+	HTTPRequestDurationSeconds Name = "http_request_duration_seconds"
+	// HTTP server request body size in bytes.
+	HTTPRequestSizeBytes Name = "http_request_size_bytes"
+	// HTTP server response body size in bytes.
+	HTTPResponseSizeBytes Name = "http_response_size_bytes"
+	// Number of HTTP requests currently being processed.
+	HTTPActiveRequests Name = "http_active_requests"
+)
+
+// units maps every taxonomy metric to its unit.
+var units = map[Name]Unit{
+	SchemaValidations:                        UnitCount,
+	SchemaValidationErrors:                   UnitCount,
+	ConfigLoadMs:                             UnitMilliseconds,
+	ConfigLoadErrors:                         UnitCount,
+	PathfinderFindMs:                         UnitMilliseconds,
+	PathfinderValidationErrors:               UnitCount,
+	PathfinderSecurityWarnings:               UnitCount,
+	FoundryLookupCount:                       UnitCount,
+	LoggingEmitCount:                         UnitCount,
+	LoggingEmitLatencyMs:                     UnitMilliseconds,
+	GoneatCommandDurationMs:                  UnitMilliseconds,
+	PrometheusExporterRefreshDurationSeconds: UnitSeconds,
+	PrometheusExporterRefreshTotal:           UnitCount,
+	PrometheusExporterRefreshErrorsTotal:     UnitCount,
+	PrometheusExporterRefreshInflight:        UnitCount,
+	PrometheusExporterHTTPRequestsTotal:      UnitCount,
+	PrometheusExporterHTTPErrorsTotal:        UnitCount,
+	PrometheusExporterRestartsTotal:          UnitCount,
+	FoundryMIMEDetectionsTotalJSON:           UnitCount,
+	FoundryMIMEDetectionsTotalXML:            UnitCount,
+	FoundryMIMEDetectionsTotalYaml:           UnitCount,
+	FoundryMIMEDetectionsTotalCsv:            UnitCount,
+	FoundryMIMEDetectionsTotalPlainText:      UnitCount,
+	FoundryMIMEDetectionsTotalUnknown:        UnitCount,
+	FoundryMIMEDetectionMsJSON:               UnitMilliseconds,
+	FoundryMIMEDetectionMsXML:                UnitMilliseconds,
+	FoundryMIMEDetectionMsYaml:               UnitMilliseconds,
+	FoundryMIMEDetectionMsCsv:                UnitMilliseconds,
+	FoundryMIMEDetectionMsPlainText:          UnitMilliseconds,
+	FoundryMIMEDetectionMsUnknown:            UnitMilliseconds,
+	ErrorHandlingWrapsTotal:                  UnitCount,
+	ErrorHandlingWrapMs:                      UnitMilliseconds,
+	FulhashOperationsTotalXxh3128:            UnitCount,
+	FulhashOperationsTotalSha256:             UnitCount,
+	FulhashHashStringTotal:                   UnitCount,
+	FulhashBytesHashedTotal:                  UnitBytes,
+	FulhashOperationMs:                       UnitMilliseconds,
+	FulencodeOperationTotal:                  UnitCount,
+	FulencodeOperationDurationSeconds:        UnitSeconds,
+	FulencodeBytesProcessedTotal:             UnitBytes,
+	FulencodeExpansionRatioPercent:           UnitPercent,
+	FulencodeDetectResultTotal:               UnitCount,
+	FulencodeDetectDurationSeconds:           UnitSeconds,
+	FulencodeNormalizeTotal:                  UnitCount,
+	FulencodeNormalizeSemanticChangesTotal:   UnitCount,
+	FulencodeSecurityViolationsTotal:         UnitCount,
+	FulencodeCorrectionsTotal:                UnitCount,
+	FulencodeBomOperationsTotal:              UnitCount,
+	FulencodeBomMismatchesTotal:              UnitCount,
+	FulencodeErrorsTotal:                     UnitCount,
+	HTTPRequestsTotal:                        UnitCount,
+	HTTPRequestDurationSeconds:               UnitSeconds,
+	HTTPRequestSizeBytes:                     UnitBytes,
+	HTTPResponseSizeBytes:                    UnitBytes,
+	HTTPActiveRequests:                       UnitCount,
+}
+
+// descriptions holds the first sentence of every taxonomy description.
+var descriptions = map[Name]string{
+	SchemaValidations:                        "Total schema validation attempts.",
+	SchemaValidationErrors:                   "Failed schema validation attempts.",
+	ConfigLoadMs:                             "Duration of configuration load operations.",
+	ConfigLoadErrors:                         "Failed configuration load attempts.",
+	PathfinderFindMs:                         "Duration of pathfinder file discovery operations.",
+	PathfinderValidationErrors:               "Failed pathfinder validation attempts.",
+	PathfinderSecurityWarnings:               "Pathfinder security warnings (e.g., path traversal attempts).",
+	FoundryLookupCount:                       "Number of catalog lookups performed.",
+	LoggingEmitCount:                         "Total structured log events emitted.",
+	LoggingEmitLatencyMs:                     "Time spent emitting structured log events.",
+	GoneatCommandDurationMs:                  "Duration of goneat command execution (instrumentation helper).",
+	PrometheusExporterRefreshDurationSeconds: "Time to refresh/export registry data into Prometheus collectors.",
+	PrometheusExporterRefreshTotal:           "Total refresh cycles attempted.",
+	PrometheusExporterRefreshErrorsTotal:     "Failed refresh cycles with detailed error classification.",
+	PrometheusExporterRefreshInflight:        "Number of concurrent refresh operations currently running.",
+	PrometheusExporterHTTPRequestsTotal:      "HTTP scrape/exposition requests handled by exporter.",
+	PrometheusExporterHTTPErrorsTotal:        "HTTP exposition failures (5xx, auth failures, timeouts).",
+	PrometheusExporterRestartsTotal:          "Exporter refresh loop or HTTP server restarts.",
+	FoundryMIMEDetectionsTotalJSON:           "Total JSON MIME type detections performed by the Foundry module.",
+	FoundryMIMEDetectionsTotalXML:            "Total XML MIME type detections performed by the Foundry module.",
+	FoundryMIMEDetectionsTotalYaml:           "Total YAML MIME type detections performed by the Foundry module.",
+	FoundryMIMEDetectionsTotalCsv:            "Total CSV MIME type detections performed by the Foundry module.",
+	FoundryMIMEDetectionsTotalPlainText:      "Total plain text MIME type detections performed by the Foundry module.",
+	FoundryMIMEDetectionsTotalUnknown:        "Total unknown/binary MIME type detections performed by the Foundry module.",
+	FoundryMIMEDetectionMsJSON:               "JSON MIME type detection latency in milliseconds.",
+	FoundryMIMEDetectionMsXML:                "XML MIME type detection latency in milliseconds.",
+	FoundryMIMEDetectionMsYaml:               "YAML MIME type detection latency in milliseconds.",
+	FoundryMIMEDetectionMsCsv:                "CSV MIME type detection latency in milliseconds.",
+	FoundryMIMEDetectionMsPlainText:          "Plain text MIME type detection latency in milliseconds.",
+	FoundryMIMEDetectionMsUnknown:            "Unknown/binary MIME type detection latency in milliseconds.",
+	ErrorHandlingWrapsTotal:                  "Total error wrap operations performed by the Error Handling module.",
+	ErrorHandlingWrapMs:                      "Error wrap operation latency in milliseconds.",
+	FulhashOperationsTotalXxh3128:            "Total XXH3-128 hash operations performed by the FulHash module.",
+	FulhashOperationsTotalSha256:             "Total SHA256 hash operations performed by the FulHash module.",
+	FulhashHashStringTotal:                   "Total string hash operations performed by the FulHash module (any algorithm).",
+	FulhashBytesHashedTotal:                  "Total bytes processed across all hash operations by the FulHash module.",
+	FulhashOperationMs:                       "Hash operation latency in milliseconds for all algorithms.",
+	FulencodeOperationTotal:                  "Total Fulencode operations executed.",
+	FulencodeOperationDurationSeconds:        "Fulencode operation duration in seconds.",
+	FulencodeBytesProcessedTotal:             "Total bytes processed by Fulencode.",
+	FulencodeExpansionRatioPercent:           "Expansion ratio (output/input) expressed as a percentage for encode/decode operations.",
+	FulencodeDetectResultTotal:               "Total encoding detection outcomes.",
+	FulencodeDetectDurationSeconds:           "Encoding detection duration in seconds.",
+	FulencodeNormalizeTotal:                  "Total normalization operations executed.",
+	FulencodeNormalizeSemanticChangesTotal:   "Total semantic changes detected during normalization (typically NFKC/NFKD).",
+	FulencodeSecurityViolationsTotal:         "Total security violations detected by Fulencode.",
+	FulencodeCorrectionsTotal:                "Total corrections applied in non-strict modes (replace/fallback).",
+	FulencodeBomOperationsTotal:              "Total BOM operations executed.",
+	FulencodeBomMismatchesTotal:              "Total BOM mismatch events detected.",
+	FulencodeErrorsTotal:                     "Total Fulencode errors by canonical error code.",
+	HTTPRequestsTotal:                        "Total HTTP server requests received.",
+	HTTPRequestDurationSeconds:               "HTTP server request duration in seconds.",
+	HTTPRequestSizeBytes:                     "HTTP server request body size in bytes.",
+	HTTPResponseSizeBytes:                    "HTTP server response body size in bytes.",
+	HTTPActiveRequests:                       "Number of HTTP requests currently being processed.",
+}
+
+// Default histogram buckets (ADR-0007), from the taxonomy defaults.
+var (
+	millisecondBuckets = []float64{1, 5, 10, 50, 100, 500, 1000, 5000, 10000}
+	secondBuckets      = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	byteBuckets        = []float64{1024, 10240, 102400, 1048576, 10485760, 104857600}
+)
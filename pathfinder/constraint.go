@@ -0,0 +1,337 @@
+package pathfinder
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fulmenhq/crucible"
+)
+
+// PathConstraintSchemaPath is the embedded PathConstraint schema, relative to
+// schemas/.
+const PathConstraintSchemaPath = "pathfinder/v1.0.0/path-constraint.schema.json"
+
+// ConstraintType is the scope of a constraint boundary.
+type ConstraintType string
+
+const (
+	ConstraintRepository ConstraintType = "repository"
+	ConstraintWorkspace  ConstraintType = "workspace"
+	ConstraintCloud      ConstraintType = "cloud"
+)
+
+// EnforcementLevel controls what happens when a path violates a constraint.
+type EnforcementLevel string
+
+const (
+	EnforcementStrict     EnforcementLevel = "strict"     // Reject and report the violation
+	EnforcementWarn       EnforcementLevel = "warn"       // Report the violation but allow the path
+	EnforcementPermissive EnforcementLevel = "permissive" // Allow the path silently
+)
+
+// PathConstraint configures a safety boundary for path access.
+// See: schemas/pathfinder/v1.0.0/path-constraint.schema.json
+type PathConstraint struct {
+	Root             string           `json:"root"`                      // Root path for the constraint boundary
+	Type             ConstraintType   `json:"type"`                      // Type of constraint defining the boundary scope
+	EnforcementLevel EnforcementLevel `json:"enforcementLevel"`          // How strictly the constraint is enforced
+	AllowedPatterns  []string         `json:"allowedPatterns,omitempty"` // Additional allowed path patterns
+	BlockedPatterns  []string         `json:"blockedPatterns,omitempty"` // Blocked path patterns
+}
+
+// Constraint evaluates paths against a PathConstraint. It is safe for
+// concurrent use.
+type Constraint struct {
+	pc       PathConstraint
+	root     string // absolute root
+	realRoot string // root with symlinks resolved
+}
+
+// NewConstraint validates pc and returns its evaluator. The root need not
+// exist yet; symlinks are resolved as far as the existing part of it reaches.
+func NewConstraint(pc PathConstraint) (*Constraint, error) {
+	if err := crucible.ValidateSchemaValue(PathConstraintSchemaPath, pc); err != nil {
+		return nil, NewError(CodeInvalidConstraint, "invalid path constraint").Wrap(err)
+	}
+	if pc.Root == "" {
+		return nil, NewError(CodeInvalidConstraint, "constraint root is empty")
+	}
+	if err := ValidatePatterns(slices.Concat(pc.AllowedPatterns, pc.BlockedPatterns)); err != nil {
+		return nil, NewError(CodeInvalidConstraint, "malformed glob pattern").Wrap(err)
+	}
+	root, err := filepath.Abs(pc.Root)
+	if err != nil {
+		return nil, NewError(CodeInvalidConstraint, "cannot resolve constraint root").WithPath(pc.Root).Wrap(err)
+	}
+	realRoot, err := resolveExisting(root)
+	if err != nil {
+		return nil, ioError(err, root)
+	}
+	return &Constraint{pc: pc, root: root, realRoot: realRoot}, nil
+}
+
+// Root returns the absolute constraint root.
+func (c *Constraint) Root() string {
+	return c.root
+}
+
+// Level returns the enforcement level.
+func (c *Constraint) Level() EnforcementLevel {
+	return c.pc.EnforcementLevel
+}
+
+// Evaluate checks p, a path relative to the constraint root, and returns the
+// violation it commits, or nil. Reason codes:
+//
+//   - ABSOLUTE_PATH: p is absolute (rooted, a drive path or a UNC path)
+//   - PATH_TRAVERSAL: p contains a ".." segment
+//   - INVALID_PATH: p contains a NUL byte
+//   - BLOCKED_PATH: p, or a directory above it, matches a blocked pattern and
+//     no allowed pattern
+//   - SYMLINK_ESCAPE: a symlink on the existing part of p resolves outside
+//     the root
+//
+// Evaluate applies no enforcement level; see Check and Enforce.
+func (c *Constraint) Evaluate(p string) *Error {
+	rel, err := CleanRelative(p)
+	if err != nil {
+		return err
+	}
+	if rel != "" && matchesTree(c.pc.BlockedPatterns, rel) && !matchesTree(c.pc.AllowedPatterns, rel) {
+		return NewError(CodeBlockedPath, "path matches a blocked pattern").WithPath(p)
+	}
+	real, rerr := resolveExisting(filepath.Join(c.root, filepath.FromSlash(rel)))
+	if rerr != nil {
+		return ioError(rerr, p)
+	}
+	if !within(c.realRoot, real) {
+		return NewError(CodeSymlinkEscape, "path resolves outside the constraint root").
+			WithPath(p).WithDetail("resolved", real)
+	}
+	return nil
+}
+
+// Enforce applies the enforcement level to a violation returned by
+// Evaluate. Under strict enforcement the violation is reported and returned;
+// under warn it is reported and nil is returned; permissive ignores it.
+// Reported violations are counted in pathfinder_security_warnings on the
+// default metrics registry.
+func (c *Constraint) Enforce(v *Error) error {
+	if v == nil || c.pc.EnforcementLevel == EnforcementPermissive {
+		return nil
+	}
+	reportViolation(v, c.pc.EnforcementLevel)
+	if c.pc.EnforcementLevel == EnforcementStrict {
+		return v
+	}
+	return nil
+}
+
+// Check evaluates p and enforces the result.
+func (c *Constraint) Check(p string) error {
+	return c.Enforce(c.Evaluate(p))
+}
+
+// evaluateAbs evaluates the absolute path abs, rejecting it as a traversal
+// when it lies outside the root.
+func (c *Constraint) evaluateAbs(abs string) *Error {
+	rel, err := filepath.Rel(c.root, abs)
+	if err != nil {
+		return NewError(CodePathTraversal, "path is outside the constraint root").WithPath(abs).Wrap(err)
+	}
+	if v := c.Evaluate(filepath.ToSlash(rel)); v != nil {
+		v.Path = abs
+		return v
+	}
+	return nil
+}
+
+// CleanRelative validates p as a path relative to some root and returns its
+// cleaned, slash-separated form; the root itself cleans to "". Backslashes
+// are treated as separators. Absolute paths, paths containing a ".." segment
+// and paths containing a NUL byte are rejected with ABSOLUTE_PATH,
+// PATH_TRAVERSAL and INVALID_PATH respectively.
+func CleanRelative(p string) (string, *Error) {
+	if strings.ContainsRune(p, 0) {
+		return "", NewError(CodeInvalidPath, "path contains a NUL byte").WithPath(p)
+	}
+	s := strings.ReplaceAll(p, `\`, "/")
+	if IsAbsolute(s) {
+		return "", NewError(CodeAbsolutePath, "path is absolute").WithPath(p)
+	}
+	for _, seg := range strings.Split(s, "/") {
+		if seg == ".." {
+			return "", NewError(CodePathTraversal, "path escapes the root").WithPath(p)
+		}
+	}
+	s = path.Clean(s)
+	if s == "." {
+		return "", nil
+	}
+	return s, nil
+}
+
+// CheckLinkTarget validates the target of a link stored at name, a path
+// already cleaned by CleanRelative. Symlink targets are relative to the
+// link's directory; hard link targets are relative to the root. Absolute
+// targets and targets resolving outside the root are rejected with
+// SYMLINK_ESCAPE; an empty target with INVALID_PATH.
+func CheckLinkTarget(name, target string, hardlink bool) *Error {
+	t := strings.ReplaceAll(target, `\`, "/")
+	escape := func(why string) *Error {
+		return NewError(CodeSymlinkEscape, fmt.Sprintf("link target %q %s", target, why)).
+			WithPath(name).WithDetail("target", target)
+	}
+	if t == "" {
+		return NewError(CodeInvalidPath, "link has an empty target").WithPath(name)
+	}
+	if IsAbsolute(t) {
+		return escape("is absolute")
+	}
+	base := path.Dir(name)
+	if hardlink {
+		base = "."
+	}
+	resolved := path.Join(base, t)
+	if resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return escape("is outside the root")
+	}
+	return nil
+}
+
+// maxLinkHops bounds symlink resolution in LinkChecker, like ELOOP.
+const maxLinkHops = 40
+
+// LinkChecker validates the links of a tree materialized under a root in
+// order, such as the entries of an archive being extracted. CheckLinkTarget
+// judges each target lexically, so a chain of links that each stay inside
+// the root, such as "d/up -> .." followed by "d/up/x -> ../..", can still
+// plant a link that leaves it. LinkChecker resolves every link name and
+// target through the symlinks checked before it instead.
+//
+// The zero value is ready to use. A LinkChecker is not safe for concurrent
+// use.
+type LinkChecker struct {
+	symlinks map[string]string // Name of each accepted symlink to its target
+}
+
+// Check validates a link stored at name, a path already cleaned by
+// CleanRelative, with the rules of CheckLinkTarget, then resolves it through
+// the symlinks accepted so far. Targets that leave the root, or need more
+// than 40 links to resolve, are rejected with SYMLINK_ESCAPE. Accepted
+// symlinks are remembered for the links checked after them.
+func (c *LinkChecker) Check(name, target string, hardlink bool) *Error {
+	if err := CheckLinkTarget(name, target, hardlink); err != nil {
+		return err
+	}
+	t := strings.ReplaceAll(target, `\`, "/")
+	hops := 0
+	dir := ""
+	ok := true
+	if !hardlink {
+		dir, ok = c.walk("", path.Dir(name), &hops)
+	}
+	if ok {
+		_, ok = c.walk(dir, t, &hops)
+	}
+	if !ok {
+		return NewError(CodeSymlinkEscape, fmt.Sprintf("link target %q resolves outside the root through other links", target)).
+			WithPath(name).WithDetail("target", target)
+	}
+	if !hardlink {
+		if c.symlinks == nil {
+			c.symlinks = map[string]string{}
+		}
+		c.symlinks[name] = t
+	}
+	return nil
+}
+
+// walk resolves rel against dir, a path relative to the root that contains
+// no symlinks, following the accepted symlinks. It reports false if the
+// result leaves the root.
+func (c *LinkChecker) walk(dir, rel string, hops *int) (string, bool) {
+	for _, part := range strings.Split(rel, "/") {
+		switch part {
+		case "", ".":
+		case "..":
+			if dir == "" {
+				return "", false
+			}
+			i := strings.LastIndex(dir, "/")
+			dir = dir[:max(i, 0)]
+		default:
+			next := path.Join(dir, part)
+			target, ok := c.symlinks[next]
+			if !ok {
+				dir = next
+				continue
+			}
+			if *hops++; *hops > maxLinkHops {
+				return "", false
+			}
+			if dir, ok = c.walk(dir, target, hops); !ok {
+				return "", false
+			}
+		}
+	}
+	return dir, true
+}
+
+// IsAbsolute reports whether a slash-separated path is absolute on any
+// platform: rooted, a Windows drive path, or a UNC path.
+func IsAbsolute(p string) bool {
+	if strings.HasPrefix(p, "/") {
+		return true
+	}
+	return len(p) >= 2 && p[1] == ':' && (p[0]|0x20 >= 'a' && p[0]|0x20 <= 'z')
+}
+
+// matchesTree reports whether rel or one of the directories above it
+// matches any of patterns, so a pattern naming a directory covers its
+// contents.
+func matchesTree(patterns []string, rel string) bool {
+	for p := rel; ; {
+		if MatchAny(patterns, p) {
+			return true
+		}
+		i := strings.LastIndex(p, "/")
+		if i < 0 {
+			return false
+		}
+		p = p[:i]
+	}
+}
+
+// resolveExisting resolves symlinks in the longest existing prefix of the
+// absolute path abs and appends the remainder unchanged.
+func resolveExisting(abs string) (string, error) {
+	var rest []string
+	for p := abs; ; {
+		real, err := filepath.EvalSymlinks(p)
+		if err == nil {
+			return filepath.Join(append([]string{real}, rest...)...), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(p)
+		if parent == p {
+			return abs, nil
+		}
+		rest = append([]string{filepath.Base(p)}, rest...)
+		p = parent
+	}
+}
+
+// within reports whether p is root or lies beneath it.
+func within(root, p string) bool {
+	rel, err := filepath.Rel(root, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
@@ -0,0 +1,184 @@
+package pathfinder
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/fulmenhq/crucible/observability/metrics"
+)
+
+func TestConstraintEvaluate(t *testing.T) {
+	root := writeTree(t, map[string]string{"src/main.go": "", "secrets/key.pem": "", "secrets/README.md": ""})
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(root, "out")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(root, "src"), filepath.Join(root, "alias")); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewConstraint(PathConstraint{
+		Root:             root,
+		Type:             ConstraintRepository,
+		EnforcementLevel: EnforcementStrict,
+		BlockedPatterns:  []string{"secrets", "**/*.env"},
+		AllowedPatterns:  []string{"secrets/README.md"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want error
+	}{
+		{"", nil},
+		{"src/main.go", nil},
+		{"src/./new/file.go", nil},
+		{"alias/main.go", nil},
+		{"secrets/README.md", nil},
+		{"/etc/passwd", ErrAbsolutePath},
+		{`C:\Windows\win.ini`, ErrAbsolutePath},
+		{"../outside", ErrPathTraversal},
+		{"src/../../outside", ErrPathTraversal},
+		{"src/\x00", ErrInvalidPath},
+		{"secrets/key.pem", ErrBlockedPath},
+		{"config/prod.env", ErrBlockedPath},
+		{"out", ErrSymlinkEscape},
+		{"out/not-yet-created.txt", ErrSymlinkEscape},
+	}
+	for _, tt := range tests {
+		v := c.Evaluate(tt.path)
+		switch {
+		case tt.want == nil && v != nil:
+			t.Errorf("Evaluate(%q) = %v, want nil", tt.path, v)
+		case tt.want != nil && !errors.Is(v, tt.want):
+			t.Errorf("Evaluate(%q) = %v, want %v", tt.path, v, tt.want)
+		case v != nil && !v.IsSecurityViolation() && v.Code != CodeInvalidPath:
+			t.Errorf("Evaluate(%q) = %v, not a security violation", tt.path, v)
+		}
+	}
+}
+
+func TestConstraintEnforcement(t *testing.T) {
+	old := metrics.Default()
+	t.Cleanup(func() { metrics.SetDefault(old) })
+
+	root := t.TempDir()
+	for _, tt := range []struct {
+		level   EnforcementLevel
+		wantErr bool
+		reports int
+	}{
+		{EnforcementStrict, true, 1},
+		{EnforcementWarn, false, 1},
+		{EnforcementPermissive, false, 0},
+	} {
+		reg := metrics.NewRegistry()
+		metrics.SetDefault(reg)
+		c, err := NewConstraint(PathConstraint{Root: root, Type: ConstraintWorkspace, EnforcementLevel: tt.level})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = c.Check("../escape")
+		got := reg.Export()
+		if (err != nil) != tt.wantErr || len(got) != tt.reports {
+			t.Errorf("%s: err = %v, %d reports; want error %v, %d reports", tt.level, err, len(got), tt.wantErr, tt.reports)
+		}
+		if len(got) == 1 && (got[0].Name != metrics.PathfinderSecurityWarnings || got[0].Tags["code"] != string(CodePathTraversal) || got[0].Tags["level"] != string(tt.level)) {
+			t.Errorf("%s: unexpected violation %+v", tt.level, got[0])
+		}
+		if err := c.Check("ok.txt"); err != nil {
+			t.Errorf("%s: Check(ok.txt) = %v", tt.level, err)
+		}
+	}
+}
+
+func TestNewConstraintInvalid(t *testing.T) {
+	for _, pc := range []PathConstraint{
+		{Root: ".", Type: "galaxy", EnforcementLevel: EnforcementStrict},
+		{Root: ".", Type: ConstraintWorkspace, EnforcementLevel: "lenient"},
+		{Root: "", Type: ConstraintWorkspace, EnforcementLevel: EnforcementStrict},
+		{Root: ".", Type: ConstraintWorkspace, EnforcementLevel: EnforcementStrict, BlockedPatterns: []string{"[a"}},
+	} {
+		if _, err := NewConstraint(pc); !errors.Is(err, ErrInvalidConstraint) {
+			t.Errorf("NewConstraint(%+v) = %v, want INVALID_CONSTRAINT", pc, err)
+		}
+	}
+}
+
+func TestFinderConstraint(t *testing.T) {
+	root := writeTree(t, map[string]string{"a.txt": "", "keys/id.pem": "", "src/b.go": ""})
+	outside := writeTree(t, map[string]string{"shared.txt": ""})
+	if err := os.Symlink(outside, filepath.Join(root, "ext")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	find := func(level EnforcementLevel, q FindQuery) ([]string, []error) {
+		t.Helper()
+		f, err := NewFinder(FinderConfig{Constraint: &PathConstraint{
+			Root: root, Type: ConstraintRepository, EnforcementLevel: level, BlockedPatterns: []string{"keys"},
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var paths []string
+		var errs []error
+		for res, err := range f.Find(context.Background(), q) {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			paths = append(paths, res.RelativePath)
+		}
+		return paths, errs
+	}
+
+	got, errs := find(EnforcementStrict, FindQuery{Root: root, FollowSymlinks: true})
+	if want := []string{"a.txt", "src/b.go"}; !slices.Equal(got, want) {
+		t.Errorf("strict: got %v, want %v", got, want)
+	}
+	if len(errs) != 2 || !errors.Is(errs[0], ErrSymlinkEscape) || !errors.Is(errs[1], ErrBlockedPath) {
+		t.Errorf("strict: expected SYMLINK_ESCAPE and BLOCKED_PATH, got %v", errs)
+	}
+
+	got, errs = find(EnforcementWarn, FindQuery{Root: root, FollowSymlinks: true})
+	if want := []string{"a.txt", "ext/shared.txt", "keys/id.pem", "src/b.go"}; len(errs) != 0 || !slices.Equal(got, want) {
+		t.Errorf("warn: got %v, %v; want %v", got, errs, want)
+	}
+
+	got, errs = find(EnforcementStrict, FindQuery{Root: outside})
+	if len(got) != 0 || len(errs) != 1 || !errors.Is(errs[0], ErrPathTraversal) {
+		t.Errorf("root outside constraint: got %v, %v", got, errs)
+	}
+}
+
+func TestLinkChecker(t *testing.T) {
+	links := []struct {
+		name, target string
+		hardlink     bool
+		escapes      bool
+	}{
+		{"lib/libfoo.so", "libfoo.so.1", false, false},
+		{"lib/libfoo.so.1", "libfoo.so.1.2", false, false},
+		{"d/up", "..", false, false},
+		{"d/up/lib-alias", "lib", false, false},
+		{"d/up/lib-alias/libbar.so", "../d/up/lib/libfoo.so", false, false},
+		{"d/up/escape", "../..", false, true},
+		{"d/hard", "d/up/lib/libfoo.so", true, false},
+		{"d/hard-escape", "d/up/../etc/passwd", true, true},
+		{"loop/a", "b", false, false},
+		{"loop/b", "a", false, false},
+		{"loop/c", "a/x", false, true},
+		{"outside", "../x", false, true},
+	}
+	var c LinkChecker
+	for _, l := range links {
+		err := c.Check(l.name, l.target, l.hardlink)
+		if l.escapes != (err != nil) || (err != nil && !errors.Is(err, ErrSymlinkEscape)) {
+			t.Errorf("Check(%q -> %q) = %v, want escape %v", l.name, l.target, err, l.escapes)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package pathfinder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/fulmenhq/crucible/errorsx"
+	"github.com/fulmenhq/crucible/foundry"
+	"github.com/fulmenhq/crucible/observability/metrics"
+)
+
+// ErrorCode is a pathfinder error code.
+// See: docs/standards/library/extensions/pathfinder.md#error-handling
+type ErrorCode string
+
+const (
+	CodeInvalidQuery     ErrorCode = "INVALID_QUERY"
+	CodeInvalidRoot      ErrorCode = "INVALID_ROOT"
+	CodeTraversalLoop    ErrorCode = "TRAVERSAL_LOOP"
+	CodePermissionDenied ErrorCode = "PERMISSION_DENIED"
+	CodeReadFailed       ErrorCode = "READ_FAILED"
+
+	// Path constraint violations and configuration errors.
+	CodeAbsolutePath      ErrorCode = "ABSOLUTE_PATH"
+	CodePathTraversal     ErrorCode = "PATH_TRAVERSAL"
+	CodeSymlinkEscape     ErrorCode = "SYMLINK_ESCAPE"
+	CodeBlockedPath       ErrorCode = "BLOCKED_PATH"
+	CodeInvalidPath       ErrorCode = "INVALID_PATH"
+	CodeInvalidConstraint ErrorCode = "INVALID_CONSTRAINT"
+)
+
+// Sentinel errors for use with errors.Is; they match any Error with the same code.
+var (
+	ErrInvalidQuery     = NewError(CodeInvalidQuery, "")
+	ErrInvalidRoot      = NewError(CodeInvalidRoot, "")
+	ErrTraversalLoop    = NewError(CodeTraversalLoop, "")
+	ErrPermissionDenied = NewError(CodePermissionDenied, "")
+	ErrReadFailed       = NewError(CodeReadFailed, "")
+
+	ErrAbsolutePath      = NewError(CodeAbsolutePath, "")
+	ErrPathTraversal     = NewError(CodePathTraversal, "")
+	ErrSymlinkEscape     = NewError(CodeSymlinkEscape, "")
+	ErrBlockedPath       = NewError(CodeBlockedPath, "")
+	ErrInvalidPath       = NewError(CodeInvalidPath, "")
+	ErrInvalidConstraint = NewError(CodeInvalidConstraint, "")
+)
+
+// Error is the Go form of the pathfinder error response.
+// See: schemas/pathfinder/v1.0.0/error-response.schema.json
+type Error struct {
+	errorsx.Coded[ErrorCode]
+	Path string // Path that caused the error, if applicable
+}
+
+// NewError creates an Error.
+func NewError(code ErrorCode, message string) *Error {
+	return &Error{Coded: errorsx.Coded[ErrorCode]{Code: code, Message: message}}
+}
+
+// WithPath sets the offending path and returns the error for chaining.
+func (e *Error) WithPath(path string) *Error {
+	e.Path = path
+	return e
+}
+
+// WithDetail sets a detail field and returns the error for chaining.
+func (e *Error) WithDetail(key string, value any) *Error {
+	e.SetDetail(key, value)
+	return e
+}
+
+// Wrap sets the underlying cause and returns the error for chaining.
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+func (e *Error) Error() string {
+	var note string
+	if e.Path != "" {
+		note = fmt.Sprintf("(path %q)", e.Path)
+	}
+	return e.Text("pathfinder", note)
+}
+
+// ExitCode maps the error onto a Foundry exit code.
+func (e *Error) ExitCode() int {
+	switch e.Code {
+	case CodeInvalidQuery:
+		return foundry.ExitInvalidArgument
+	case CodeInvalidRoot:
+		return foundry.ExitDirectoryNotFound
+	case CodePermissionDenied:
+		return foundry.ExitPermissionDenied
+	case CodeTraversalLoop, CodeInvalidPath:
+		return foundry.ExitDataInvalid
+	case CodeAbsolutePath, CodePathTraversal, CodeSymlinkEscape, CodeBlockedPath:
+		return foundry.ExitSecurityViolation
+	case CodeInvalidConstraint:
+		return foundry.ExitConfigInvalid
+	default:
+		return foundry.ExitFileReadError
+	}
+}
+
+// IsSecurityViolation reports whether the error is a path constraint
+// violation.
+func (e *Error) IsSecurityViolation() bool {
+	switch e.Code {
+	case CodeAbsolutePath, CodePathTraversal, CodeSymlinkEscape, CodeBlockedPath:
+		return true
+	default:
+		return false
+	}
+}
+
+// MarshalJSON renders the error as a pathfinder error response.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	type envelope struct {
+		Code    ErrorCode      `json:"code"`
+		Message string         `json:"message"`
+		Details map[string]any `json:"details,omitempty"`
+		Path    string         `json:"path,omitempty"`
+	}
+
+	msg := e.Message
+	if msg == "" {
+		msg = e.Error()
+	}
+	return json.Marshal(envelope{Code: e.Code, Message: msg, Details: e.Details, Path: e.Path})
+}
+
+// ioError classifies a filesystem error met while reading path.
+func ioError(err error, path string) *Error {
+	if errors.Is(err, fs.ErrPermission) {
+		return NewError(CodePermissionDenied, "permission denied").WithPath(path).Wrap(err)
+	}
+	return NewError(CodeReadFailed, "cannot read path").WithPath(path).Wrap(err)
+}
+
+// reportViolation counts an enforced constraint violation in
+// pathfinder_security_warnings on the default metrics registry, tagged with
+// its code and enforcement level.
+func reportViolation(v *Error, level EnforcementLevel) {
+	if c, err := metrics.Default().Counter(metrics.PathfinderSecurityWarnings); err == nil {
+		c.With(metrics.Tags{"code": string(v.Code), "level": string(level)}).Inc()
+	}
+}
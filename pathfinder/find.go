@@ -0,0 +1,283 @@
+package pathfinder
+
+import (
+	"context"
+	"io/fs"
+	"iter"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fulmenhq/crucible"
+)
+
+// Find walks q.Root and yields a PathResult for every file that matches the
+// query. Directories are descended in lexical order, so results are
+// deterministic.
+//
+// Include and Exclude patterns are matched against the slash-separated path
+// relative to the root; "**" matches any number of directories. With no
+// Include patterns every file matches. A directory matching an Exclude
+// pattern, or "<pattern>/**", is not descended. Names starting with "." are
+// skipped unless IncludeHidden is set. MaxDepth limits the number of
+// directories between the root and a result: 1 admits "a.txt" and
+// "dir/a.txt" but not "dir/sub/a.txt".
+//
+// Symlinks are reported like files and not followed unless FollowSymlinks is
+// set. When following, a link back to a directory already on the current
+// path is reported as a TRAVERSAL_LOOP error and skipped.
+//
+// An invalid query or root yields a single error. Errors met while walking,
+// such as an unreadable directory, are yielded alongside results and the
+// walk continues; the caller stops it by breaking out of the loop. When ctx
+// is cancelled the walk yields ctx.Err() and stops.
+//
+// Find uses a Finder with the zero FinderConfig.
+func Find(ctx context.Context, q FindQuery) iter.Seq2[PathResult, error] {
+	return defaultFinder.Find(ctx, q)
+}
+
+var defaultFinder = &Finder{}
+
+// Finder runs discovery operations under a FinderConfig. It is safe for
+// concurrent use.
+type Finder struct {
+	cfg        FinderConfig
+	constraint *Constraint
+}
+
+// NewFinder validates cfg and returns a Finder.
+func NewFinder(cfg FinderConfig) (*Finder, error) {
+	if err := crucible.ValidateSchemaValue(FinderConfigSchemaPath, cfg); err != nil {
+		return nil, NewError(CodeInvalidQuery, "invalid finder config").Wrap(err)
+	}
+	f := &Finder{cfg: cfg}
+	if cfg.Constraint != nil {
+		c, err := NewConstraint(*cfg.Constraint)
+		if err != nil {
+			return nil, err
+		}
+		f.constraint = c
+	}
+	return f, nil
+}
+
+// Find is like the package-level Find, with the Finder's configuration
+// applied.
+//
+// When the config has a Constraint, every directory is checked before it is
+// descended and every result before it is yielded; see Constraint.Evaluate.
+// Under strict enforcement a violating path is yielded as an error and
+// skipped, and a root outside the constraint yields a single error. Under
+// warn enforcement violations are counted in pathfinder_security_warnings
+// and the path is kept.
+//
+// With RespectGitignore, paths ignored by .git/info/exclude or by a
+// .gitignore file are skipped; ignored directories are not read. When the
+// root is inside a git repository, the .gitignore files of the directories
+// from the repository root down to the search root apply too, and a root
+// inside an ignored directory yields nothing. Later rules override earlier
+// ones and deeper files override their parents, as in git. The .git
+// directory itself is never walked.
+//
+// With IncludeMetadata or CalculateChecksums, every result carries Metadata;
+// see describe. Files are stat'ed and hashed by up to MaxWorkers goroutines
+// while the walk continues, and results are still yielded in walk order.
+func (f *Finder) Find(ctx context.Context, q FindQuery) iter.Seq2[PathResult, error] {
+	walk := func(yield func(PathResult, error) bool) {
+		w, err := newWalker(q, f)
+		if err != nil {
+			yield(PathResult{}, err)
+			return
+		}
+		w.run(ctx, yield)
+	}
+	if !f.cfg.IncludeMetadata && !f.cfg.CalculateChecksums {
+		return walk
+	}
+	return f.withMetadata(walk)
+}
+
+type walker struct {
+	q         FindQuery
+	root      string      // absolute root
+	c         *Constraint // nil when unconstrained
+	gitignore bool        // honor .gitignore files
+}
+
+func newWalker(q FindQuery, f *Finder) (*walker, *Error) {
+	if err := crucible.ValidateSchemaValue(FindQuerySchemaPath, q); err != nil {
+		return nil, NewError(CodeInvalidQuery, "invalid find query").Wrap(err)
+	}
+	if err := ValidatePatterns(slices.Concat(q.Include, q.Exclude)); err != nil {
+		return nil, NewError(CodeInvalidQuery, "malformed glob pattern").Wrap(err)
+	}
+	root, err := filepath.Abs(q.Root)
+	if err != nil {
+		return nil, NewError(CodeInvalidRoot, "cannot resolve root").WithPath(q.Root).Wrap(err)
+	}
+	return &walker{q: q, root: root, c: f.constraint, gitignore: f.cfg.RespectGitignore}, nil
+}
+
+func (w *walker) run(ctx context.Context, yield func(PathResult, error) bool) {
+	info, err := os.Stat(w.root)
+	if err != nil {
+		yield(PathResult{}, NewError(CodeInvalidRoot, "root does not exist").WithPath(w.q.Root).Wrap(err))
+		return
+	}
+	if !info.IsDir() {
+		yield(PathResult{}, NewError(CodeInvalidRoot, "root is not a directory").WithPath(w.q.Root))
+		return
+	}
+	if ok, err := w.admit(w.root); !ok {
+		yield(PathResult{}, err)
+		return
+	}
+	var ignores []*ignoreList
+	if w.gitignore {
+		var skip bool
+		ignores, skip, err = rootIgnores(w.root)
+		if (err != nil && !yield(PathResult{}, ioError(err, w.root))) || skip {
+			return
+		}
+	}
+	w.dir(ctx, w.root, "", []fs.FileInfo{info}, ignores, yield)
+}
+
+// dir walks the directory at abs, whose slash-separated path relative to the
+// root is rel ("" for the root itself). ancestors holds the directories on
+// the current path for loop detection and ignores the gitignore rules in
+// effect above it. It returns false once the walk must stop.
+func (w *walker) dir(ctx context.Context, abs, rel string, ancestors []fs.FileInfo, ignores []*ignoreList, yield func(PathResult, error) bool) bool {
+	if err := ctx.Err(); err != nil {
+		yield(PathResult{}, err)
+		return false
+	}
+	entries, err := os.ReadDir(abs)
+	if err != nil && !yield(PathResult{}, ioError(err, abs)) {
+		return false
+	}
+	if w.gitignore {
+		l, err := loadIgnore(filepath.Join(abs, ".gitignore"), rel)
+		if err != nil && !yield(PathResult{}, ioError(err, abs)) {
+			return false
+		}
+		if l != nil {
+			ignores = append(ignores, l)
+		}
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if !w.q.IncludeHidden && strings.HasPrefix(name, ".") {
+			continue
+		}
+		childAbs := filepath.Join(abs, name)
+		childRel := path.Join(rel, name)
+		if w.gitignore && name == ".git" {
+			continue
+		}
+		depth := strings.Count(childRel, "/")
+		if w.q.MaxDepth > 0 && depth > w.q.MaxDepth {
+			continue
+		}
+
+		typ := e.Type()
+		var info fs.FileInfo
+		if typ&fs.ModeSymlink != 0 && w.q.FollowSymlinks {
+			// A dangling link is reported as the link itself.
+			if info, err = os.Stat(childAbs); err == nil {
+				typ = info.Mode().Type()
+			}
+		}
+		// A followed link to a directory is a directory to directory-only
+		// rules such as "build/".
+		if w.gitignore && ignored(ignores, childRel, typ.IsDir()) {
+			continue
+		}
+
+		switch {
+		case typ.IsDir():
+			if prunes(w.q.Exclude, childRel) || (w.q.MaxDepth > 0 && depth >= w.q.MaxDepth) {
+				continue
+			}
+			if info == nil {
+				if info, err = e.Info(); err != nil {
+					if !yield(PathResult{}, ioError(err, childAbs)) {
+						return false
+					}
+					continue
+				}
+			}
+			if loopsBack(info, ancestors) {
+				loop := NewError(CodeTraversalLoop, "symlink cycles back to an ancestor directory").WithPath(childAbs)
+				if !yield(PathResult{}, loop) {
+					return false
+				}
+				continue
+			}
+			if ok, err := w.admit(childAbs); !ok {
+				if !yield(PathResult{}, err) {
+					return false
+				}
+				continue
+			}
+			if !w.dir(ctx, childAbs, childRel, append(ancestors, info), ignores, yield) {
+				return false
+			}
+		case typ.IsRegular() || typ&fs.ModeSymlink != 0:
+			if !w.matches(childRel) {
+				continue
+			}
+			if ok, err := w.admit(childAbs); !ok {
+				if !yield(PathResult{}, err) {
+					return false
+				}
+				continue
+			}
+			if !yield(w.result(childAbs, childRel), nil) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// admit applies the constraint, if any, to abs. It returns false with the
+// error to yield when the path must be skipped.
+func (w *walker) admit(abs string) (bool, error) {
+	if w.c == nil {
+		return true, nil
+	}
+	if err := w.c.Enforce(w.c.evaluateAbs(abs)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (w *walker) matches(rel string) bool {
+	if len(w.q.Include) > 0 && !MatchAny(w.q.Include, rel) {
+		return false
+	}
+	return !MatchAny(w.q.Exclude, rel)
+}
+
+func (w *walker) result(abs, rel string) PathResult {
+	return PathResult{
+		RelativePath: rel,
+		SourcePath:   abs,
+		LogicalPath:  rel,
+		LoaderType:   LoaderTypeLocal,
+	}
+}
+
+func loopsBack(info fs.FileInfo, ancestors []fs.FileInfo) bool {
+	for _, a := range ancestors {
+		if os.SameFile(info, a) {
+			return true
+		}
+	}
+	return false
+}
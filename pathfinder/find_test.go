@@ -0,0 +1,214 @@
+package pathfinder
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/fulmenhq/crucible"
+)
+
+// writeTree creates files (slash-separated path → content) under a temp root.
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// collect runs q and returns the relative paths found and errors yielded.
+func collect(t *testing.T, q FindQuery) ([]string, []error) {
+	t.Helper()
+	var paths []string
+	var errs []error
+	for res, err := range Find(context.Background(), q) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		paths = append(paths, res.RelativePath)
+	}
+	return paths, errs
+}
+
+var repoTree = map[string]string{
+	"README.md":                   "readme",
+	"main.go":                     "package main",
+	"internal/util/util.go":       "package util",
+	"internal/util/util_test.go":  "package util",
+	"node_modules/pkg/index.js":   "module.exports = {}",
+	".github/workflows/ci.yml":    "on: push",
+	"docs/.drafts/notes.md":       "draft",
+	"docs/guide/getting-start.md": "guide",
+}
+
+func TestFind(t *testing.T) {
+	root := writeTree(t, repoTree)
+
+	tests := []struct {
+		name string
+		q    FindQuery
+		want []string
+	}{
+		{"all visible", FindQuery{}, []string{
+			"README.md", "docs/guide/getting-start.md", "internal/util/util.go",
+			"internal/util/util_test.go", "main.go", "node_modules/pkg/index.js",
+		}},
+		{"include", FindQuery{Include: []string{"**/*.go"}}, []string{
+			"internal/util/util.go", "internal/util/util_test.go", "main.go",
+		}},
+		{"anchored include", FindQuery{Include: []string{"*.go"}}, []string{"main.go"}},
+		{"exclude", FindQuery{Include: []string{"**/*.go"}, Exclude: []string{"**/*_test.go"}}, []string{
+			"internal/util/util.go", "main.go",
+		}},
+		{"exclude directory", FindQuery{Exclude: []string{"node_modules/**", "docs"}}, []string{
+			"README.md", "internal/util/util.go", "internal/util/util_test.go", "main.go",
+		}},
+		{"max depth", FindQuery{MaxDepth: 1, Include: []string{"**/*.md"}}, []string{"README.md"}},
+		{"hidden", FindQuery{IncludeHidden: true, Include: []string{"**/*.md", "**/*.yml"}}, []string{
+			".github/workflows/ci.yml", "README.md", "docs/.drafts/notes.md", "docs/guide/getting-start.md",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.q.Root = root
+			got, errs := collect(t, tt.q)
+			if len(errs) != 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindResultShape(t *testing.T) {
+	root := writeTree(t, map[string]string{"a/b.txt": "b"})
+	for res, err := range Find(context.Background(), FindQuery{Root: root}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.RelativePath != "a/b.txt" || res.LogicalPath != "a/b.txt" || res.LoaderType != LoaderTypeLocal {
+			t.Errorf("unexpected result %+v", res)
+		}
+		if !filepath.IsAbs(res.SourcePath) || res.SourcePath != filepath.Join(root, "a", "b.txt") {
+			t.Errorf("sourcePath = %q", res.SourcePath)
+		}
+		if err := crucible.ValidateSchemaValue(PathResultSchemaPath, res); err != nil {
+			t.Errorf("result does not match schema: %v", err)
+		}
+	}
+}
+
+func TestFindSymlinks(t *testing.T) {
+	root := writeTree(t, map[string]string{"real/file.txt": "x"})
+	outside := writeTree(t, map[string]string{"shared.txt": "y"})
+	for link, target := range map[string]string{
+		"alias":     filepath.Join(root, "real"),
+		"real/loop": root,
+		"ext":       outside,
+		"file-link": filepath.Join(root, "real", "file.txt"),
+		"dangling":  filepath.Join(root, "missing"),
+	} {
+		if err := os.Symlink(target, filepath.Join(root, filepath.FromSlash(link))); err != nil {
+			t.Skipf("symlinks unsupported: %v", err)
+		}
+	}
+
+	got, errs := collect(t, FindQuery{Root: root})
+	want := []string{"alias", "dangling", "ext", "file-link", "real/file.txt", "real/loop"}
+	if len(errs) != 0 || !slices.Equal(got, want) {
+		t.Errorf("without following: got %v, %v; want %v", got, errs, want)
+	}
+
+	got, errs = collect(t, FindQuery{Root: root, FollowSymlinks: true})
+	want = []string{"alias/file.txt", "dangling", "ext/shared.txt", "file-link", "real/file.txt"}
+	if !slices.Equal(got, want) {
+		t.Errorf("following: got %v, want %v", got, want)
+	}
+	if len(errs) != 2 || !errors.Is(errs[0], ErrTraversalLoop) || !errors.Is(errs[1], ErrTraversalLoop) {
+		t.Errorf("expected a TRAVERSAL_LOOP error per path to real/loop, got %v", errs)
+	}
+}
+
+func TestFindInvalid(t *testing.T) {
+	file := filepath.Join(writeTree(t, map[string]string{"f": ""}), "f")
+	tests := []struct {
+		name string
+		q    FindQuery
+		want error
+	}{
+		{"empty root", FindQuery{}, ErrInvalidQuery},
+		{"negative depth", FindQuery{Root: ".", MaxDepth: -1}, ErrInvalidQuery},
+		{"bad pattern", FindQuery{Root: ".", Include: []string{"[a"}}, ErrInvalidQuery},
+		{"missing root", FindQuery{Root: filepath.Join(t.TempDir(), "missing")}, ErrInvalidRoot},
+		{"file root", FindQuery{Root: file}, ErrInvalidRoot},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := collect(t, tt.q)
+			if len(got) != 0 || len(errs) != 1 || !errors.Is(errs[0], tt.want) {
+				t.Errorf("got %v, %v; want a single %v", got, errs, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindStops(t *testing.T) {
+	root := writeTree(t, repoTree)
+	n := 0
+	for range Find(context.Background(), FindQuery{Root: root}) {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("iteration continued after break")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var errs []error
+	for _, err := range Find(ctx, FindQuery{Root: root}) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", errs)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "a/b/c.go", true},
+		{"*.go", "a/b.go", false},
+		{"src/**", "src/a/b", true},
+		{"src/**/test/*.go", "src/test/x.go", true},
+		{"a/*/c", "a/b/c", true},
+		{"a/*/c", "a/b/d/c", false},
+		{"*.md", "README.md", true},
+		{"*.md", "docs/guide.md", false},
+		{"**/__pycache__", "src/__pycache__", true},
+		{"src/**/b", "src/b", true},
+		{"**/*.py", "a.pyc", false},
+	}
+	for _, tt := range tests {
+		if got := MatchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
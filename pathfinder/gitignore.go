@@ -0,0 +1,204 @@
+package pathfinder
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule is one pattern line of a gitignore file.
+type ignoreRule struct {
+	pattern  string // glob relative to the ignore file's directory
+	negate   bool   // "!pattern" re-includes a path
+	dirOnly  bool   // "pattern/" matches directories only
+	anchored bool   // pattern contains a slash and matches from base only
+}
+
+// ignoreList holds the rules of one ignore file, which apply to paths below
+// base. A file above the search root applies to the whole search, with the
+// root at prefix relative to the file's directory.
+type ignoreList struct {
+	base   string // slash-separated directory relative to the search root, "" for the root
+	prefix string // for a file above the search root, the root relative to its directory
+	rules  []ignoreRule
+}
+
+// parseIgnore parses gitignore syntax: blank lines and "#" comments are
+// skipped, "!" negates, a trailing "/" restricts the pattern to directories
+// and a slash at the start or in the middle anchors it to base. A leading
+// backslash escapes "#" and "!"; trailing spaces are ignored unless escaped.
+func parseIgnore(base string, data []byte) *ignoreList {
+	l := &ignoreList{base: base}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		line = trimTrailingSpaces(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var r ignoreRule
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		r.anchored = strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		r.pattern = strings.ReplaceAll(line, "[!", "[^")
+		if ValidatePatterns([]string{r.pattern}) != nil {
+			continue // git ignores malformed patterns
+		}
+		l.rules = append(l.rules, r)
+	}
+	return l
+}
+
+// trimTrailingSpaces removes trailing spaces that are not escaped with a
+// backslash.
+func trimTrailingSpaces(s string) string {
+	for strings.HasSuffix(s, " ") && !strings.HasSuffix(s, `\ `) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// match reports whether the rule matches rel, a path relative to the
+// ignore file's directory.
+func (r ignoreRule) match(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	pattern := r.pattern
+	if !r.anchored {
+		pattern = "**/" + pattern
+	}
+	if !MatchGlob(pattern, rel) {
+		return false
+	}
+	// A trailing "/**" matches what is inside a directory, not the
+	// directory itself.
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok && MatchGlob(prefix, rel) {
+		return false
+	}
+	return true
+}
+
+// ignored reports whether rel, relative to the search root, is ignored by
+// lists, which are ordered from lowest to highest precedence. The last
+// matching rule wins, so a nested ignore file overrides its parents.
+func ignored(lists []*ignoreList, rel string, isDir bool) bool {
+	ign := false
+	for _, l := range lists {
+		sub := rel
+		switch {
+		case l.prefix != "":
+			sub = path.Join(l.prefix, rel)
+		case l.base != "":
+			var ok bool
+			if sub, ok = strings.CutPrefix(rel, l.base+"/"); !ok {
+				continue
+			}
+		}
+		for _, r := range l.rules {
+			if r.match(sub, isDir) {
+				ign = !r.negate
+			}
+		}
+	}
+	return ign
+}
+
+// loadIgnore reads the ignore file at name, if any, as a list for base.
+func loadIgnore(name, base string) (*ignoreList, error) {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseIgnore(base, data), nil
+}
+
+// rootIgnores returns the ignore lists that reach the search root from
+// outside it: .git/info/exclude of the enclosing repository, which has the
+// lowest precedence, then the .gitignore files from the repository root down
+// to the root's parent. It also reports whether the root lies inside an
+// ignored directory, in which case everything below it is ignored. Outside a
+// repository there are none.
+func rootIgnores(root string) ([]*ignoreList, bool, error) {
+	repo := repositoryRoot(root)
+	if repo == "" {
+		return nil, false, nil
+	}
+	var lists []*ignoreList
+	// .git is a file in worktrees and submodules; their exclude file lives
+	// elsewhere and is not consulted.
+	if info, err := os.Stat(filepath.Join(repo, ".git")); err == nil && info.IsDir() {
+		l, err := loadIgnore(filepath.Join(repo, ".git", "info", "exclude"), "")
+		if err != nil {
+			return nil, false, err
+		}
+		if l != nil {
+			lists = append(lists, l)
+		}
+	}
+	rel, err := filepath.Rel(repo, root)
+	if err != nil || rel == "." {
+		return lists, false, nil
+	}
+	rel = filepath.ToSlash(rel)
+
+	// Load each directory's file relative to the repository, checking the
+	// next directory down against the rules so far, as git prunes.
+	dir := ""
+	for _, seg := range strings.Split(rel, "/") {
+		l, err := loadIgnore(filepath.Join(repo, filepath.FromSlash(dir), ".gitignore"), dir)
+		if err != nil {
+			return nil, false, err
+		}
+		if l != nil {
+			lists = append(lists, l)
+		}
+		dir = path.Join(dir, seg)
+		if seg == ".git" || ignored(lists, dir, true) {
+			return nil, true, nil
+		}
+	}
+	for _, l := range lists {
+		l.prefix = rel
+		if l.base != "" {
+			l.prefix = strings.TrimPrefix(rel, l.base+"/")
+			l.base = ""
+		}
+	}
+	return lists, false, nil
+}
+
+// repositoryRoot returns the nearest of dir and its ancestors containing a
+// .git entry, or "" if there is none.
+func repositoryRoot(dir string) string {
+	for {
+		if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
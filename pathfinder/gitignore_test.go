@@ -0,0 +1,167 @@
+package pathfinder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	l := parseIgnore("", []byte(`# build output
+*.log
+!keep.log
+/bin
+build/
+docs/**/*.tmp
+vendor/**
+!vendor/modules.txt
+\#literal
+trailing
+`))
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"debug.log", false, true},
+		{"a/b/debug.log", false, true},
+		{"keep.log", false, false},
+		{"a/keep.log", false, false},
+		{"bin", true, true},
+		{"cmd/bin", true, false},
+		{"build", true, true},
+		{"src/build", true, true},
+		{"build", false, false},
+		{"docs/x.tmp", false, true},
+		{"docs/a/b/x.tmp", false, true},
+		{"x.tmp", false, false},
+		{"vendor", true, false},
+		{"vendor/lib/a.go", false, true},
+		{"vendor/modules.txt", false, false},
+		{"#literal", false, true},
+		{"trailing", false, true},
+	}
+	for _, tt := range tests {
+		if got := ignored([]*ignoreList{l}, tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("ignored(%q, dir=%v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestIgnoreNestedPrecedence(t *testing.T) {
+	lists := []*ignoreList{
+		parseIgnore("", []byte("*.gen.go\n")),
+		parseIgnore("api", []byte("!*.gen.go\n/local.txt\n")),
+	}
+	tests := []struct {
+		rel  string
+		want bool
+	}{
+		{"x.gen.go", true},
+		{"api/x.gen.go", false},
+		{"api/local.txt", true},
+		{"api/sub/local.txt", false},
+		{"local.txt", false},
+	}
+	for _, tt := range tests {
+		if got := ignored(lists, tt.rel, false); got != tt.want {
+			t.Errorf("ignored(%q) = %v, want %v", tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestFinderGitignore(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		".git/HEAD":               "ref: refs/heads/main",
+		".git/info/exclude":       "scratch.txt\n",
+		".gitignore":              "node_modules/\n*.log\ndist/**\n!dist/keep.txt\n",
+		"main.go":                 "",
+		"app.log":                 "",
+		"scratch.txt":             "",
+		"node_modules/pkg/a.js":   "",
+		"dist/bundle.js":          "",
+		"dist/keep.txt":           "",
+		"web/.gitignore":          "!important.log\n/generated\n",
+		"web/important.log":       "",
+		"web/other.log":           "",
+		"web/generated/out.js":    "",
+		"web/src/generated/in.js": "",
+	})
+	got := collectIgnoring(t, FindQuery{Root: root, IncludeHidden: true})
+	want := []string{
+		".gitignore", "dist/keep.txt", "main.go", "web/.gitignore", "web/important.log", "web/src/generated/in.js",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Without the option nothing is ignored.
+	paths, _ := collect(t, FindQuery{Root: root})
+	if !slices.Contains(paths, "node_modules/pkg/a.js") || !slices.Contains(paths, "app.log") {
+		t.Errorf("default Find should not apply gitignore rules, got %v", paths)
+	}
+}
+
+// collectIgnoring runs q with RespectGitignore and returns the relative
+// paths found.
+func collectIgnoring(t *testing.T, q FindQuery) []string {
+	t.Helper()
+	f, err := NewFinder(FinderConfig{RespectGitignore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for res, err := range f.Find(context.Background(), q) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, res.RelativePath)
+	}
+	return got
+}
+
+func TestFinderGitignoreAboveRoot(t *testing.T) {
+	repo := writeTree(t, map[string]string{
+		".git/HEAD":              "ref: refs/heads/main",
+		".git/info/exclude":      "scratch.txt\n",
+		".gitignore":             "*.log\n/web/src/gen/\nbuild/\n",
+		"web/.gitignore":         "!keep.log\n",
+		"web/src/main.go":        "",
+		"web/src/app.log":        "",
+		"web/src/keep.log":       "",
+		"web/src/scratch.txt":    "",
+		"web/src/gen/out.go":     "",
+		"web/src/sub/gen/in.go":  "",
+		"web/build/src/main.go":  "",
+		"web/src/build/a/out.go": "",
+	})
+
+	got := collectIgnoring(t, FindQuery{Root: filepath.Join(repo, "web", "src")})
+	want := []string{"keep.log", "main.go", "sub/gen/in.go"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// A root inside an ignored directory is ignored as a whole.
+	if got := collectIgnoring(t, FindQuery{Root: filepath.Join(repo, "web", "build", "src")}); len(got) != 0 {
+		t.Errorf("root in an ignored directory: got %v", got)
+	}
+}
+
+func TestFinderGitignoreSymlinkedDir(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		".gitignore":     "build/\n",
+		"out/result.txt": "",
+		"main.go":        "",
+	})
+	if err := os.Symlink(filepath.Join(root, "out"), filepath.Join(root, "build")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	got := collectIgnoring(t, FindQuery{Root: root, FollowSymlinks: true})
+	want := []string{"main.go", "out/result.txt"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
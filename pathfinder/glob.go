@@ -0,0 +1,75 @@
+package pathfinder
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchGlob reports whether the slash-separated relative path name matches
+// pattern. A "**" segment matches zero or more path segments; other segments
+// follow path.Match. Patterns are anchored at the root name is relative to,
+// so "*.go" matches only "main.go" and "**/*.go" matches at any depth.
+//
+// Find and fulpack's include and exclude patterns share these semantics.
+func MatchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// MatchAny reports whether name matches any of patterns.
+func MatchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if MatchGlob(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// prunes reports whether the directory dir is excluded as a whole by one of
+// patterns: either the pattern matches dir itself or it has the form
+// "<dir pattern>/**".
+func prunes(patterns []string, dir string) bool {
+	for _, p := range patterns {
+		if MatchGlob(p, dir) {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(p, "/**"); ok && MatchGlob(prefix, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePatterns checks that every segment of every pattern is well formed,
+// returning path.ErrBadPattern otherwise.
+func ValidatePatterns(patterns []string) error {
+	for _, p := range patterns {
+		for _, seg := range strings.Split(p, "/") {
+			if _, err := path.Match(seg, ""); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package pathfinder
+
+import (
+	"fmt"
+	"iter"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fulmenhq/crucible"
+	"github.com/fulmenhq/crucible/fulhash"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultMaxWorkers matches the maxWorkers default in finder-config.schema.json.
+const defaultMaxWorkers = 4
+
+// pending is a walk item travelling through the metadata pipeline. done is
+// closed once res (or err) is final.
+type pending struct {
+	res  PathResult
+	err  error
+	done chan struct{}
+}
+
+// withMetadata wraps walk so that every result is described by a bounded
+// pool of workers. Items enter a queue in walk order; the consumer waits for
+// each in turn, so the order is preserved while up to MaxWorkers files are
+// read concurrently.
+func (f *Finder) withMetadata(walk iter.Seq2[PathResult, error]) iter.Seq2[PathResult, error] {
+	workers := f.cfg.MaxWorkers
+	if workers <= 0 {
+		workers = defaultMaxWorkers
+	}
+	return func(yield func(PathResult, error) bool) {
+		stop := make(chan struct{})
+		work := make(chan *pending)
+		queue := make(chan *pending, workers)
+
+		var wg sync.WaitGroup
+		for range workers {
+			wg.Go(func() {
+				for p := range work {
+					p.res, p.err = f.describe(p.res)
+					close(p.done)
+				}
+			})
+		}
+
+		go func() {
+			defer close(queue)
+			defer close(work)
+			for res, err := range walk {
+				p := &pending{res: res, err: err, done: make(chan struct{})}
+				select {
+				case queue <- p:
+				case <-stop:
+					return
+				}
+				if err != nil {
+					close(p.done)
+					continue
+				}
+				select {
+				case work <- p:
+				case <-stop:
+					return
+				}
+			}
+		}()
+
+		defer func() {
+			close(stop)
+			for range queue {
+			}
+			wg.Wait()
+		}()
+
+		for p := range queue {
+			<-p.done
+			if !yield(p.res, p.err) {
+				return
+			}
+		}
+	}
+}
+
+// describe fills res.Metadata from the file at res.SourcePath: size,
+// modification time (RFC 3339, UTC), octal permissions and, when the
+// extension is in the Foundry MIME catalog, mimeType. Symlinks are described
+// by their target; a dangling link by the link itself. With
+// CalculateChecksums the file is hashed with FulHash; a hashing failure is
+// recorded in checksumError rather than failing the result. A file that can
+// no longer be stat'ed is returned as an error.
+func (f *Finder) describe(res PathResult) (PathResult, error) {
+	info, err := os.Stat(res.SourcePath)
+	if err != nil {
+		if info, err = os.Lstat(res.SourcePath); err != nil {
+			return PathResult{}, ioError(err, res.SourcePath)
+		}
+	}
+	size := info.Size()
+	md := &Metadata{
+		Size:        &size,
+		Modified:    info.ModTime().UTC().Format(time.RFC3339),
+		Permissions: fmt.Sprintf("%04o", info.Mode().Perm()),
+		MimeType:    mimeType(res.RelativePath),
+	}
+	if f.cfg.CalculateChecksums && info.Mode().IsRegular() {
+		alg := f.cfg.ChecksumAlgorithm
+		if alg == "" {
+			alg = fulhash.XXH3_128
+		}
+		if digest, err := hashFile(res.SourcePath, alg); err != nil {
+			md.ChecksumError = err.Error()
+		} else {
+			md.Checksum = digest.Formatted
+			md.ChecksumAlgorithm = string(alg)
+		}
+	}
+	res.Metadata = md
+	return res, nil
+}
+
+func hashFile(name string, alg fulhash.Algorithm) (fulhash.Digest, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return fulhash.Digest{}, err
+	}
+	defer file.Close()
+	return fulhash.HashReader(file, alg)
+}
+
+// mimeType returns the Foundry catalog MIME type for the extension of name,
+// or "" if the extension is not catalogued.
+func mimeType(name string) string {
+	ext := strings.TrimPrefix(path.Ext(name), ".")
+	if ext == "" {
+		return ""
+	}
+	return mimeTypes()[strings.ToLower(ext)]
+}
+
+// mimeTypes maps file extensions to MIME types from the embedded Foundry
+// catalog (config/library/foundry/mime-types.yaml).
+var mimeTypes = sync.OnceValue(func() map[string]string {
+	var catalog struct {
+		Types []struct {
+			MIME       string   `yaml:"mime"`
+			Extensions []string `yaml:"extensions"`
+		} `yaml:"types"`
+	}
+	byExt := map[string]string{}
+	data, err := crucible.ConfigRegistry.Library().Foundry().MIMETypes()
+	if err != nil || yaml.Unmarshal(data, &catalog) != nil {
+		return byExt
+	}
+	for _, t := range catalog.Types {
+		for _, ext := range t.Extensions {
+			byExt[strings.ToLower(ext)] = t.MIME
+		}
+	}
+	return byExt
+})
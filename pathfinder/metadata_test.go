@@ -0,0 +1,106 @@
+package pathfinder
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/fulmenhq/crucible"
+	"github.com/fulmenhq/crucible/fulhash"
+)
+
+func TestFinderMetadata(t *testing.T) {
+	files := map[string]string{"config.json": "{}", "notes.txt": "hello", "data.bin": "\x00\x01"}
+	for i := range 40 {
+		files[fmt.Sprintf("bulk/%02d.yaml", i)] = fmt.Sprintf("n: %d", i)
+	}
+	root := writeTree(t, files)
+	plain, _ := collect(t, FindQuery{Root: root})
+
+	for _, tt := range []struct {
+		name string
+		cfg  FinderConfig
+		alg  fulhash.Algorithm
+	}{
+		{"metadata only", FinderConfig{IncludeMetadata: true}, ""},
+		{"xxh3 single worker", FinderConfig{CalculateChecksums: true, MaxWorkers: 1}, fulhash.XXH3_128},
+		{"sha256", FinderConfig{CalculateChecksums: true, ChecksumAlgorithm: fulhash.SHA256, MaxWorkers: 8}, fulhash.SHA256},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFinder(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for res, err := range f.Find(context.Background(), FindQuery{Root: root}) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, res.RelativePath)
+				md := res.Metadata
+				if md == nil || md.Size == nil || *md.Size != int64(len(files[res.RelativePath])) {
+					t.Fatalf("%s: bad metadata %+v", res.RelativePath, md)
+				}
+				if md.Modified == "" || md.Permissions != "0644" {
+					t.Errorf("%s: modified %q, permissions %q", res.RelativePath, md.Modified, md.Permissions)
+				}
+				if tt.alg == "" && md.Checksum != "" {
+					t.Errorf("%s: unexpected checksum %q", res.RelativePath, md.Checksum)
+				}
+				if tt.alg != "" {
+					want, _ := fulhash.HashString(files[res.RelativePath], tt.alg)
+					if md.Checksum != want.Formatted || md.ChecksumAlgorithm != string(tt.alg) {
+						t.Errorf("%s: checksum %q (%s), want %q", res.RelativePath, md.Checksum, md.ChecksumAlgorithm, want.Formatted)
+					}
+				}
+				if err := crucible.ValidateSchemaValue(PathResultSchemaPath, res); err != nil {
+					t.Errorf("%s: result does not match schema: %v", res.RelativePath, err)
+				}
+			}
+			if !slices.Equal(got, plain) {
+				t.Errorf("order changed: got %v, want %v", got, plain)
+			}
+		})
+	}
+}
+
+func TestFinderMetadataStops(t *testing.T) {
+	root := writeTree(t, repoTree)
+	f, err := NewFinder(FinderConfig{CalculateChecksums: true, MaxWorkers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for range f.Find(context.Background(), FindQuery{Root: root}) {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("iteration continued after break")
+	}
+
+	// Query errors pass through the pipeline unchanged.
+	var errs []error
+	for _, err := range f.Find(context.Background(), FindQuery{}) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || errs[0] == nil {
+		t.Errorf("expected a single query error, got %v", errs)
+	}
+}
+
+func TestMimeType(t *testing.T) {
+	tests := map[string]string{
+		"a/b.json":  "application/json",
+		"c.YML":     "application/yaml",
+		"notes.txt": "text/plain",
+		"main.go":   "",
+		"Makefile":  "",
+	}
+	for name, want := range tests {
+		if got := mimeType(name); got != want {
+			t.Errorf("mimeType(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Package pathfinder discovers files under a root directory and reports them
+// as schema-shaped PathResult values.
+//
+// See: docs/standards/library/extensions/pathfinder.md
+package pathfinder
+
+import "github.com/fulmenhq/crucible/fulhash"
+
+// Embedded schemas describing the pathfinder data structures, relative to
+// schemas/ for use with crucible.ValidateSchemaValue.
+const (
+	FindQuerySchemaPath    = "pathfinder/v1.0.0/find-query.schema.json"
+	PathResultSchemaPath   = "pathfinder/v1.0.0/path-result.schema.json"
+	MetadataSchemaPath     = "pathfinder/v1.0.0/metadata.schema.json"
+	FinderConfigSchemaPath = "pathfinder/v1.0.0/finder-config.schema.json"
+)
+
+// LoaderType identifies the loader that produced a PathResult.
+type LoaderType string
+
+const (
+	LoaderTypeLocal  LoaderType = "local"
+	LoaderTypeRemote LoaderType = "remote"
+	LoaderTypeCloud  LoaderType = "cloud"
+)
+
+// FindQuery holds the parameters of a file discovery operation.
+// See: schemas/pathfinder/v1.0.0/find-query.schema.json
+type FindQuery struct {
+	Root           string   `json:"root"`                     // Root directory to search from
+	Include        []string `json:"include,omitempty"`        // Patterns to include in search
+	Exclude        []string `json:"exclude,omitempty"`        // Patterns to exclude from search
+	MaxDepth       int      `json:"maxDepth,omitempty"`       // Maximum directory depth (0 = unlimited)
+	FollowSymlinks bool     `json:"followSymlinks,omitempty"` // Whether to follow symbolic links
+	IncludeHidden  bool     `json:"includeHidden,omitempty"`  // Whether to include hidden files/directories
+}
+
+// FinderConfig configures a Finder.
+// See: schemas/pathfinder/v1.0.0/finder-config.schema.json
+type FinderConfig struct {
+	MaxWorkers         int               `json:"maxWorkers,omitempty"`         // Maximum number of metadata workers (0 = 4)
+	Constraint         *PathConstraint   `json:"constraint,omitempty"`         // Path constraint configuration
+	IncludeMetadata    bool              `json:"includeMetadata,omitempty"`    // Populate size, modified, permissions and mimeType
+	CalculateChecksums bool              `json:"calculateChecksums,omitempty"` // Populate a FulHash checksum (implies IncludeMetadata)
+	ChecksumAlgorithm  fulhash.Algorithm `json:"checksumAlgorithm,omitempty"`  // Checksum algorithm (default xxh3-128)
+	ChecksumEncoding   string            `json:"checksumEncoding,omitempty"`   // Checksum encoding (only "hex")
+	RespectGitignore   bool              `json:"respectGitignore,omitempty"`   // Skip paths ignored by .gitignore files and .git/info/exclude
+}
+
+// PathResult is a single file found by a discovery operation.
+// See: schemas/pathfinder/v1.0.0/path-result.schema.json
+type PathResult struct {
+	RelativePath string     `json:"relativePath"`          // Path relative to search root, slash-separated
+	SourcePath   string     `json:"sourcePath"`            // Absolute path to the file
+	LogicalPath  string     `json:"logicalPath,omitempty"` // Logical path for consumer (defaults to relativePath)
+	LoaderType   LoaderType `json:"loaderType"`            // Type of loader used
+	Metadata     *Metadata  `json:"metadata,omitempty"`    // Additional provider-specific information
+}
+
+// Metadata describes a discovered file.
+// See: schemas/pathfinder/v1.0.0/metadata.schema.json
+type Metadata struct {
+	Size              *int64         `json:"size,omitempty"`              // File size in bytes
+	Modified          string         `json:"modified,omitempty"`          // Last modification timestamp (RFC 3339)
+	Permissions       string         `json:"permissions,omitempty"`       // File permissions (octal)
+	MimeType          string         `json:"mimeType,omitempty"`          // MIME type of the file
+	Encoding          string         `json:"encoding,omitempty"`          // Character encoding if applicable
+	Checksum          string         `json:"checksum,omitempty"`          // FulHash checksum ("algorithm:hex")
+	ChecksumAlgorithm string         `json:"checksumAlgorithm,omitempty"` // Algorithm used for checksum calculation
+	ChecksumError     string         `json:"checksumError,omitempty"`     // Error message if checksum calculation failed
+	Tags              []string       `json:"tags,omitempty"`              // User-defined tags
+	Custom            map[string]any `json:"custom,omitempty"`            // Custom metadata fields
+}
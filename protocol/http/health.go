@@ -0,0 +1,208 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"sync"
+	"time"
+)
+
+// Status is a health status.
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// rank orders statuses from best to worst.
+func (s Status) rank() int {
+	switch s {
+	case StatusPass:
+		return 0
+	case StatusWarn:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Probe is a Kubernetes health probe.
+type Probe string
+
+const (
+	ProbeLive    Probe = "live"
+	ProbeReady   Probe = "ready"
+	ProbeStartup Probe = "startup"
+)
+
+// Path returns the endpoint path of the probe, such as /health/live.
+func (p Probe) Path() string { return "/health/" + string(p) }
+
+// Probes lists every probe.
+var Probes = []Probe{ProbeLive, ProbeReady, ProbeStartup}
+
+// DefaultCheckTimeout bounds a check registered without a timeout.
+const DefaultCheckTimeout = 5 * time.Second
+
+// CheckFunc reports the health of a component: nil passes, an error wrapped
+// by Degraded warns and any other error fails.
+type CheckFunc func(ctx context.Context) error
+
+// Degraded marks err as a warning rather than a failure.
+func Degraded(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &degradedError{err}
+}
+
+type degradedError struct{ err error }
+
+func (e *degradedError) Error() string { return e.err.Error() }
+func (e *degradedError) Unwrap() error { return e.err }
+
+// HealthResponse is the health endpoint payload.
+// See: schemas/protocol/http/v1.0.0/health-response.schema.json
+type HealthResponse struct {
+	Service       string        `json:"service,omitempty"`
+	Status        Status        `json:"status"`
+	Timestamp     string        `json:"timestamp"`
+	UptimeSeconds float64       `json:"uptimeSeconds"`
+	Checks        []CheckResult `json:"checks,omitempty"`
+}
+
+// CheckResult is the outcome of one component check.
+type CheckResult struct {
+	Name       string         `json:"name"`
+	Status     Status         `json:"status"`
+	ObservedAt string         `json:"observedAt"`
+	Details    map[string]any `json:"details,omitempty"`
+}
+
+type check struct {
+	name    string
+	timeout time.Duration
+	fn      CheckFunc
+}
+
+// Health serves the /health/live, /health/ready and /health/startup
+// endpoints from the checks registered for each probe. It is safe for
+// concurrent use.
+type Health struct {
+	service string
+	started time.Time
+	now     func() time.Time
+
+	mu     sync.RWMutex
+	checks map[Probe][]check
+}
+
+// NewHealth returns a Health for service, which may be empty. Uptime is
+// measured from the call.
+func NewHealth(service string) *Health {
+	return &Health{service: service, started: time.Now(), now: time.Now, checks: map[Probe][]check{}}
+}
+
+// AddCheck registers a check for probe. A check that does not return within
+// timeout (DefaultCheckTimeout when timeout is not positive) fails. AddCheck
+// panics if name is empty, which health-response.schema.json does not allow.
+func (h *Health) AddCheck(probe Probe, name string, timeout time.Duration, fn CheckFunc) {
+	if name == "" {
+		panic("http: health check with an empty name")
+	}
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[probe] = append(h.checks[probe], check{name: name, timeout: timeout, fn: fn})
+}
+
+// Check runs the checks of probe concurrently and returns the response. The
+// overall status is that of the worst check, or pass without checks.
+func (h *Health) Check(ctx context.Context, probe Probe) HealthResponse {
+	h.mu.RLock()
+	checks := h.checks[probe]
+	h.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Go(func() { results[i] = h.run(ctx, c) })
+	}
+	wg.Wait()
+
+	now := h.now()
+	resp := HealthResponse{
+		Service:       h.service,
+		Status:        StatusPass,
+		Timestamp:     now.UTC().Format(time.RFC3339Nano),
+		UptimeSeconds: max(now.Sub(h.started).Seconds(), 0),
+		Checks:        results,
+	}
+	for _, r := range results {
+		if r.Status.rank() > resp.Status.rank() {
+			resp.Status = r.Status
+		}
+	}
+	return resp
+}
+
+// run executes c under its timeout. A check that panics fails.
+func (h *Health) run(ctx context.Context, c check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("panic: %v", p)
+			}
+		}()
+		done <- c.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("check timed out after %s", c.timeout)
+	}
+
+	res := CheckResult{Name: c.name, Status: StatusPass, ObservedAt: h.now().UTC().Format(time.RFC3339Nano)}
+	if err != nil {
+		res.Status = StatusFail
+		var degraded *degradedError
+		if errors.As(err, &degraded) {
+			res.Status = StatusWarn
+		}
+		res.Details = map[string]any{"error": err.Error()}
+	}
+	return res
+}
+
+// Handler returns the handler for probe. It responds 200 when the probe
+// passes or warns and 503 when it fails.
+func (h *Health) Handler(probe Probe) nethttp.Handler {
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		resp := h.Check(r.Context(), probe)
+		status := nethttp.StatusOK
+		if resp.Status == StatusFail {
+			status = nethttp.StatusServiceUnavailable
+		}
+		traceIDs(w, r)
+		writeJSON(w, status, resp)
+	})
+}
+
+// Mount registers the handler of every probe on mux at its path.
+func (h *Health) Mount(mux *nethttp.ServeMux) {
+	for _, p := range Probes {
+		mux.Handle("GET "+p.Path(), h.Handler(p))
+	}
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealth(t *testing.T) {
+	h := NewHealth("crucible-test")
+	h.AddCheck(ProbeReady, "database", 0, func(context.Context) error { return nil })
+	h.AddCheck(ProbeReady, "queue", 0, func(context.Context) error { return Degraded(errors.New("lag 125")) })
+	h.AddCheck(ProbeStartup, "cache", 10*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	h.AddCheck(ProbeStartup, "migrations", 0, func(context.Context) error { panic("boom") })
+	mux := nethttp.NewServeMux()
+	h.Mount(mux)
+
+	cases := []struct {
+		probe  Probe
+		status int
+		health Status
+		checks map[string]Status
+	}{
+		{ProbeLive, nethttp.StatusOK, StatusPass, map[string]Status{}},
+		{ProbeReady, nethttp.StatusOK, StatusWarn, map[string]Status{"database": StatusPass, "queue": StatusWarn}},
+		{ProbeStartup, nethttp.StatusServiceUnavailable, StatusFail, map[string]Status{"cache": StatusFail, "migrations": StatusFail}},
+	}
+	for _, c := range cases {
+		t.Run(string(c.probe), func(t *testing.T) {
+			rec, body := serve(t, mux, httptest.NewRequest(nethttp.MethodGet, c.probe.Path(), nil), HealthSchemaPath)
+			if rec.Code != c.status || body["status"] != string(c.health) || body["service"] != "crucible-test" {
+				t.Errorf("status %d, body %v", rec.Code, body)
+			}
+			checks, _ := body["checks"].([]any)
+			if len(checks) != len(c.checks) {
+				t.Fatalf("checks %v", checks)
+			}
+			for _, v := range checks {
+				ch := v.(map[string]any)
+				if want := c.checks[ch["name"].(string)]; ch["status"] != string(want) {
+					t.Errorf("check %v, want %s", ch, want)
+				}
+				if ch["status"] != string(StatusPass) && ch["details"].(map[string]any)["error"] == nil {
+					t.Errorf("check %v has no error detail", ch)
+				}
+			}
+		})
+	}
+}
+
+func TestAddCheckEmptyName(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for an empty check name")
+		}
+	}()
+	NewHealth("crucible-test").AddCheck(ProbeLive, "", 0, func(context.Context) error { return nil })
+}
@@ -0,0 +1,234 @@
+// Package http implements the Fulmen HTTP REST conventions for servers:
+// health and version endpoints and the success and error envelopes, each
+// valid against its schema under schemas/protocol/http/v1.0.0/.
+//
+// See: docs/standards/protocol/http-rest-standards.md
+package http
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	nethttp "net/http"
+	"time"
+
+	"github.com/fulmenhq/crucible/errorsx"
+	"github.com/fulmenhq/crucible/foundry"
+)
+
+// Schema paths for crucible.ValidateSchemaData.
+const (
+	HealthSchemaPath  = "protocol/http/v1.0.0/health-response.schema.json"
+	VersionSchemaPath = "protocol/http/v1.0.0/version-response.schema.json"
+	SuccessSchemaPath = "protocol/http/v1.0.0/success-response.schema.json"
+	ErrorSchemaPath   = "protocol/http/v1.0.0/error-response.schema.json"
+)
+
+// Traceability headers, echoed from the request or generated.
+const (
+	RequestIDHeader     = "X-Request-ID"
+	CorrelationIDHeader = "X-Correlation-ID"
+)
+
+// ContentType is the content type of every JSON response.
+const ContentType = "application/json; charset=utf-8"
+
+// SuccessResponse is the success envelope.
+// See: schemas/protocol/http/v1.0.0/success-response.schema.json
+type SuccessResponse struct {
+	Success       bool   `json:"success"`
+	Message       string `json:"message,omitempty"`
+	Data          any    `json:"data,omitempty"`
+	RequestID     string `json:"requestId,omitempty"`
+	CorrelationID string `json:"correlationId,omitempty"`
+}
+
+// ErrorResponse is the error envelope.
+// See: schemas/protocol/http/v1.0.0/error-response.schema.json
+type ErrorResponse struct {
+	Success       bool        `json:"success"`
+	Error         ErrorDetail `json:"error"`
+	RequestID     string      `json:"requestId,omitempty"`
+	CorrelationID string      `json:"correlationId,omitempty"`
+	Timestamp     string      `json:"timestamp,omitempty"`
+}
+
+// ErrorDetail is the error member of an ErrorResponse.
+type ErrorDetail struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// Error is an API error with the HTTP status it is served with.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Details map[string]any
+}
+
+// NewError returns an API error. Statuses outside 4xx and 5xx are served as
+// 500, since errors must not be reported with a success status.
+func NewError(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// WithDetail sets a detail field.
+func (e *Error) WithDetail(key string, value any) *Error {
+	if e.Details == nil {
+		e.Details = map[string]any{}
+	}
+	e.Details[key] = value
+	return e
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// WriteSuccess writes a success envelope with status, which defaults to 200
+// when it is not 2xx. Data may be nil.
+func WriteSuccess(w nethttp.ResponseWriter, r *nethttp.Request, status int, message string, data any) error {
+	if status < 200 || status > 299 {
+		status = nethttp.StatusOK
+	}
+	requestID, correlationID := traceIDs(w, r)
+	return writeJSON(w, status, SuccessResponse{
+		Success:       true,
+		Message:       message,
+		Data:          data,
+		RequestID:     requestID,
+		CorrelationID: correlationID,
+	})
+}
+
+// WriteError writes an error envelope for err.
+//
+// An *Error is served with its status, code, message and details. An
+// *errorsx.Error is served with its code and details and a status derived
+// from its exit code by StatusForExitCode; its message is served only when
+// set with errorsx.WithMessage, since by default it is the wrapped error's
+// text, and the status text is served instead. Any other error is served as
+// a 500 internal_error without its message. Internal details are thus not
+// leaked to clients.
+func WriteError(w nethttp.ResponseWriter, r *nethttp.Request, err error) error {
+	status := nethttp.StatusInternalServerError
+	detail := ErrorDetail{Code: "internal_error", Message: nethttp.StatusText(status)}
+	var apiErr *Error
+	var envErr *errorsx.Error
+	switch {
+	case errors.As(err, &apiErr):
+		if apiErr.Status >= 400 && apiErr.Status <= 599 {
+			status = apiErr.Status
+		}
+		detail = ErrorDetail{Code: apiErr.Code, Message: apiErr.Message, Details: apiErr.Details}
+	case errors.As(err, &envErr):
+		status = StatusForExitCode(envErr.ExitCode())
+		detail = ErrorDetail{Code: envErr.Code, Message: envErr.Message, Details: envErr.Details}
+		if envErr.Err != nil && envErr.Message == envErr.Err.Error() {
+			detail.Message = ""
+		}
+	}
+	if detail.Code == "" {
+		detail.Code = "error"
+	}
+	if detail.Message == "" {
+		detail.Message = nethttp.StatusText(status)
+	}
+
+	requestID, correlationID := traceIDs(w, r)
+	return writeJSON(w, status, ErrorResponse{
+		Error:         detail,
+		RequestID:     requestID,
+		CorrelationID: correlationID,
+		Timestamp:     time.Now().UTC().Format(time.RFC3339Nano),
+	})
+}
+
+// StatusForExitCode maps a Foundry exit code onto the HTTP status an API
+// reports it with: usage and data errors are client errors, missing files
+// are 404, permission and security failures 401 or 403, timeouts 504,
+// unavailable dependencies 503, and everything else 500.
+func StatusForExitCode(code int) int {
+	switch code {
+	case foundry.ExitInvalidArgument, foundry.ExitMissingRequiredArgument, foundry.ExitUsage, foundry.ExitParseError:
+		return nethttp.StatusBadRequest
+	case foundry.ExitDataInvalid, foundry.ExitDataCorrupt:
+		return nethttp.StatusUnprocessableEntity
+	case foundry.ExitFileNotFound, foundry.ExitDirectoryNotFound, foundry.ExitNotFound:
+		return nethttp.StatusNotFound
+	case foundry.ExitAuthenticationFailed, foundry.ExitCertificateInvalid:
+		return nethttp.StatusUnauthorized
+	case foundry.ExitPermissionDenied, foundry.ExitAuthorizationFailed, foundry.ExitSecurityViolation:
+		return nethttp.StatusForbidden
+	case foundry.ExitConnectionTimeout, foundry.ExitOperationTimeout, foundry.ExitTimeout:
+		return nethttp.StatusGatewayTimeout
+	case foundry.ExitHealthCheckFailed, foundry.ExitDatabaseUnavailable, foundry.ExitExternalServiceUnavailable,
+		foundry.ExitResourceExhausted, foundry.ExitNetworkUnreachable, foundry.ExitConnectionRefused:
+		return nethttp.StatusServiceUnavailable
+	default:
+		return nethttp.StatusInternalServerError
+	}
+}
+
+// writeJSON writes v as a JSON response that must not be cached.
+func writeJSON(w nethttp.ResponseWriter, status int, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	h := w.Header()
+	h.Set("Content-Type", ContentType)
+	h.Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// traceIDs returns the request and correlation identifiers of r, generating
+// any that are missing, and sets them as response headers.
+func traceIDs(w nethttp.ResponseWriter, r *nethttp.Request) (requestID, correlationID string) {
+	if r != nil {
+		requestID = r.Header.Get(RequestIDHeader)
+		correlationID = r.Header.Get(CorrelationIDHeader)
+	}
+	if requestID == "" {
+		requestID = w.Header().Get(RequestIDHeader)
+	}
+	if correlationID == "" {
+		correlationID = w.Header().Get(CorrelationIDHeader)
+	}
+	if requestID == "" {
+		requestID = NewID()
+	}
+	if correlationID == "" {
+		correlationID = NewID()
+	}
+	w.Header().Set(RequestIDHeader, requestID)
+	w.Header().Set(CorrelationIDHeader, correlationID)
+	return requestID, correlationID
+}
+
+// NewID returns a UUIDv7, the identifier format the logging standard
+// requires for correlation IDs.
+func NewID() string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16)
+	rand.Read(b[6:])
+	b[6] = b[6]&0x0f | 0x70
+	b[8] = b[8]&0x3f | 0x80
+	var out [36]byte
+	hex.Encode(out[0:8], b[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], b[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], b[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], b[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:], b[10:])
+	return string(out[:])
+}
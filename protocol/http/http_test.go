@@ -0,0 +1,124 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/fulmenhq/crucible"
+	"github.com/fulmenhq/crucible/errorsx"
+	"github.com/fulmenhq/crucible/foundry"
+)
+
+// serve runs h and checks the response against the schema at path.
+func serve(t *testing.T, h nethttp.Handler, req *nethttp.Request, path string) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("content type %q", ct)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("cache control %q", cc)
+	}
+	if err := crucible.ValidateSchemaData(path, rec.Body.Bytes()); err != nil {
+		t.Errorf("response does not match %s: %v\n%s", path, err, rec.Body)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	return rec, body
+}
+
+func TestExamples(t *testing.T) {
+	for name, path := range map[string]string{
+		"health-response":  HealthSchemaPath,
+		"version-response": VersionSchemaPath,
+		"success-response": SuccessSchemaPath,
+		"error-response":   ErrorSchemaPath,
+	} {
+		data, err := os.ReadFile(filepath.Join("..", "..", "examples", "protocol", "http", "v1.0.0", name+".example.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := crucible.ValidateSchemaData(path, data); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestWriteSuccess(t *testing.T) {
+	req := httptest.NewRequest(nethttp.MethodPost, "/api/v1/jobs", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	rec, body := serve(t, nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		WriteSuccess(w, r, nethttp.StatusCreated, "Job created", map[string]any{"id": "job-1"})
+	}), req, SuccessSchemaPath)
+	if rec.Code != nethttp.StatusCreated || body["message"] != "Job created" || body["requestId"] != "req-1" {
+		t.Errorf("status %d, body %v", rec.Code, body)
+	}
+	if got := rec.Header().Get(RequestIDHeader); got != "req-1" {
+		t.Errorf("request ID header %q", got)
+	}
+	uuidV7 := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if id := rec.Header().Get(CorrelationIDHeader); !uuidV7.MatchString(id) || body["correlationId"] != id {
+		t.Errorf("generated correlation ID %q, body %v", id, body["correlationId"])
+	}
+
+	rec, _ = serve(t, nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		WriteSuccess(w, r, nethttp.StatusNotFound, "", nil)
+	}), httptest.NewRequest(nethttp.MethodGet, "/", nil), SuccessSchemaPath)
+	if rec.Code != nethttp.StatusOK {
+		t.Errorf("success written with status %d", rec.Code)
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	cases := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{"api error", NewError(nethttp.StatusUnprocessableEntity, "invalid_payload", "Bad email").WithDetail("pointer", "/data/email"), 422, "invalid_payload", "Bad email"},
+		{"wrapped api error", fmt.Errorf("handler: %w", NewError(nethttp.StatusNotFound, "not_found", "No such job")), 404, "not_found", "No such job"},
+		{"success status", NewError(nethttp.StatusOK, "odd", "Odd"), 500, "odd", "Odd"},
+		{"envelope", errorsx.Wrap(errors.New("open /srv/data/x.db: no such file"), "file_missing", errorsx.WithExitCode(foundry.ExitFileNotFound)), 404, "file_missing", "Not Found"},
+		{"envelope message", errorsx.Wrap(errors.New("open /srv/data/x.db: no such file"), "file_missing", errorsx.WithMessage("No such report")), 500, "file_missing", "No such report"},
+		{"plain error", errors.New("db password is hunter2"), 500, "internal_error", "Internal Server Error"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(nethttp.MethodGet, "/", nil)
+			req.Header.Set(CorrelationIDHeader, "corr-1")
+			rec, body := serve(t, nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				WriteError(w, r, c.err)
+			}), req, ErrorSchemaPath)
+			detail, _ := body["error"].(map[string]any)
+			if rec.Code != c.status || detail["code"] != c.code || body["correlationId"] != "corr-1" {
+				t.Errorf("status %d, body %v", rec.Code, body)
+			}
+			if detail["message"] != c.message {
+				t.Errorf("message %q, want %q", detail["message"], c.message)
+			}
+		})
+	}
+}
+
+func TestVersionHandler(t *testing.T) {
+	info := BuildVersion()
+	if info.Language != "go" || info.Metadata["goVersion"] == nil {
+		t.Errorf("build version %+v", info)
+	}
+	rec, body := serve(t, VersionHandler(info), httptest.NewRequest(nethttp.MethodGet, "/version", nil), VersionSchemaPath)
+	if rec.Code != nethttp.StatusOK || body["data"].(map[string]any)["commit"] != info.Commit {
+		t.Errorf("status %d, body %v", rec.Code, body)
+	}
+}
@@ -0,0 +1,299 @@
+package http
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	nethttp "net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fulmenhq/crucible/observability/logging"
+	"github.com/fulmenhq/crucible/observability/metrics"
+)
+
+// Middleware wraps an HTTP handler.
+type Middleware func(next nethttp.Handler) nethttp.Handler
+
+// FieldProvider is the name under which the package registers a logging
+// field provider adding the requestId and correlationId of the request in
+// the context, for augmentation middleware listing it in dynamic fields.
+const FieldProvider = "http_request"
+
+func init() {
+	logging.RegisterFieldProvider(FieldProvider, func(ctx context.Context) []slog.Attr {
+		var attrs []slog.Attr
+		if id := RequestID(ctx); id != "" {
+			attrs = append(attrs, slog.String("requestId", id))
+		}
+		if id := CorrelationID(ctx); id != "" {
+			attrs = append(attrs, slog.String("correlationId", id))
+		}
+		return attrs
+	})
+}
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	correlationIDKey
+	loggerKey
+)
+
+// RequestID returns the request identifier the middleware stored in ctx.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// CorrelationID returns the correlation identifier the middleware stored in
+// ctx.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey).(string)
+	return id
+}
+
+// Logger returns the request logger the middleware stored in ctx, which
+// carries the request and correlation identifiers, or slog.Default().
+func Logger(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// MiddlewareConfig configures NewMiddleware.
+type MiddlewareConfig struct {
+	Service  string            // service label of every metric
+	Registry *metrics.Registry // defaults to metrics.Default()
+	Logger   *slog.Logger      // defaults to slog.Default()
+
+	// Route returns the templated route of a served request. It defaults to
+	// the pattern net/http.ServeMux matched, without its method and host,
+	// or "unmatched".
+	Route func(r *nethttp.Request) string
+}
+
+// NewMiddleware returns middleware recording the request telemetry every
+// Fulmen service shares.
+//
+// Each request gets the request and correlation identifiers of its
+// X-Request-ID and X-Correlation-ID headers, or new ones, echoed as response
+// headers and stored in the context, where RequestID, CorrelationID and
+// Logger return them and WriteSuccess and WriteError put them in envelopes.
+// Header values are only kept when they are 1 to 128 ASCII letters, digits,
+// '.', '_' or '-'; anything else is replaced by a new identifier.
+// When the handler returns, the request is counted in http_requests_total,
+// timed in http_request_duration_seconds and measured in
+// http_request_size_bytes and http_response_size_bytes, with the method,
+// route, status and service labels, plus outcome: the status group from the
+// Foundry HTTP status catalog (StatusGroup). Methods other than the standard
+// ones are labelled OTHER, so clients cannot create series at will.
+// http_active_requests tracks requests in progress. A completion event is
+// logged at INFO, WARN for client errors or ERROR for server errors.
+//
+// It fails when the registry already registers an HTTP metric as another
+// kind.
+func NewMiddleware(cfg MiddlewareConfig) (Middleware, error) {
+	reg := cfg.Registry
+	if reg == nil {
+		reg = metrics.Default()
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	route := cfg.Route
+	if route == nil {
+		route = matchedRoute
+	}
+	service := metrics.Tags{"service": cfg.Service}
+
+	requests, err := reg.Counter(metrics.HTTPRequestsTotal)
+	if err != nil {
+		return nil, err
+	}
+	duration, err := reg.Histogram(metrics.HTTPRequestDurationSeconds)
+	if err != nil {
+		return nil, err
+	}
+	requestSize, err := reg.Histogram(metrics.HTTPRequestSizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	responseSize, err := reg.Histogram(metrics.HTTPResponseSizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	active, err := reg.Gauge(metrics.HTTPActiveRequests)
+	if err != nil {
+		return nil, err
+	}
+	active = active.With(service)
+
+	return func(next nethttp.Handler) nethttp.Handler {
+		return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			start := time.Now()
+			active.Inc()
+			defer active.Dec()
+
+			requestID := headerOrNewID(r, RequestIDHeader)
+			correlationID := headerOrNewID(r, CorrelationIDHeader)
+			w.Header().Set(RequestIDHeader, requestID)
+			w.Header().Set(CorrelationIDHeader, correlationID)
+			log := logger.With("requestId", requestID, "correlationId", correlationID)
+			ctx := context.WithValue(r.Context(), requestIDKey, requestID)
+			ctx = context.WithValue(ctx, correlationIDKey, correlationID)
+			ctx = context.WithValue(ctx, loggerKey, log)
+			r = r.WithContext(ctx)
+
+			body := &countingBody{ReadCloser: r.Body}
+			if r.Body != nil && r.Body != nethttp.NoBody {
+				r.Body = body
+			}
+			rec := &recorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+
+			elapsed := time.Since(start)
+			status := rec.status
+			if status == 0 {
+				status = nethttp.StatusOK
+			}
+			in := max(body.n, r.ContentLength)
+			tags := metrics.Tags{
+				"method":  metricMethod(r.Method),
+				"route":   route(r),
+				"status":  strconv.Itoa(status),
+				"service": cfg.Service,
+				"outcome": StatusGroup(status),
+			}
+			requests.With(tags).Inc()
+			duration.With(tags).ObserveDuration(elapsed)
+			responseSize.With(tags).Observe(float64(rec.bytes))
+			requestSize.With(metrics.Tags{"method": tags["method"], "route": tags["route"], "service": cfg.Service}).Observe(float64(max(in, 0)))
+
+			level := slog.LevelInfo
+			switch {
+			case status >= 500:
+				level = slog.LevelError
+			case status >= 400:
+				level = slog.LevelWarn
+			}
+			log.LogAttrs(ctx, level, "http request",
+				slog.String("operation", r.Method+" "+tags["route"]),
+				slog.Duration("durationMs", elapsed),
+				slog.String("method", r.Method),
+				slog.String("route", tags["route"]),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.String("outcome", tags["outcome"]),
+				slog.Int64("requestBytes", max(in, 0)),
+				slog.Int64("responseBytes", rec.bytes),
+			)
+		})
+	}, nil
+}
+
+// maxIDLength bounds client-supplied request and correlation identifiers.
+const maxIDLength = 128
+
+func headerOrNewID(r *nethttp.Request, header string) string {
+	if id := r.Header.Get(header); validID(id) {
+		return id
+	}
+	return NewID()
+}
+
+// validID reports whether id is 1 to maxIDLength characters from
+// [A-Za-z0-9._-], which covers UUIDs and the usual trace identifiers.
+func validID(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+	for _, c := range []byte(id) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '.', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// matchedRoute returns the ServeMux pattern of r without its method and
+// host, such as /users/{id}.
+func matchedRoute(r *nethttp.Request) string {
+	p := r.Pattern
+	if p == "" {
+		return "unmatched"
+	}
+	if i := strings.IndexByte(p, ' '); i >= 0 {
+		p = strings.TrimLeft(p[i+1:], " \t")
+	}
+	if i := strings.IndexByte(p, '/'); i > 0 {
+		p = p[i:]
+	}
+	return p
+}
+
+// metricMethod returns method for the standard HTTP methods and OTHER for
+// anything else.
+func metricMethod(method string) string {
+	switch method {
+	case nethttp.MethodGet, nethttp.MethodHead, nethttp.MethodPost, nethttp.MethodPut,
+		nethttp.MethodPatch, nethttp.MethodDelete, nethttp.MethodConnect,
+		nethttp.MethodOptions, nethttp.MethodTrace:
+		return method
+	}
+	return "OTHER"
+}
+
+// recorder captures the status and body size of a response.
+type recorder struct {
+	nethttp.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *recorder) WriteHeader(status int) {
+	if w.status == 0 && status >= 200 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recorder) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = nethttp.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush sends buffered data to the client, so streaming handlers asserting
+// http.Flusher keep working. It does nothing when the wrapped writer cannot
+// flush.
+func (w *recorder) Flush() {
+	if w.status == 0 {
+		w.status = nethttp.StatusOK
+	}
+	nethttp.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap gives http.ResponseController access to the wrapped writer.
+func (w *recorder) Unwrap() nethttp.ResponseWriter { return w.ResponseWriter }
+
+// countingBody counts the bytes read from a request body.
+type countingBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
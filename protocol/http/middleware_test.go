@@ -0,0 +1,149 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fulmenhq/crucible/observability/metrics"
+)
+
+func TestStatusGroup(t *testing.T) {
+	for status, want := range map[int]string{200: "success", 201: "success", 299: "success", 404: "client-error", 503: "server-error", 302: "redirect", 799: "7xx"} {
+		if got := StatusGroup(status); got != want {
+			t.Errorf("StatusGroup(%d) = %s, want %s", status, got, want)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	reg := metrics.NewRegistry()
+	var logs bytes.Buffer
+	mw, err := NewMiddleware(MiddlewareConfig{
+		Service:  "jobs",
+		Registry: reg,
+		Logger:   slog.New(slog.NewJSONHandler(&logs, nil)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := nethttp.NewServeMux()
+	mux.HandleFunc("POST /jobs/{id}", func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		io.Copy(io.Discard, r.Body)
+		if RequestID(r.Context()) == "" || CorrelationID(r.Context()) != "corr-1" {
+			t.Errorf("IDs missing from context")
+		}
+		Logger(r.Context()).Info("handling")
+		WriteError(w, r, NewError(nethttp.StatusNotFound, "not_found", "No such job"))
+	})
+	handler := mw(mux)
+
+	req := httptest.NewRequest(nethttp.MethodPost, "/jobs/42", strings.NewReader(`{"name":"x"}`))
+	req.Header.Set(CorrelationIDHeader, "corr-1")
+	rec, body := serve(t, handler, req, ErrorSchemaPath)
+	if body["correlationId"] != "corr-1" || body["requestId"] != rec.Header().Get(RequestIDHeader) || body["requestId"] == "" {
+		t.Errorf("envelope IDs %v, headers %v", body, rec.Header())
+	}
+
+	written := rec.Body.Len()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(nethttp.MethodGet, "/nowhere", nil))
+
+	got := map[string]metrics.Event{}
+	for _, ev := range reg.Export() {
+		got[string(ev.Name)+" "+ev.Tags["route"]+" "+ev.Tags["status"]] = ev
+	}
+	req404 := got["http_requests_total /jobs/{id} 404"]
+	if req404.Value != 1.0 || req404.Tags["method"] != "POST" || req404.Tags["service"] != "jobs" || req404.Tags["outcome"] != "client-error" {
+		t.Errorf("request counter %+v", req404)
+	}
+	if got["http_requests_total unmatched 404"].Value != 1.0 {
+		t.Errorf("unmatched request not counted: %v", got)
+	}
+	if hv := got["http_request_size_bytes /jobs/{id} "].Value.(*metrics.HistogramValue); hv.Count != 1 || hv.Sum != 12 {
+		t.Errorf("request size %+v", hv)
+	}
+	if hv := got["http_response_size_bytes /jobs/{id} 404"].Value.(*metrics.HistogramValue); hv.Sum != float64(written) {
+		t.Errorf("response size %+v", hv)
+	}
+	if hv := got["http_request_duration_seconds /jobs/{id} 404"].Value.(*metrics.HistogramValue); hv.Count != 1 {
+		t.Errorf("duration %+v", hv)
+	}
+	if got["http_active_requests  "].Value != 0.0 {
+		t.Errorf("active requests %+v", got["http_active_requests  "])
+	}
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("log lines:\n%s", logs.String())
+	}
+	var handling, done map[string]any
+	json.Unmarshal([]byte(lines[0]), &handling)
+	json.Unmarshal([]byte(lines[1]), &done)
+	if handling["correlationId"] != "corr-1" || handling["requestId"] != body["requestId"] {
+		t.Errorf("request logger lacks IDs: %v", handling)
+	}
+	if done["level"] != "WARN" || done["route"] != "/jobs/{id}" || done["status"] != 404.0 || done["requestBytes"] != 12.0 || done["correlationId"] != "corr-1" {
+		t.Errorf("completion event %v", done)
+	}
+}
+
+func TestMiddlewareStreamingAndMethods(t *testing.T) {
+	reg := metrics.NewRegistry()
+	mw, err := NewMiddleware(MiddlewareConfig{Registry: reg, Logger: slog.New(slog.DiscardHandler)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := mw(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		f, ok := w.(nethttp.Flusher)
+		if !ok {
+			t.Fatal("response writer does not implement http.Flusher")
+		}
+		io.WriteString(w, "data: 1\n\n")
+		f.Flush()
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("BREW", "/pot", nil))
+	if !rec.Flushed || rec.Body.String() != "data: 1\n\n" {
+		t.Errorf("flushed %v, body %q", rec.Flushed, rec.Body.String())
+	}
+
+	for _, ev := range reg.Export() {
+		if m, ok := ev.Tags["method"]; ok && m != "OTHER" {
+			t.Errorf("%s method label %q, want OTHER", ev.Name, m)
+		}
+	}
+}
+
+func TestMiddlewareRejectsInvalidIDs(t *testing.T) {
+	mw, err := NewMiddleware(MiddlewareConfig{Registry: metrics.NewRegistry(), Logger: slog.New(slog.DiscardHandler)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := mw(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {}))
+
+	for id, keep := range map[string]bool{
+		"0192d4e5-7f3a-7c2b-9a1e-5f6d7c8b9a0b": true,
+		"trace.abc_123-x":                      true,
+		strings.Repeat("a", 128):               true,
+		strings.Repeat("a", 129):               false,
+		"bad id":                               false,
+		"<script>":                             false,
+		"café":                                 false,
+	} {
+		req := httptest.NewRequest(nethttp.MethodGet, "/", nil)
+		req.Header.Set(RequestIDHeader, id)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		got := rec.Header().Get(RequestIDHeader)
+		if (got == id) != keep || got == "" {
+			t.Errorf("%q: response ID %q, want kept = %v", id, got, keep)
+		}
+	}
+}
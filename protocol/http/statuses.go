@@ -0,0 +1,61 @@
+package http
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/fulmenhq/crucible"
+	"gopkg.in/yaml.v3"
+)
+
+// groupIndex maps the codes of the Foundry HTTP status catalog
+// (config/library/foundry/http-statuses.yaml) to their group id, and each
+// status class (code/100) to the group of its catalogued codes.
+type groupIndex struct {
+	codes   map[int]string
+	classes map[int]string
+}
+
+var statusGroups = sync.OnceValues(func() (*groupIndex, error) {
+	var catalog struct {
+		Groups []struct {
+			ID    string `yaml:"id"`
+			Codes []struct {
+				Value int `yaml:"value"`
+			} `yaml:"codes"`
+		} `yaml:"groups"`
+	}
+	data, err := crucible.ConfigRegistry.Library().Foundry().HTTPStatuses()
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse HTTP status catalog: %w", err)
+	}
+	idx := &groupIndex{codes: map[int]string{}, classes: map[int]string{}}
+	for _, g := range catalog.Groups {
+		for _, c := range g.Codes {
+			idx.codes[c.Value] = g.ID
+			idx.classes[c.Value/100] = g.ID
+		}
+	}
+	return idx, nil
+})
+
+// StatusGroup returns the Foundry catalog group of an HTTP status, such as
+// success or client-error. Codes missing from the catalog take the group of
+// their class; a status outside every catalogued class is reported by class,
+// such as 7xx.
+func StatusGroup(status int) string {
+	idx, err := statusGroups()
+	if err == nil {
+		if g, ok := idx.codes[status]; ok {
+			return g
+		}
+		if g, ok := idx.classes[status/100]; ok {
+			return g
+		}
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
@@ -0,0 +1,67 @@
+package http
+
+import (
+	nethttp "net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// VersionInfo is the data of a version response.
+// See: schemas/protocol/http/v1.0.0/version-response.schema.json
+type VersionInfo struct {
+	Version   string         `json:"version"`
+	Commit    string         `json:"commit"`
+	BuildDate string         `json:"buildDate"`
+	BuildHost string         `json:"buildHost,omitempty"`
+	Language  string         `json:"language,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+}
+
+// BuildVersion returns version information for the running binary from its
+// embedded build info. The version is the main module version, the commit
+// and build date come from the VCS stamp, and the build date falls back to
+// the executable's modification time. Missing values are "unknown", or the
+// Unix epoch for the build date, so the result always validates.
+func BuildVersion() VersionInfo {
+	info := VersionInfo{Version: "unknown", Commit: "unknown", Language: "go", Metadata: map[string]any{"goVersion": runtime.Version()}}
+	var built time.Time
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if v := bi.Main.Version; v != "" && v != "(devel)" {
+			info.Version = v
+		}
+		if bi.Main.Path != "" {
+			info.Metadata["module"] = bi.Main.Path
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info.Commit = s.Value
+			case "vcs.time":
+				built, _ = time.Parse(time.RFC3339, s.Value)
+			case "vcs.modified":
+				info.Metadata["dirty"] = s.Value == "true"
+			}
+		}
+	}
+	if built.IsZero() {
+		if exe, err := os.Executable(); err == nil {
+			if fi, err := os.Stat(exe); err == nil {
+				built = fi.ModTime()
+			}
+		}
+	}
+	if built.IsZero() {
+		built = time.Unix(0, 0)
+	}
+	info.BuildDate = built.UTC().Format(time.RFC3339)
+	return info
+}
+
+// VersionHandler returns a handler serving info as a version response.
+func VersionHandler(info VersionInfo) nethttp.Handler {
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		WriteSuccess(w, r, nethttp.StatusOK, "", info)
+	})
+}
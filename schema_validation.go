@@ -0,0 +1,218 @@
+package crucible
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaBaseURL is the canonical base URL for Crucible schema $ids.
+// Schemas are resolved from the embedded schemas/ tree relative to this base.
+const SchemaBaseURL = "https://schemas.fulmenhq.dev/crucible/"
+
+// configBaseURL is where schema references into the config/ tree resolve,
+// e.g. "../../../../config/taxonomy/metrics.yaml" from a schema four levels deep.
+const configBaseURL = "https://schemas.fulmenhq.dev/config/"
+
+// SchemaDiagnostic is a single validation failure reported by ValidateSchemaData.
+type SchemaDiagnostic struct {
+	Pointer string `json:"pointer"` // JSON Pointer to the offending location in the document
+	Keyword string `json:"keyword"` // JSON Schema keyword that failed
+	Message string `json:"message"`
+}
+
+// SchemaValidationError is returned when a document does not conform to a schema.
+type SchemaValidationError struct {
+	Schema      string
+	Diagnostics []SchemaDiagnostic
+}
+
+func (e *SchemaValidationError) Error() string {
+	if len(e.Diagnostics) == 0 {
+		return fmt.Sprintf("document does not conform to %s", e.Schema)
+	}
+	parts := make([]string, len(e.Diagnostics))
+	for i, d := range e.Diagnostics {
+		ptr := d.Pointer
+		if ptr == "" {
+			ptr = "/"
+		}
+		parts[i] = fmt.Sprintf("%s: %s", ptr, d.Message)
+	}
+	return fmt.Sprintf("document does not conform to %s: %s", e.Schema, strings.Join(parts, "; "))
+}
+
+var (
+	schemaCacheMu sync.Mutex
+	schemaCache   = map[string]*jsonschema.Schema{}
+
+	schemaIDIndexOnce sync.Once
+	schemaIDIndex     map[string]string
+
+	diagnosticPrinter = message.NewPrinter(language.English)
+)
+
+// ValidateSchemaData validates a JSON or YAML document against an embedded schema.
+// schemaPath is relative to schemas/, e.g. "library/fulencode/v1.0.0/fulencode-config.schema.json".
+func ValidateSchemaData(schemaPath string, data []byte) error {
+	var raw any
+	if err := yaml.Unmarshal(bytes.TrimSpace(data), &raw); err != nil {
+		return fmt.Errorf("failed to parse document: %w", err)
+	}
+	return ValidateSchemaValue(schemaPath, convertYAML(raw))
+}
+
+// ValidateSchemaValue validates a Go value against an embedded schema. Structs
+// are round-tripped through encoding/json so their JSON tags are honored.
+func ValidateSchemaValue(schemaPath string, value any) error {
+	schema, err := compileSchema(schemaPath)
+	if err != nil {
+		return err
+	}
+
+	doc, err := toJSONValue(value)
+	if err != nil {
+		return err
+	}
+
+	if err := schema.Validate(doc); err != nil {
+		verr, ok := err.(*jsonschema.ValidationError)
+		if !ok {
+			return fmt.Errorf("failed to validate against %s: %w", schemaPath, err)
+		}
+		return &SchemaValidationError{Schema: schemaPath, Diagnostics: diagnostics(verr)}
+	}
+	return nil
+}
+
+func compileSchema(schemaPath string) (*jsonschema.Schema, error) {
+	schemaCacheMu.Lock()
+	defer schemaCacheMu.Unlock()
+
+	if schema, ok := schemaCache[schemaPath]; ok {
+		return schema, nil
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.UseLoader(embeddedSchemaLoader{})
+	schema, err := compiler.Compile(SchemaBaseURL + strings.TrimPrefix(schemaPath, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema %s: %w", schemaPath, err)
+	}
+
+	schemaCache[schemaPath] = schema
+	return schema, nil
+}
+
+// embeddedSchemaLoader resolves schema URLs from the embedded schemas/ tree,
+// first by canonical version-in-path location and then by declared $id for
+// schemas still using the deprecated version-in-filename form. References
+// into config/ (such as the metrics taxonomy $defs) load from the embedded
+// config tree, which may be YAML.
+type embeddedSchemaLoader struct{}
+
+func (embeddedSchemaLoader) Load(url string) (any, error) {
+	url, _, _ = strings.Cut(url, "#")
+
+	if rel, ok := strings.CutPrefix(url, SchemaBaseURL); ok {
+		if data, err := schemasFS.ReadFile(path.Join("schemas", rel)); err == nil {
+			return jsonschema.UnmarshalJSON(bytes.NewReader(data))
+		}
+	}
+
+	if rel, ok := strings.CutPrefix(url, configBaseURL); ok {
+		if data, err := configFS.ReadFile(path.Join("config", rel)); err == nil {
+			var raw any
+			if err := yaml.Unmarshal(data, &raw); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", url, err)
+			}
+			return toJSONValue(convertYAML(raw))
+		}
+	}
+
+	if p, ok := lookupSchemaID(url); ok {
+		data, err := schemasFS.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		return jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	}
+
+	return nil, fmt.Errorf("schema not found in embedded catalog: %s", url)
+}
+
+func lookupSchemaID(id string) (string, bool) {
+	schemaIDIndexOnce.Do(func() {
+		schemaIDIndex = map[string]string{}
+		_ = fs.WalkDir(schemasFS, "schemas", func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(p, ".json") {
+				return nil
+			}
+			data, err := schemasFS.ReadFile(p)
+			if err != nil {
+				return nil
+			}
+			var header struct {
+				ID string `json:"$id"`
+			}
+			if json.Unmarshal(data, &header) == nil && header.ID != "" {
+				schemaIDIndex[header.ID] = p
+			}
+			return nil
+		})
+	})
+
+	p, ok := schemaIDIndex[id]
+	return p, ok
+}
+
+func toJSONValue(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode document: %w", err)
+	}
+	return jsonschema.UnmarshalJSON(bytes.NewReader(data))
+}
+
+func diagnostics(verr *jsonschema.ValidationError) []SchemaDiagnostic {
+	var out []SchemaDiagnostic
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			keyword := ""
+			if kp := e.ErrorKind.KeywordPath(); len(kp) > 0 {
+				keyword = kp[len(kp)-1]
+			}
+			out = append(out, SchemaDiagnostic{
+				Pointer: instancePointer(e.InstanceLocation),
+				Keyword: keyword,
+				Message: e.ErrorKind.LocalizedString(diagnosticPrinter),
+			})
+			return
+		}
+		for _, cause := range e.Causes {
+			walk(cause)
+		}
+	}
+	walk(verr)
+	return out
+}
+
+func instancePointer(tokens []string) string {
+	var sb strings.Builder
+	for _, tok := range tokens {
+		sb.WriteByte('/')
+		tok = strings.ReplaceAll(tok, "~", "~0")
+		sb.WriteString(strings.ReplaceAll(tok, "/", "~1"))
+	}
+	return sb.String()
+}
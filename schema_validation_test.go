@@ -0,0 +1,57 @@
+package crucible
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateSchemaData(t *testing.T) {
+	const schema = "library/fulencode/v1.0.0/fulencode-config.schema.json"
+
+	t.Run("valid YAML document", func(t *testing.T) {
+		doc := "limits:\n  max_decoded_size: 4096\ndefaults:\n  on_error: replace\n"
+		if err := ValidateSchemaData(schema, []byte(doc)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("invalid document reports diagnostics", func(t *testing.T) {
+		err := ValidateSchemaData(schema, []byte(`{"limits": {"max_decoded_size": 1}}`))
+		var verr *SchemaValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("expected SchemaValidationError, got %v", err)
+		}
+		if len(verr.Diagnostics) != 1 {
+			t.Fatalf("expected 1 diagnostic, got %+v", verr.Diagnostics)
+		}
+		if d := verr.Diagnostics[0]; d.Pointer != "/limits/max_decoded_size" || d.Keyword != "minimum" {
+			t.Errorf("unexpected diagnostic: %+v", d)
+		}
+	})
+
+	t.Run("resolves cross-schema references", func(t *testing.T) {
+		doc := `{"timestamp": "2025-10-09T12:00:00.000000000Z", "severity": "INFO", "severityLevel": 20, "message": "ok", "service": "svc"}`
+		if err := ValidateSchemaData("observability/logging/v1.0.0/log-event.schema.json", []byte(doc)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("resolves references into config", func(t *testing.T) {
+		const metrics = "observability/metrics/v1.0.0/metrics-event.schema.json"
+		doc := `{"timestamp": "2025-11-06T12:34:56.789Z", "name": "schema_validations", "value": 3, "unit": "count"}`
+		if err := ValidateSchemaData(metrics, []byte(doc)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		doc = `{"timestamp": "2025-11-06T12:34:56.789Z", "name": "not_a_metric", "value": 3}`
+		var verr *SchemaValidationError
+		if err := ValidateSchemaData(metrics, []byte(doc)); !errors.As(err, &verr) {
+			t.Fatalf("expected SchemaValidationError, got %v", err)
+		}
+	})
+
+	t.Run("unknown schema", func(t *testing.T) {
+		if err := ValidateSchemaData("does/not/exist.schema.json", []byte(`{}`)); err == nil {
+			t.Error("expected error for unknown schema")
+		}
+	})
+}
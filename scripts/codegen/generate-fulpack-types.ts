@@ -237,9 +237,21 @@ function inferTypeScriptType(property: JSONSchemaProperty): string {
   return type ? typeMap[type] || "unknown" : "unknown";
 }
 
+// Go field types that cannot be inferred from the schema. The named types are
+// declared in hand-written sources of the fulpack package.
+const goTypeOverrides: { [field: string]: string } = {
+  "ArchiveManifest.index": "*ManifestIndex",
+};
+
 // Helper: Infer Go type from JSON Schema property
 function inferGoType(property: JSONSchemaProperty, isOptional: boolean = false): string {
-  const { type, enum: enumValues, items, format } = property;
+  const { type, enum: enumValues, items, format, $ref } = property;
+
+  // Handle references to sibling fulpack schemas (e.g., .../v1.0.0/archive-entry)
+  if ($ref) {
+    const refType = toPascalCase($ref.split("/").pop()!.replace(".schema.json", ""));
+    return isOptional ? `*${refType}` : refType;
+  }
 
   // Handle enums - use string type, will be defined as type alias
   if (enumValues && enumValues.length > 0) {
@@ -443,7 +455,7 @@ function processSchema(
     const pythonType = inferPythonType(propDef);
     const isAlreadyNullable = pythonType.endsWith(" | None");
     const typescriptType = inferTypeScriptType(propDef);
-    const goType = inferGoType(propDef, !isRequired);
+    const goType = goTypeOverrides[`${className}.${propName}`] ?? inferGoType(propDef, !isRequired);
     const rustType = inferRustType(propDef, !isRequired);
     const nameGo = toGoPascalCase(propName);
     const nameRust = toRustSnakeCase(propName);
@@ -0,0 +1,125 @@
+#!/usr/bin/env bun
+
+/**
+ * Metrics Taxonomy Generator
+ *
+ * Generates Go metric name and unit constants plus default histogram
+ * buckets from the metrics taxonomy at config/taxonomy/metrics.yaml.
+ *
+ * Usage:
+ *   bun run scripts/codegen/generate-metrics-taxonomy.ts
+ *   bun run scripts/codegen/generate-metrics-taxonomy.ts --format
+ *   bun run scripts/codegen/generate-metrics-taxonomy.ts --out /path/to/output.go
+ */
+
+import { execSync } from "node:child_process";
+import { readFileSync, writeFileSync } from "node:fs";
+import { resolve } from "node:path";
+import { load as loadYaml } from "js-yaml";
+
+interface MetricYaml {
+  name: string;
+  unit: string;
+  description?: string;
+}
+
+interface TaxonomyYaml {
+  version: string;
+  $defs: {
+    metricUnit: { enum: string[] };
+  };
+  defaults: {
+    histogram_buckets: {
+      ms_metrics: number[];
+      seconds_metrics: number[];
+      bytes_metrics: number[];
+    };
+  };
+  metrics: MetricYaml[];
+}
+
+interface UnitEntry {
+  ConstName: string;
+  Value: string;
+}
+
+interface MetricEntry {
+  ConstName: string;
+  Name: string;
+  Unit: string;
+  Summary: string;
+}
+
+// Go initialisms kept upper-case in identifiers.
+const INITIALISMS: Record<string, string> = { http: "HTTP", json: "JSON", mime: "MIME", xml: "XML" };
+
+// Unit abbreviations spelled out in constant names.
+const UNIT_NAMES: Record<string, string> = { ms: "Milliseconds", s: "Seconds" };
+
+// Convert a snake_case name to a Go identifier: "http_requests_total" → "HTTPRequestsTotal"
+function toGoName(name: string): string {
+  return name
+    .split("_")
+    .map((w) => INITIALISMS[w] ?? w.charAt(0).toUpperCase() + w.slice(1))
+    .join("");
+}
+
+// First sentence of a description, on one line.
+function summary(description: string | undefined): string {
+  const text = (description ?? "").replace(/\s+/g, " ").trim();
+  const match = text.match(/^(.*?\.)(\s|$)/);
+  return match ? match[1] : text;
+}
+
+const taxonomy = loadYaml(
+  readFileSync(resolve("config/taxonomy/metrics.yaml"), "utf-8"),
+) as TaxonomyYaml;
+
+const units: UnitEntry[] = taxonomy.$defs.metricUnit.enum.map((u) => ({
+  ConstName: `Unit${UNIT_NAMES[u] ?? toGoName(u)}`,
+  Value: u,
+}));
+const unitConst = new Map(units.map((u) => [u.Value, u.ConstName]));
+
+const metrics: MetricEntry[] = taxonomy.metrics.map((m) => ({
+  ConstName: toGoName(m.name),
+  Name: m.name,
+  Unit: unitConst.get(m.unit) ?? "",
+  Summary: summary(m.description),
+}));
+
+const buckets = taxonomy.defaults.histogram_buckets;
+const Buckets = {
+  Milliseconds: buckets.ms_metrics.join(", "),
+  Seconds: buckets.seconds_metrics.join(", "),
+  Bytes: buckets.bytes_metrics.join(", "),
+};
+
+// Resolve output path
+const args = process.argv.slice(2);
+const outIdx = args.indexOf("--out");
+const outputPath =
+  (outIdx >= 0 ? args[outIdx + 1] : undefined) ?? resolve("observability/metrics/taxonomy.go");
+const shouldFormat = args.includes("--format");
+
+// Render template
+const ejs = await import("ejs");
+const templatePath = resolve("scripts/codegen/metrics-taxonomy/go/template.ejs");
+const templateContent = readFileSync(templatePath, "utf-8");
+const rendered = ejs.render(templateContent, {
+  Version: taxonomy.version,
+  Units: units,
+  Metrics: metrics,
+  Buckets,
+});
+
+writeFileSync(outputPath, rendered, "utf-8");
+console.log(`✓ Wrote ${outputPath}`);
+
+if (shouldFormat) {
+  execSync(`bash scripts/codegen/metrics-taxonomy/go/postprocess.sh "${outputPath}"`, {
+    stdio: "inherit",
+  });
+}
+
+console.log("✓ Metrics taxonomy constants generated successfully");
@@ -0,0 +1,59 @@
+#!/usr/bin/env bun
+
+/**
+ * Metrics Taxonomy Verification Script
+ *
+ * Verifies that observability/metrics/taxonomy.go is up-to-date with the
+ * metrics taxonomy at config/taxonomy/metrics.yaml by regenerating to a temp
+ * file and comparing against the checked-in version.
+ *
+ * Usage:
+ *   bun run scripts/codegen/verify-metrics-taxonomy.ts
+ *   make verify-codegen
+ *
+ * Exit codes:
+ *   0 - Verified: generated output matches checked-in file
+ *   1 - Drift detected or verification error
+ */
+
+import { execSync } from "node:child_process";
+import { mkdirSync, readFileSync, rmSync } from "node:fs";
+import { resolve } from "node:path";
+
+const checkedInPath = resolve("observability/metrics/taxonomy.go");
+const tmpDir = resolve(".tmp/codegen-verify-metrics");
+const tmpPath = resolve(tmpDir, "taxonomy.go");
+
+// Ensure tmp directory exists
+mkdirSync(tmpDir, { recursive: true });
+
+try {
+  // Regenerate to temp location
+  execSync(`bun run scripts/codegen/generate-metrics-taxonomy.ts --format --out "${tmpPath}"`, {
+    stdio: "pipe",
+  });
+
+  const generated = readFileSync(tmpPath, "utf-8");
+  const checkedIn = readFileSync(checkedInPath, "utf-8");
+
+  if (generated === checkedIn) {
+    console.log("✅ Metrics taxonomy constants are up-to-date");
+    process.exit(0);
+  } else {
+    console.error("❌ Metrics taxonomy drift detected");
+    console.error("   observability/metrics/taxonomy.go does not match regenerated output.");
+    console.error("   Run `make codegen-metrics` to update.");
+    process.exit(1);
+  }
+} catch (err) {
+  console.error("❌ Metrics taxonomy verification failed:");
+  console.error(`   ${err instanceof Error ? err.message : String(err)}`);
+  process.exit(1);
+} finally {
+  // Clean up temp dir
+  try {
+    rmSync(tmpDir, { recursive: true, force: true });
+  } catch {
+    // best-effort cleanup
+  }
+}
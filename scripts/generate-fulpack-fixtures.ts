@@ -12,6 +12,9 @@
  * Fixtures:
  *   - basic.tar        : Uncompressed tar with binary+text content (<10KB)
  *   - basic.tar.gz     : Compressed version of basic structure (<10KB)
+ *   - basic.tar.zst    : basic.tar.gz content with zstd compression (<10KB, read-only format)
+ *   - basic.tar.xz     : basic.tar.gz content with xz compression (<10KB, read-only format)
+ *   - basic.tar.bz2    : basic.tar.gz content with bzip2 compression (<10KB, read-only format)
  *   - nested.zip       : 3-level directory nesting (<10KB)
  *   - pathological.tar.gz : Security test cases (<50KB)
  */
@@ -238,8 +241,7 @@ Last updated: 2025-11-14
 // Fixture: basic.tar.gz (Compressed TAR)
 // ============================================================================
 
-async function generateBasicTarGz(): Promise<void> {
-  const contentDir = join(TEMP_DIR, "basic-targz-content");
+async function writeBasicTarGzContent(contentDir: string): Promise<void> {
   await ensureCleanDir(contentDir);
 
   // Create simple text files (will compress well)
@@ -262,6 +264,11 @@ Compressed tar archive for standard testing.
     join(contentDir, "subdir", "file3.txt"),
     "Nested file 3 for directory testing.\n",
   );
+}
+
+async function generateBasicTarGz(): Promise<void> {
+  const contentDir = join(TEMP_DIR, "basic-targz-content");
+  await writeBasicTarGzContent(contentDir);
 
   // Create tar.gz archive
   const outputPath = join(FIXTURES_DIR, "basic.tar.gz");
@@ -291,6 +298,56 @@ Generated by: scripts/generate-fulpack-fixtures.ts
 `;
 }
 
+// ============================================================================
+// Fixtures: basic.tar.zst, basic.tar.xz, basic.tar.bz2 (Read-Only Formats)
+// ============================================================================
+
+// Same content as basic.tar.gz, so implementations can assert identical
+// scan/extract results across compressions. The compressor must be on PATH.
+const READ_ONLY_VARIANTS: { [name: string]: { compressor: string; format: string } } = {
+  "basic.tar.zst": { compressor: "zstd", format: "tar.zst" },
+  "basic.tar.xz": { compressor: "xz", format: "tar.xz" },
+  "basic.tar.bz2": { compressor: "bzip2", format: "tar.bz2" },
+};
+
+function generateBasicTarVariant(name: string): () => Promise<void> {
+  return async () => {
+    const contentDir = join(TEMP_DIR, `${name}-content`);
+    await writeBasicTarGzContent(contentDir);
+
+    const outputPath = join(FIXTURES_DIR, name);
+    const { compressor } = READ_ONLY_VARIANTS[name]!;
+    execSync(`tar -cf - -C "${contentDir}" . | ${compressor} -c > "${outputPath}"`, {
+      stdio: "inherit",
+    });
+  };
+}
+
+function getBasicTarVariantDocs(name: string): () => string {
+  return () => {
+    const { compressor, format } = READ_ONLY_VARIANTS[name]!;
+    return `# ${name} - ${compressor} Compressed TAR Fixture
+
+## Purpose
+Test read support for the read-only \`${format}\` format and magic-byte format detection.
+
+## Contents
+Same files as basic.tar.gz:
+- **README.md** (text, ~150 bytes)
+- **file1.txt** (text, ~50 bytes)
+- **file2.txt** (text, ~50 bytes)
+- **subdir/file3.txt** (text, ~50 bytes)
+
+## Expected Behavior
+- info(), scan(), verify() and extract() match basic.tar.gz
+- Format is detected as \`${format}\` from content, even when the file is renamed
+- create() rejects \`${format}\` with INVALID_ARCHIVE_FORMAT (read-only format)
+
+Generated by: scripts/generate-fulpack-fixtures.ts
+`;
+  };
+}
+
 // ============================================================================
 // Fixture: nested.zip (3-Level Directory Nesting)
 // ============================================================================
@@ -352,59 +409,84 @@ Generated by: scripts/generate-fulpack-fixtures.ts
 // Fixture: pathological.tar.gz (Security Test Cases)
 // ============================================================================
 
-async function generatePathologicalTarGz(): Promise<void> {
-  const contentDir = join(TEMP_DIR, "pathological-content");
-  await ensureCleanDir(contentDir);
-
-  // Create legitimate files first
-  await writeFile(
-    join(contentDir, "README.md"),
-    `# Pathological Archive - Security Test Cases
-
-This archive contains malicious path patterns for testing security protections.
-DO NOT extract without security validation!
-
-## Malicious Patterns
-1. Path traversal attempts (../../../etc/passwd)
-2. Absolute paths (/etc/passwd, /root/.ssh/id_rsa)
-3. Symlink escape attempts (symlinks targeting outside archive)
+// tar(1) cannot store "../" or absolute member names (it strips them), so the
+// archive is written entry by entry with Python's tarfile module. Timestamps,
+// owners and the gzip header are fixed so the output is reproducible.
+const PATHOLOGICAL_TAR_PY = `
+import gzip, io, sys, tarfile
 
-All operations MUST validate paths before extraction.
-`,
-  );
+MTIME = 1763159820  # 2025-11-14T22:37:00Z
 
-  await writeFile(join(contentDir, "legitimate.txt"), "This is a safe file.\n");
+README = """# Pathological Archive - Security Test Cases
 
-  // Create files with malicious names (tar will warn but create them)
-  // Note: These become safe files on disk but have dangerous names in the archive
+This archive contains malicious entries for testing security protections.
+DO NOT extract without security validation!
 
-  // Path traversal attempts
-  await mkdir(join(contentDir, "safe-traversal"), { recursive: true });
-  await writeFile(join(contentDir, "safe-traversal", "..traversal1.txt"), "traversal test 1\n");
-  await writeFile(join(contentDir, "safe-traversal", "..traversal2.txt"), "traversal test 2\n");
+## Malicious Entries
+1. Path traversal: ../../../etc/passwd
+2. Absolute paths: /etc/passwd, /root/.ssh/id_rsa
+3. Symlink escape: escape/passwd -> ../../../../etc/passwd
+4. Symlink chain escape: chain/up -> .., then chain/up/escape -> ../..
+   (each target is lexically inside the archive; together they leave it)
 
-  // Absolute path simulation (can't create actual absolute paths in tar from relative dir)
-  await mkdir(join(contentDir, "safe-absolute"), { recursive: true });
-  await writeFile(
-    join(contentDir, "safe-absolute", "root_ssh_simulation.txt"),
-    "absolute path test\n",
-  );
+## Safe Entries
+- legitimate.txt, safe-traversal/..traversal*.txt (".." inside a name),
+  safe-absolute/root_ssh_simulation.txt, safe-symlinks/symlink-test.txt
 
-  // Symlink tests
-  await mkdir(join(contentDir, "safe-symlinks"), { recursive: true });
-  await writeFile(join(contentDir, "safe-symlinks", "symlink-target.txt"), "symlink target\n");
-  // Create symlink (will be preserved in tar)
-  execSync(
-    `cd "${join(contentDir, "safe-symlinks")}" && ln -s symlink-target.txt symlink-test.txt`,
-  );
+All operations MUST validate paths before extraction.
+"""
+
+def info(name, typ=tarfile.REGTYPE, size=0, link="", mode=0o644):
+    ti = tarfile.TarInfo(name)
+    ti.type, ti.size, ti.linkname, ti.mtime = typ, size, link, MTIME
+    ti.mode = 0o755 if typ in (tarfile.DIRTYPE, tarfile.SYMTYPE) else mode
+    ti.uname = ti.gname = ""
+    return ti
+
+buf = io.BytesIO()
+with tarfile.open(fileobj=buf, mode="w", format=tarfile.PAX_FORMAT) as tar:
+    def file(name, text):
+        data = text.encode()
+        tar.addfile(info(name, size=len(data)), io.BytesIO(data))
+    def dir(name):
+        tar.addfile(info(name, tarfile.DIRTYPE))
+    def link(name, target):
+        tar.addfile(info(name, tarfile.SYMTYPE, link=target))
+
+    file("README.md", README)
+    file("legitimate.txt", "This is a safe file.\\n")
+    dir("safe-traversal/")
+    file("safe-traversal/..traversal1.txt", "traversal test 1\\n")
+    file("safe-traversal/..traversal2.txt", "traversal test 2\\n")
+    dir("safe-absolute/")
+    file("safe-absolute/root_ssh_simulation.txt", "absolute path test\\n")
+    dir("safe-symlinks/")
+    file("safe-symlinks/symlink-target.txt", "symlink target\\n")
+    link("safe-symlinks/symlink-test.txt", "symlink-target.txt")
+    file("../../../etc/passwd", "path traversal test\\n")
+    file("/etc/passwd", "absolute path test\\n")
+    file("/root/.ssh/id_rsa", "absolute path test\\n")
+    dir("escape/")
+    link("escape/passwd", "../../../../etc/passwd")
+    dir("chain/")
+    link("chain/up", "..")
+    link("chain/up/escape", "../..")
+
+out = io.BytesIO()
+with gzip.GzipFile(filename="", mode="wb", fileobj=out, mtime=0) as gz:
+    gz.write(buf.getvalue())
+with open(sys.argv[1], "wb") as f:
+    f.write(out.getvalue())
+`;
 
-  // Create tar.gz archive
-  // Note: Real pathological entries would need manual tar manipulation or specialized tools
-  // This creates a "safe" version that can be enhanced with actual dangerous entries later
+async function generatePathologicalTarGz(): Promise<void> {
   const outputPath = join(FIXTURES_DIR, "pathological.tar.gz");
-  execSync(`tar -czf "${outputPath}" -C "${contentDir}" .`, { stdio: "inherit" });
+  execSync(`python3 - "${outputPath}"`, {
+    input: PATHOLOGICAL_TAR_PY,
+    stdio: ["pipe", "inherit", "inherit"],
+  });
 
-  console.log("  ⚠️  Note: Contains simulated security test cases");
+  console.log("  ⚠️  Note: Contains real malicious entries - never extract without validation");
 }
 
 function getPathologicalTarGzDocs(): string {
@@ -416,9 +498,15 @@ Test security protections against malicious archive entries.
 ## Contents
 - **README.md**: Warning about malicious patterns
 - **legitimate.txt**: Safe baseline file
-- **safe-traversal/**: Directory with ".." in filenames (simulation)
-- **safe-absolute/**: Files simulating absolute path attacks
-- **safe-symlinks/**: Symlink test cases
+- **safe-traversal/**: Files with ".." inside their names (not traversals)
+- **safe-absolute/**: Plain file named after an absolute path attack
+- **safe-symlinks/**: Symlink to a sibling file (within bounds)
+- **../../../etc/passwd**: Path traversal
+- **/etc/passwd**, **/root/.ssh/id_rsa**: Absolute paths
+- **escape/passwd** -> ../../../../etc/passwd: Symlink escape
+- **chain/up** -> .., then **chain/up/escape** -> ../..: Symlink chain escape
+  (each target is lexically inside the archive; resolved through the first
+  link, the second leaves it)
 
 **Total**: <2KB content, <5KB compressed
 
@@ -430,33 +518,35 @@ Test security protections against malicious archive entries.
 
 ### verify()
 - MUST return valid=false with security errors:
-  - PATH_TRAVERSAL for "../" patterns
-  - ABSOLUTE_PATH for paths starting with "/"
-  - SYMLINK_ESCAPE for symlinks targeting outside archive
+  - PATH_TRAVERSAL for "../../../etc/passwd"
+  - ABSOLUTE_PATH for "/etc/passwd" and "/root/.ssh/id_rsa"
+  - SYMLINK_ESCAPE for "escape/passwd" and "chain/up/escape"
+- MUST NOT report the safe-* entries
 - Emit \`fulpack.security.violations_total\` metrics
 
 ### extract()
 - MUST reject extraction with security errors
-- MUST NOT create any files with dangerous paths
+- MUST NOT create any files (validation precedes writing)
 - MUST NOT follow symlinks outside extraction directory
 
 ## Test Coverage
 - ✅ Path traversal detection ("../../../etc/passwd")
 - ✅ Absolute path rejection ("/etc/passwd", "/root/...")
 - ✅ Symlink escape detection (symlink → "../../../../etc/...")
+- ✅ Symlink chain escape detection (targets resolved through earlier links)
 - ✅ Security metrics emitted (violations_total)
 - ✅ verify() catches threats before extract()
 
 ## Security Note
-⚠️  This is a simulated security fixture. Real-world malicious archives may
-use more sophisticated techniques. Always validate archives before extraction.
+⚠️  This fixture contains real malicious entries. Never extract it with a tool
+that does not validate entry paths and link targets.
 
 ## Size Governance
 - **Max size**: 50KB (per pathological fixture governance)
-- **Actual size**: ~3KB (well under limit)
+- **Actual size**: <1KB (well under limit)
 
 Generated by: scripts/generate-fulpack-fixtures.ts
-Last updated: 2025-11-14
+Last updated: 2026-10-18
 `;
 }
 
@@ -479,6 +569,13 @@ const FIXTURES: FixtureSpec[] = [
     generate: generateBasicTarGz,
     getDocs: getBasicTarGzDocs,
   },
+  ...Object.keys(READ_ONLY_VARIANTS).map((name) => ({
+    name,
+    maxSize: 10 * 1024,
+    description: "Compressed tar archive (read-only format)",
+    generate: generateBasicTarVariant(name),
+    getDocs: getBasicTarVariantDocs(name),
+  })),
   {
     name: "nested.zip",
     maxSize: 10 * 1024,
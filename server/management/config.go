@@ -0,0 +1,321 @@
+// Package management implements the Fulmen server management harness:
+// configuration classes with environment overrides, port allocation and
+// PID files, failing with the Foundry exit codes the configuration names.
+//
+// See: docs/standards/library/modules/server-management.md
+package management
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fulmenhq/crucible"
+	"github.com/fulmenhq/crucible/foundry"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaPath locates the server management schema for
+// crucible.ValidateSchemaData.
+const SchemaPath = "server/management/v1.0.0/server-management.schema.json"
+
+// ConfigPath is the embedded default configuration, relative to config/.
+const ConfigPath = "server/management/server-management.yaml"
+
+// DefaultEnvPrefix is the envPrefix of a configuration that sets none.
+const DefaultEnvPrefix = "FULMEN_APP"
+
+// Config is a server management configuration document.
+// See: schemas/server/management/v1.0.0/server-management.schema.json
+type Config struct {
+	EnvPrefix                string                   `yaml:"envPrefix,omitempty" json:"envPrefix,omitempty"`
+	Configurations           map[string]*ServerConfig `yaml:"configurations" json:"configurations"`
+	AdditionalConfigurations map[string]*ServerConfig `yaml:"additionalConfigurations,omitempty" json:"additionalConfigurations,omitempty"`
+}
+
+// ServerConfig is a configuration class, such as dev or x-storybook.
+type ServerConfig struct {
+	PreferredPort int          `yaml:"preferredPort" json:"preferredPort"`
+	Range         PortRange    `yaml:"range" json:"range"`
+	HealthCheck   HealthCheck  `yaml:"healthCheck" json:"healthCheck"`
+	ExitBehavior  ExitBehavior `yaml:"exitBehavior" json:"exitBehavior"`
+	EnvOverrides  []string     `yaml:"envOverrides,omitempty" json:"envOverrides,omitempty"`
+	PIDFile       string       `yaml:"pidFile,omitempty" json:"pidFile,omitempty"`
+	LogFile       string       `yaml:"logFile,omitempty" json:"logFile,omitempty"`
+
+	// Set by Resolve.
+	Class      string `yaml:"-" json:"-"`
+	EnvPrefix  string `yaml:"-" json:"-"`
+	PortPinned bool   `yaml:"-" json:"-"` // PreferredPort came from the PORT override
+	Root       string `yaml:"-" json:"-"` // Project root PIDFile and LogFile are relative to
+}
+
+// PortRange is an inclusive port range.
+type PortRange struct {
+	Min int `yaml:"min" json:"min"`
+	Max int `yaml:"max" json:"max"`
+}
+
+// Contains reports whether port lies in the range.
+func (r PortRange) Contains(port int) bool {
+	return r.Min <= port && port <= r.Max
+}
+
+// HealthCheck configures the readiness probe of a class. Durations are in
+// milliseconds, as in the configuration.
+type HealthCheck struct {
+	Method     string `yaml:"method" json:"method"`
+	Path       string `yaml:"path" json:"path"`
+	TimeoutMs  int    `yaml:"timeout" json:"timeout"`
+	Retries    int    `yaml:"retries" json:"retries"`
+	IntervalMs int    `yaml:"interval" json:"interval"`
+}
+
+// ExitBehavior maps failures onto the exit codes the process terminates
+// with.
+type ExitBehavior struct {
+	PortInUse         int `yaml:"portInUse" json:"portInUse"`
+	HealthCheckFailed int `yaml:"healthCheckFailed" json:"healthCheckFailed"`
+	StartupTimeout    int `yaml:"startupTimeout" json:"startupTimeout"`
+}
+
+// UnmarshalYAML decodes a class over the schema defaults, so omitted
+// health check and exit behavior fields keep them.
+func (s *ServerConfig) UnmarshalYAML(node *yaml.Node) error {
+	type plain ServerConfig
+	p := plain{
+		HealthCheck: HealthCheck{Method: "GET", TimeoutMs: 5000, Retries: 3, IntervalMs: 1000},
+		ExitBehavior: ExitBehavior{
+			PortInUse:         foundry.ExitPortInUse,
+			HealthCheckFailed: foundry.ExitHealthCheckFailed,
+			StartupTimeout:    foundry.ExitTimeout,
+		},
+	}
+	if err := node.Decode(&p); err != nil {
+		return err
+	}
+	*s = ServerConfig(p)
+	return nil
+}
+
+// LoadConfig parses a YAML or JSON server management configuration and
+// validates it.
+func LoadConfig(data []byte) (*Config, error) {
+	if err := crucible.ValidateSchemaData(SchemaPath, data); err != nil {
+		return nil, invalidConfig("", "%v", err)
+	}
+	cfg := &Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, invalidConfig("", "failed to parse: %v", err)
+	}
+	if cfg.EnvPrefix == "" {
+		cfg.EnvPrefix = DefaultEnvPrefix
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// DefaultConfig returns the embedded default configuration.
+func DefaultConfig() (*Config, error) {
+	data, err := crucible.GetConfig(ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	return LoadConfig(data)
+}
+
+// Validate checks every class for constraints the schema cannot express:
+// the port range must not be empty and must contain the preferred port,
+// each exitBehavior code must be the Foundry exit code for its failure
+// (EXIT_PORT_IN_USE, EXIT_HEALTH_CHECK_FAILED and EXIT_TIMEOUT), and each
+// envOverrides entry must end in the class and a setting Resolve overrides,
+// such as _DEV_PORT.
+func (c *Config) Validate() error {
+	for _, class := range c.Classes() {
+		if err := c.class(class).validate(class); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ServerConfig) validate(class string) error {
+	if s.Range.Min > s.Range.Max {
+		return invalidConfig(class, "range min %d exceeds max %d", s.Range.Min, s.Range.Max)
+	}
+	if !s.PortPinned && !s.Range.Contains(s.PreferredPort) {
+		return invalidConfig(class, "preferredPort %d is outside range %d-%d", s.PreferredPort, s.Range.Min, s.Range.Max)
+	}
+	for _, name := range s.EnvOverrides {
+		if !slices.ContainsFunc(envSettings, func(setting string) bool { return strings.HasSuffix(name, envSuffix(class, setting)) }) {
+			return invalidConfig(class, "envOverrides: %s names no setting of this class (want ${envPrefix}%s)", name, envSuffix(class, "${SETTING}"))
+		}
+	}
+	for _, eb := range []struct {
+		field      string
+		code, want int
+	}{
+		{"portInUse", s.ExitBehavior.PortInUse, foundry.ExitPortInUse},
+		{"healthCheckFailed", s.ExitBehavior.HealthCheckFailed, foundry.ExitHealthCheckFailed},
+		{"startupTimeout", s.ExitBehavior.StartupTimeout, foundry.ExitTimeout},
+	} {
+		want := foundry.GetExitCodeInfo(eb.want)
+		info := foundry.GetExitCodeInfo(eb.code)
+		if info == nil {
+			return invalidConfig(class, "exitBehavior.%s: %d is not a Foundry exit code, want %d (%s)", eb.field, eb.code, want.Code, want.Name)
+		}
+		if info.Code != want.Code {
+			return invalidConfig(class, "exitBehavior.%s: %d is %s, want %d (%s)", eb.field, eb.code, info.Name, want.Code, want.Name)
+		}
+	}
+	return nil
+}
+
+// Classes returns the names of the standard and additional classes, sorted.
+func (c *Config) Classes() []string {
+	names := slices.Collect(maps.Keys(c.Configurations))
+	names = slices.AppendSeq(names, maps.Keys(c.AdditionalConfigurations))
+	slices.Sort(names)
+	return names
+}
+
+func (c *Config) class(name string) *ServerConfig {
+	if s, ok := c.Configurations[name]; ok {
+		return s
+	}
+	return c.AdditionalConfigurations[name]
+}
+
+// envSettings are the settings environment variables override, named
+// ${envPrefix}_${CLASS}_${SETTING}.
+var envSettings = []string{
+	"PORT", "RANGE_MIN", "RANGE_MAX",
+	"HEALTH_METHOD", "HEALTH_PATH", "HEALTH_TIMEOUT", "HEALTH_RETRIES", "HEALTH_INTERVAL",
+	"PID_FILE", "LOG_FILE",
+}
+
+// EnvVar returns the environment variable overriding setting (such as PORT
+// or RANGE_MIN) for class: FULMEN_APP_DEV_PORT, or FULMEN_APP_STORYBOOK_PORT
+// for x-storybook.
+func (c *Config) EnvVar(class, setting string) string {
+	return c.EnvPrefix + envSuffix(class, setting)
+}
+
+// envSuffix returns the part of the variable overriding setting for class
+// that follows the prefix: _DEV_PORT, or _STORYBOOK_PORT for x-storybook.
+func envSuffix(class, setting string) string {
+	name := strings.ToUpper(strings.ReplaceAll(strings.TrimPrefix(class, "x-"), "-", "_"))
+	return "_" + name + "_" + setting
+}
+
+// allowsEnv reports whether the envOverrides of class, if any, list setting.
+// Entries are matched on their class and setting, not their prefix, so the
+// FULMEN_APP_ names of the default configuration keep working under another
+// envPrefix.
+func (s *ServerConfig) allowsEnv(class, setting string) bool {
+	if len(s.EnvOverrides) == 0 {
+		return true
+	}
+	suffix := envSuffix(class, setting)
+	return slices.ContainsFunc(s.EnvOverrides, func(name string) bool {
+		return strings.HasSuffix(name, suffix)
+	})
+}
+
+// Resolve returns a copy of class with environment overrides applied.
+//
+// Each setting can be overridden by ${envPrefix}_${CLASS}_${SETTING}: PORT
+// (which pins the port, so it is used even outside the range and is not
+// replaced by another when busy), RANGE_MIN, RANGE_MAX, HEALTH_METHOD,
+// HEALTH_PATH, HEALTH_TIMEOUT, HEALTH_RETRIES, HEALTH_INTERVAL, PID_FILE and
+// LOG_FILE. A class that lists envOverrides honors only the settings
+// listed, whatever prefix the listed names carry. The result is validated again, and its Root is the working
+// directory.
+func (c *Config) Resolve(class string) (*ServerConfig, error) {
+	base := c.class(class)
+	if base == nil {
+		return nil, NewError(CodeUnknownClass, class, fmt.Sprintf("no such configuration class (have %s)", strings.Join(c.Classes(), ", ")))
+	}
+	s := *base
+	s.EnvOverrides = slices.Clone(base.EnvOverrides)
+	s.Class = class
+	s.EnvPrefix = c.EnvPrefix
+	s.Root = "."
+
+	for _, setting := range envSettings {
+		if !s.allowsEnv(class, setting) {
+			continue
+		}
+		name := c.EnvVar(class, setting)
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			continue
+		}
+		if err := s.override(setting, value); err != nil {
+			return nil, invalidConfig(class, "%s=%q: %v", name, value, err)
+		}
+	}
+
+	if err := crucible.ValidateSchemaValue(SchemaPath, map[string]any{
+		"configurations":           map[string]any{},
+		"additionalConfigurations": map[string]any{"x-resolved": &s},
+	}); err != nil {
+		return nil, invalidConfig(class, "after environment overrides: %v", err)
+	}
+	if err := s.validate(class); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ResolveConfig resolves class from the embedded default configuration.
+func ResolveConfig(class string) (*ServerConfig, error) {
+	cfg, err := DefaultConfig()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Resolve(class)
+}
+
+func (s *ServerConfig) override(setting, value string) error {
+	switch setting {
+	case "HEALTH_METHOD":
+		s.HealthCheck.Method = strings.ToUpper(value)
+		return nil
+	case "HEALTH_PATH":
+		s.HealthCheck.Path = value
+		return nil
+	case "PID_FILE":
+		s.PIDFile = value
+		return nil
+	case "LOG_FILE":
+		s.LogFile = value
+		return nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("not an integer")
+	}
+	switch setting {
+	case "PORT":
+		s.PreferredPort = n
+		s.PortPinned = true
+	case "RANGE_MIN":
+		s.Range.Min = n
+	case "RANGE_MAX":
+		s.Range.Max = n
+	case "HEALTH_TIMEOUT":
+		s.HealthCheck.TimeoutMs = n
+	case "HEALTH_RETRIES":
+		s.HealthCheck.Retries = n
+	case "HEALTH_INTERVAL":
+		s.HealthCheck.IntervalMs = n
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package management
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fulmenhq/crucible/foundry"
+)
+
+func TestDefaultConfig(t *testing.T) {
+	cfg, err := DefaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.EnvPrefix != "FULMEN_APP" {
+		t.Errorf("envPrefix %q", cfg.EnvPrefix)
+	}
+	want := []string{"a11y", "dev", "preview", "prod_like", "test"}
+	if got := cfg.Classes(); len(got) != len(want) {
+		t.Errorf("classes %v, want %v", got, want)
+	}
+	for _, class := range cfg.Classes() {
+		want := ExitBehavior{PortInUse: foundry.ExitPortInUse, HealthCheckFailed: foundry.ExitHealthCheckFailed, StartupTimeout: foundry.ExitTimeout}
+		if got := cfg.Configurations[class].ExitBehavior; got != want {
+			t.Errorf("%s: exitBehavior %+v, want %+v", class, got, want)
+		}
+	}
+	test := cfg.Configurations["test"]
+	if test.PreferredPort != 4380 || test.Range != (PortRange{4380, 4389}) || test.HealthCheck.Path != "/health" || test.PIDFile != ".server/test.pid" {
+		t.Errorf("test class %+v", test)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	cfg, err := LoadConfig([]byte(`
+envPrefix: FULMEN_PULSAR
+configurations:
+  dev:
+    preferredPort: 4321
+    range: {min: 4321, max: 4322}
+    healthCheck: {path: /}
+    envOverrides: [FULMEN_PULSAR_DEV_PORT]
+additionalConfigurations:
+  x-storybook:
+    preferredPort: 6006
+    range: {min: 6006, max: 6010}
+    healthCheck: {path: /, retries: 0}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("FULMEN_PULSAR_DEV_PORT", "9000")
+	t.Setenv("FULMEN_PULSAR_DEV_HEALTH_PATH", "/ignored")
+	dev, err := cfg.Resolve("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dev.PreferredPort != 9000 || !dev.PortPinned || dev.HealthCheck.Path != "/" || dev.Class != "dev" || dev.EnvPrefix != "FULMEN_PULSAR" {
+		t.Errorf("dev %+v", dev)
+	}
+	if cfg.Configurations["dev"].PreferredPort != 4321 {
+		t.Error("Resolve modified the configuration")
+	}
+
+	t.Setenv("FULMEN_PULSAR_STORYBOOK_HEALTH_PATH", "/iframe.html")
+	t.Setenv("FULMEN_PULSAR_STORYBOOK_RANGE_MAX", "6020")
+	sb, err := cfg.Resolve("x-storybook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := HealthCheck{Method: "GET", Path: "/iframe.html", TimeoutMs: 5000, Retries: 0, IntervalMs: 1000}
+	if sb.HealthCheck != want || sb.Range.Max != 6020 || sb.ExitBehavior.StartupTimeout != foundry.ExitTimeout {
+		t.Errorf("x-storybook %+v", sb)
+	}
+
+	for name, value := range map[string]string{
+		"FULMEN_PULSAR_STORYBOOK_RANGE_MIN":      "low",
+		"FULMEN_PULSAR_STORYBOOK_HEALTH_PATH":    "health",
+		"FULMEN_PULSAR_STORYBOOK_RANGE_MAX":      "6000",
+		"FULMEN_PULSAR_STORYBOOK_HEALTH_RETRIES": "99",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			_, err := cfg.Resolve("x-storybook")
+			var e *Error
+			if !errors.As(err, &e) || e.Code != CodeInvalidConfig || e.ExitCode() != foundry.ExitConfigInvalid {
+				t.Errorf("expected an invalid config error, got %v", err)
+			}
+		})
+	}
+
+	if _, err := cfg.Resolve("staging"); !errors.Is(err, ErrUnknownClass) {
+		t.Errorf("unknown class: %v", err)
+	}
+
+	// envOverrides select settings whatever their prefix, as the defaults'
+	// FULMEN_APP_ names do under another envPrefix.
+	cfg.Configurations["dev"].EnvOverrides = []string{"FULMEN_APP_DEV_PORT"}
+	if dev, err := cfg.Resolve("dev"); err != nil || dev.PreferredPort != 9000 || dev.HealthCheck.Path != "/" {
+		t.Errorf("dev with FULMEN_APP_ overrides: %+v, %v", dev, err)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	for name, doc := range map[string]string{
+		"schema":          `configurations: {dev: {preferredPort: 80, range: {min: 80, max: 81}, healthCheck: {path: /}}}`,
+		"preferred port":  `configurations: {dev: {preferredPort: 5000, range: {min: 4321, max: 4322}, healthCheck: {path: /}}}`,
+		"empty range":     `configurations: {dev: {preferredPort: 4321, range: {min: 4322, max: 4321}, healthCheck: {path: /}}}`,
+		"unknown section": `configurations: {staging: {preferredPort: 4321, range: {min: 4321, max: 4322}, healthCheck: {path: /}}}`,
+		"exit code name":  `configurations: {dev: {preferredPort: 4321, range: {min: 4321, max: 4322}, healthCheck: {path: /}, exitBehavior: {portInUse: 11}}}`,
+		"unknown exit":    `configurations: {dev: {preferredPort: 4321, range: {min: 4321, max: 4322}, healthCheck: {path: /}, exitBehavior: {startupTimeout: 52}}}`,
+		"env override":    `configurations: {dev: {preferredPort: 4321, range: {min: 4321, max: 4322}, healthCheck: {path: /}, envOverrides: [FULMEN_APP_TEST_PORT]}}`,
+	} {
+		if _, err := LoadConfig([]byte(doc)); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%s: expected an invalid config error, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package management
+
+import (
+	"fmt"
+
+	"github.com/fulmenhq/crucible/errorsx"
+	"github.com/fulmenhq/crucible/foundry"
+)
+
+// ErrorCode is a server management error code.
+type ErrorCode string
+
+const (
+	CodeInvalidConfig          ErrorCode = "INVALID_CONFIG"
+	CodeUnknownClass           ErrorCode = "UNKNOWN_CLASS"
+	CodePortInUse              ErrorCode = "PORT_IN_USE"
+	CodePortRangeExhausted     ErrorCode = "PORT_RANGE_EXHAUSTED"
+	CodeInstanceAlreadyRunning ErrorCode = "INSTANCE_ALREADY_RUNNING"
+	CodePIDFile                ErrorCode = "PID_FILE"
+	CodeHealthCheckFailed      ErrorCode = "HEALTH_CHECK_FAILED"
+	CodeStartupTimeout         ErrorCode = "STARTUP_TIMEOUT"
+)
+
+// Sentinel errors for use with errors.Is; they match any Error with the same code.
+var (
+	ErrInvalidConfig          = NewError(CodeInvalidConfig, "", "")
+	ErrUnknownClass           = NewError(CodeUnknownClass, "", "")
+	ErrPortInUse              = NewError(CodePortInUse, "", "")
+	ErrPortRangeExhausted     = NewError(CodePortRangeExhausted, "", "")
+	ErrInstanceAlreadyRunning = NewError(CodeInstanceAlreadyRunning, "", "")
+	ErrPIDFile                = NewError(CodePIDFile, "", "")
+	ErrHealthCheckFailed      = NewError(CodeHealthCheckFailed, "", "")
+	ErrStartupTimeout         = NewError(CodeStartupTimeout, "", "")
+)
+
+// Error is a server management failure.
+type Error struct {
+	errorsx.Coded[ErrorCode]
+	Class string // Configuration class, if applicable
+	Port  int    // Port involved, if applicable
+	PID   int    // Process recorded in the PID file, if applicable
+
+	exitCode int
+}
+
+// NewError creates an Error for the given configuration class.
+func NewError(code ErrorCode, class, message string) *Error {
+	return &Error{Coded: errorsx.Coded[ErrorCode]{Code: code, Message: message}, Class: class}
+}
+
+// Wrap sets the underlying cause and returns the error for chaining.
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+// withExitCode sets the exit code, typically from the class's exitBehavior.
+func (e *Error) withExitCode(code int) *Error {
+	e.exitCode = code
+	return e
+}
+
+func (e *Error) Error() string {
+	return e.Text(e.Class, "")
+}
+
+// ExitCode returns the exit code the process should terminate with: the
+// one the class's exitBehavior configures for the failure, or the Foundry
+// code for it.
+func (e *Error) ExitCode() int {
+	if e.exitCode != 0 {
+		return e.exitCode
+	}
+	switch e.Code {
+	case CodeInvalidConfig:
+		return foundry.ExitConfigInvalid
+	case CodeUnknownClass:
+		return foundry.ExitInvalidArgument
+	case CodePortInUse:
+		return foundry.ExitPortInUse
+	case CodePortRangeExhausted:
+		return foundry.ExitPortRangeExhausted
+	case CodeInstanceAlreadyRunning:
+		return foundry.ExitInstanceAlreadyRunning
+	case CodePIDFile:
+		return foundry.ExitFileWriteError
+	case CodeHealthCheckFailed:
+		return foundry.ExitHealthCheckFailed
+	case CodeStartupTimeout:
+		return foundry.ExitTimeout
+	default:
+		return foundry.ExitFailure
+	}
+}
+
+func invalidConfig(class, format string, args ...any) *Error {
+	return NewError(CodeInvalidConfig, class, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,129 @@
+package management
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	nethttp "net/http"
+	"strconv"
+	"time"
+
+	"github.com/fulmenhq/crucible"
+	"github.com/fulmenhq/crucible/protocol/http"
+)
+
+// maxHealthBody bounds the health response body WaitHealthy reads.
+const maxHealthBody = 1 << 20
+
+// HealthReport is the outcome of the last health check attempt.
+type HealthReport struct {
+	URL        string
+	Attempts   int
+	StatusCode int                  // HTTP status, or 0 when the request failed
+	Status     http.Status          // From the health response, else pass for 2xx and fail otherwise
+	Checks     []http.CheckResult   // Per-check breakdown from the health response
+	Response   *http.HealthResponse // Nil when the endpoint did not return a JSON health response
+}
+
+// Healthy reports whether the attempt succeeded: a 2xx response whose
+// health status, if any, is pass or warn.
+func (r *HealthReport) Healthy() bool {
+	return r.StatusCode >= 200 && r.StatusCode <= 299 && r.Status != http.StatusFail
+}
+
+// WaitHealthy polls the health check of class on port of localhost until it
+// reports healthy, and returns the report of the last attempt (nil when ctx
+// ended before the first).
+//
+// It makes up to healthCheck.retries+1 requests with the configured method
+// and path, each bounded by healthCheck.timeout and spaced by
+// healthCheck.interval. A JSON body must be a valid health-response
+// document; its status and checks are reported. Other bodies are ignored
+// and the HTTP status decides.
+//
+// When every attempt fails the error is HEALTH_CHECK_FAILED with the
+// exitBehavior.healthCheckFailed exit code. When ctx ends first it is
+// STARTUP_TIMEOUT with exitBehavior.startupTimeout.
+func WaitHealthy(ctx context.Context, class *ServerConfig, port int) (*HealthReport, error) {
+	hc := class.HealthCheck
+	url := "http://" + net.JoinHostPort("localhost", strconv.Itoa(port)) + hc.Path
+	timeout := time.Duration(hc.TimeoutMs) * time.Millisecond
+	interval := time.Duration(hc.IntervalMs) * time.Millisecond
+
+	var report *HealthReport
+	var lastErr error
+	for attempt := 1; attempt <= hc.Retries+1; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(interval):
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		report, lastErr = probe(ctx, hc.Method, url, timeout)
+		report.Attempts = attempt
+		if lastErr == nil && report.Healthy() {
+			return report, nil
+		}
+	}
+
+	var e *Error
+	if err := ctx.Err(); err != nil {
+		e = NewError(CodeStartupTimeout, class.Class, url+" not healthy before the deadline").Wrap(err).withExitCode(class.ExitBehavior.StartupTimeout)
+	} else {
+		msg := fmt.Sprintf("%s unhealthy after %d attempts", url, report.Attempts)
+		if lastErr == nil {
+			msg += fmt.Sprintf(" (HTTP %d, status %s)", report.StatusCode, report.Status)
+		}
+		e = NewError(CodeHealthCheckFailed, class.Class, msg).Wrap(lastErr).withExitCode(class.ExitBehavior.HealthCheckFailed)
+	}
+	e.Port = port
+	return report, e
+}
+
+// probe makes one health check request.
+func probe(ctx context.Context, method, url string, timeout time.Duration) (*HealthReport, error) {
+	report := &HealthReport{URL: url, Status: http.StatusFail}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := nethttp.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return report, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		return report, err
+	}
+	defer resp.Body.Close()
+	report.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		report.Status = http.StatusPass
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHealthBody))
+	if err != nil {
+		return report, err
+	}
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt != "application/json" || len(bytes.TrimSpace(body)) == 0 {
+		return report, nil
+	}
+	if err := crucible.ValidateSchemaData(http.HealthSchemaPath, body); err != nil {
+		return report, fmt.Errorf("invalid health response: %w", err)
+	}
+	var hr http.HealthResponse
+	if err := json.Unmarshal(body, &hr); err != nil {
+		return report, fmt.Errorf("invalid health response: %w", err)
+	}
+	report.Response = &hr
+	report.Status = hr.Status
+	report.Checks = hr.Checks
+	return report, nil
+}
@@ -0,0 +1,103 @@
+package management
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fulmenhq/crucible/foundry"
+	"github.com/fulmenhq/crucible/protocol/http"
+)
+
+// serveHealth serves h and returns a class probing path on it and the port.
+func serveHealth(t *testing.T, h nethttp.Handler, method, path string, retries int) (*ServerConfig, int) {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.Atoi(u.Port())
+	return &ServerConfig{
+		Class:       "test",
+		HealthCheck: HealthCheck{Method: method, Path: path, TimeoutMs: 1000, Retries: retries, IntervalMs: 1},
+		ExitBehavior: ExitBehavior{
+			PortInUse:         foundry.ExitPortInUse,
+			HealthCheckFailed: foundry.ExitHealthCheckFailed,
+			StartupTimeout:    foundry.ExitTimeout,
+		},
+	}, port
+}
+
+func TestWaitHealthy(t *testing.T) {
+	var ready atomic.Int32
+	health := http.NewHealth("jobs")
+	health.AddCheck(http.ProbeReady, "database", 0, func(context.Context) error {
+		if ready.Add(1) < 3 {
+			return errors.New("connecting")
+		}
+		return nil
+	})
+	health.AddCheck(http.ProbeReady, "queue", 0, func(context.Context) error { return http.Degraded(errors.New("lag")) })
+	mux := nethttp.NewServeMux()
+	health.Mount(mux)
+
+	class, port := serveHealth(t, mux, "GET", "/health/ready", 3)
+	report, err := WaitHealthy(context.Background(), class, port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Attempts != 3 || report.StatusCode != 200 || report.Status != http.StatusWarn || report.Response == nil || report.Response.Service != "jobs" {
+		t.Errorf("report %+v", report)
+	}
+	if len(report.Checks) != 2 || report.Checks[0].Name != "database" || report.Checks[0].Status != http.StatusPass || report.Checks[1].Status != http.StatusWarn {
+		t.Errorf("checks %+v", report.Checks)
+	}
+
+	ready.Store(-100)
+	class.HealthCheck.Retries = 1
+	report, err = WaitHealthy(context.Background(), class, port)
+	var e *Error
+	if !errors.As(err, &e) || e.Code != CodeHealthCheckFailed || e.ExitCode() != foundry.ExitHealthCheckFailed || e.Port != port {
+		t.Fatalf("failing check: %v", err)
+	}
+	if report.Attempts != 2 || report.StatusCode != 503 || report.Status != http.StatusFail || report.Checks[0].Details["error"] != "connecting" {
+		t.Errorf("failing report %+v", report)
+	}
+}
+
+func TestWaitHealthyResponses(t *testing.T) {
+	class, port := serveHealth(t, nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.Write([]byte("<html>ok</html>"))
+	}), "HEAD", "/", 0)
+	if report, err := WaitHealthy(context.Background(), class, port); err != nil || report.Status != http.StatusPass || report.Response != nil {
+		t.Errorf("plain page: %+v, %v", report, err)
+	}
+
+	class, port = serveHealth(t, nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+	}), "GET", "/health", 0)
+	if _, err := WaitHealthy(context.Background(), class, port); !errors.Is(err, ErrHealthCheckFailed) {
+		t.Errorf("invalid health response: %v", err)
+	}
+
+	class, port = serveHealth(t, nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}), "GET", "/health", 5)
+	class.ExitBehavior.StartupTimeout = 124
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := WaitHealthy(ctx, class, port)
+	var e *Error
+	if !errors.As(err, &e) || e.Code != CodeStartupTimeout || e.ExitCode() != 124 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("deadline: %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package management
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// WritePID writes pid to path, creating its directory.
+func WritePID(path string, pid int) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(strconv.Itoa(pid)+"\n"), 0o644)
+}
+
+// ReadPID returns the process ID recorded in path.
+func ReadPID(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	pid, ok := parsePID(data)
+	if !ok {
+		return 0, fmt.Errorf("%s does not contain a process ID", path)
+	}
+	return pid, nil
+}
+
+func parsePID(data []byte) (int, bool) {
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid, err == nil && pid > 0
+}
+
+// RemovePID removes path; a missing file is not an error.
+func RemovePID(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// ProcessAlive reports whether a process with pid exists.
+func ProcessAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	switch {
+	case err == nil, errors.Is(err, syscall.EPERM):
+		return true
+	case runtime.GOOS == "windows":
+		// FindProcess fails for missing processes on Windows, where
+		// signal 0 is not supported.
+		return !errors.Is(err, os.ErrProcessDone)
+	default:
+		return false
+	}
+}
+
+// PIDPath returns the class's PID file path under Root, or "" when the
+// class has no PID file.
+func (s *ServerConfig) PIDPath() string {
+	if s.PIDFile == "" {
+		return ""
+	}
+	return filepath.Join(s.Root, s.PIDFile)
+}
+
+// pidWriteGrace is how long a PID file that holds no process ID is presumed
+// to be being written by an instance that has just created it.
+const pidWriteGrace = time.Second
+
+// AcquirePID records the current process in the class's PID file. It fails
+// with INSTANCE_ALREADY_RUNNING (foundry.ExitInstanceAlreadyRunning) when
+// the file names another live process; a stale file is replaced. Classes
+// without a PID file always succeed.
+//
+// The file is created exclusively, so of several instances starting
+// together exactly one acquires it. A file is stale when its process is
+// gone, or when it has held no process ID for longer than a second; it is
+// removed under a takeover lock, path+".lock", so that only the stale file
+// is removed and never one another instance has just created.
+func (s *ServerConfig) AcquirePID() error {
+	path := s.PIDPath()
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return NewError(CodePIDFile, s.Class, "failed to create the directory of "+path).Wrap(err)
+	}
+	// Each wait on another instance's takeover lock lasts pidWriteGrace/100,
+	// so the attempts outlast a lock abandoned by a crash.
+	for range 200 {
+		err := createPID(path, os.Getpid())
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return NewError(CodePIDFile, s.Class, "failed to write "+path).Wrap(err)
+		}
+
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // Released since
+		}
+		if err != nil {
+			return NewError(CodePIDFile, s.Class, "failed to read "+path).Wrap(err)
+		}
+		pid, ok := parsePID(data)
+		switch {
+		case ok && pid == os.Getpid():
+			return nil
+		case ok && ProcessAlive(pid):
+			e := NewError(CodeInstanceAlreadyRunning, s.Class, fmt.Sprintf("process %d holds %s", pid, path))
+			e.PID = pid
+			return e
+		case !ok:
+			if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) < pidWriteGrace {
+				return NewError(CodeInstanceAlreadyRunning, s.Class, "another instance is writing "+path)
+			}
+		}
+		if err := takeOver(path, data); err != nil {
+			return NewError(CodePIDFile, s.Class, "failed to remove stale "+path).Wrap(err)
+		}
+	}
+	return NewError(CodePIDFile, s.Class, path+" kept changing while acquiring it")
+}
+
+// createPID creates path holding pid, failing with fs.ErrExist when it
+// exists.
+func createPID(path string, pid int) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(strconv.Itoa(pid) + "\n")
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+// takeOver removes the stale PID file at path, read as data, under a
+// takeover lock: path+".lock", created exclusively like the PID file. The
+// file is read again under the lock and only removed if unchanged, so of
+// several instances replacing the same stale file, later ones leave alone
+// the file the first one created. While another instance holds the lock it
+// waits briefly and returns; a lock older than pidWriteGrace was left by a
+// crash and is broken.
+func takeOver(path string, data []byte) error {
+	lock := path + ".lock"
+	f, err := os.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > pidWriteGrace {
+			return RemovePID(lock)
+		}
+		time.Sleep(pidWriteGrace / 100)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	f.Close()
+	defer os.Remove(lock)
+	if cur, err := os.ReadFile(path); err == nil && bytes.Equal(cur, data) {
+		return RemovePID(path)
+	}
+	return nil
+}
+
+// ReleasePID removes the class's PID file when it names the current
+// process.
+func (s *ServerConfig) ReleasePID() error {
+	path := s.PIDPath()
+	if path == "" {
+		return nil
+	}
+	if pid, err := ReadPID(path); err != nil || pid != os.Getpid() {
+		return nil
+	}
+	if err := RemovePID(path); err != nil {
+		return NewError(CodePIDFile, s.Class, "failed to remove "+path).Wrap(err)
+	}
+	return nil
+}
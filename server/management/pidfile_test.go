@@ -0,0 +1,185 @@
+package management
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fulmenhq/crucible/foundry"
+)
+
+func TestAcquirePID(t *testing.T) {
+	s := &ServerConfig{Class: "dev", PIDFile: ".server/dev.pid", Root: t.TempDir()}
+	path := filepath.Join(s.Root, ".server", "dev.pid")
+	if s.PIDPath() != path {
+		t.Fatalf("PIDPath %s", s.PIDPath())
+	}
+
+	if err := s.AcquirePID(); err != nil {
+		t.Fatal(err)
+	}
+	if pid, err := ReadPID(path); err != nil || pid != os.Getpid() {
+		t.Fatalf("PID file holds %d, %v", pid, err)
+	}
+	if err := s.AcquirePID(); err != nil {
+		t.Errorf("reacquiring own PID file: %v", err)
+	}
+
+	WritePID(path, os.Getppid())
+	err := s.AcquirePID()
+	var e *Error
+	if !errors.As(err, &e) || e.Code != CodeInstanceAlreadyRunning || e.ExitCode() != foundry.ExitInstanceAlreadyRunning || e.PID != os.Getppid() {
+		t.Errorf("live instance: %v", err)
+	}
+	if err := s.ReleasePID(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Error("ReleasePID removed another process's PID file")
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	WritePID(path, cmd.Process.Pid)
+	if err := s.AcquirePID(); err != nil {
+		t.Errorf("stale PID file: %v", err)
+	}
+	if err := s.ReleasePID(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("PID file not released: %v", err)
+	}
+
+	// A file without a process ID may be mid-write by another instance, and
+	// is only stale after a grace period.
+	os.WriteFile(path, nil, 0o644)
+	if err := s.AcquirePID(); !errors.Is(err, ErrInstanceAlreadyRunning) {
+		t.Errorf("PID file being written: %v", err)
+	}
+	os.WriteFile(path, []byte("garbage"), 0o644)
+	old := time.Now().Add(-2 * pidWriteGrace)
+	os.Chtimes(path, old, old)
+	if err := s.AcquirePID(); err != nil {
+		t.Errorf("unreadable PID file: %v", err)
+	}
+	if pid, err := ReadPID(path); err != nil || pid != os.Getpid() {
+		t.Errorf("PID file holds %d, %v", pid, err)
+	}
+}
+
+func TestTakeOver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dev.pid")
+	WritePID(path, 42)
+	if err := takeOver(path, []byte("42\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("stale PID file kept: %v", err)
+	}
+	if err := takeOver(path, []byte("42\n")); err != nil {
+		t.Errorf("PID file already removed: %v", err)
+	}
+
+	// Another instance replaced the stale file after it was read.
+	WritePID(path, os.Getppid())
+	if err := takeOver(path, []byte("42\n")); err != nil {
+		t.Fatal(err)
+	}
+	if pid, err := ReadPID(path); err != nil || pid != os.Getppid() {
+		t.Errorf("replaced PID file removed: %d, %v", pid, err)
+	}
+
+	// Another instance holds the lock; once it is abandoned it is broken.
+	WritePID(path, 42)
+	os.WriteFile(path+".lock", nil, 0o644)
+	if err := takeOver(path, []byte("42\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Error("PID file removed without the lock")
+	}
+	old := time.Now().Add(-2 * pidWriteGrace)
+	os.Chtimes(path+".lock", old, old)
+	takeOver(path, []byte("42\n"))
+	takeOver(path, []byte("42\n"))
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 0 {
+		t.Errorf("left files behind: %v", entries)
+	}
+}
+
+// TestAcquirePIDHelper runs in the subprocesses of TestAcquirePIDConcurrent:
+// it acquires the PID file PIDFILE_HELPER names, prints the outcome and
+// holds the file until stdin closes.
+func TestAcquirePIDHelper(t *testing.T) {
+	path := os.Getenv("PIDFILE_HELPER")
+	if path == "" {
+		t.Skip("helper process")
+	}
+	s := &ServerConfig{Class: "dev", PIDFile: filepath.Base(path), Root: filepath.Dir(path)}
+	if err := s.AcquirePID(); err != nil {
+		fmt.Println("busy:", err)
+	} else {
+		fmt.Println("acquired")
+	}
+	io.ReadAll(os.Stdin)
+}
+
+func TestAcquirePIDConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dev.pid")
+	dead := exec.Command(os.Args[0], "-test.run=^$")
+	if err := dead.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, stale := range []bool{false, true} {
+		RemovePID(path)
+		if stale {
+			WritePID(path, dead.Process.Pid)
+		}
+		type helper struct {
+			cmd    *exec.Cmd
+			stdin  io.Closer
+			stdout *bufio.Reader
+		}
+		var helpers []helper
+		for range 8 {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestAcquirePIDHelper$")
+			cmd.Env = append(os.Environ(), "PIDFILE_HELPER="+path)
+			stdin, _ := cmd.StdinPipe()
+			stdout, _ := cmd.StdoutPipe()
+			if err := cmd.Start(); err != nil {
+				t.Fatal(err)
+			}
+			helpers = append(helpers, helper{cmd, stdin, bufio.NewReader(stdout)})
+		}
+
+		// Every helper holds on to its outcome until its stdin closes.
+		acquired := 0
+		for _, h := range helpers {
+			line, _ := h.stdout.ReadString('\n')
+			switch {
+			case line == "acquired\n":
+				acquired++
+			case !strings.HasPrefix(line, "busy: ") || !strings.Contains(line, "INSTANCE_ALREADY_RUNNING"):
+				t.Errorf("stale %v: helper printed %q", stale, line)
+			}
+		}
+		for _, h := range helpers {
+			h.stdin.Close()
+			h.cmd.Wait()
+		}
+		if acquired != 1 {
+			t.Errorf("stale %v: %d instances acquired the PID file, want 1", stale, acquired)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package management
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// listen binds a TCP port on every interface; tests replace it.
+var listen = func(port int) (net.Listener, error) {
+	return net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(port)))
+}
+
+// Listen binds the class's port: the preferred port, or else the first free
+// port in the range. Binding (rather than probing) keeps another process
+// from taking the port before the server uses it.
+//
+// A pinned port (set by the PORT override) is never replaced; when it is
+// busy the error is PORT_IN_USE with the exitBehavior.portInUse exit code.
+// When every port of the range is busy the error is PORT_RANGE_EXHAUSTED
+// with foundry.ExitPortRangeExhausted.
+func (s *ServerConfig) Listen() (net.Listener, error) {
+	ln, err := listen(s.PreferredPort)
+	if err == nil {
+		return ln, nil
+	}
+	if s.PortPinned {
+		e := NewError(CodePortInUse, s.Class, fmt.Sprintf("port %d is not available", s.PreferredPort)).Wrap(err).withExitCode(s.ExitBehavior.PortInUse)
+		e.Port = s.PreferredPort
+		return nil, e
+	}
+	for port := s.Range.Min; port <= s.Range.Max; port++ {
+		if port == s.PreferredPort {
+			continue
+		}
+		if ln, err := listen(port); err == nil {
+			return ln, nil
+		}
+	}
+	return nil, NewError(CodePortRangeExhausted, s.Class, fmt.Sprintf("no free port in %d-%d", s.Range.Min, s.Range.Max))
+}
+
+// FindAvailablePort returns the port Listen would bind, releasing it. Prefer
+// Listen when the caller serves the port itself.
+func (s *ServerConfig) FindAvailablePort() (int, error) {
+	ln, err := s.Listen()
+	if err != nil {
+		return 0, err
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port, nil
+}
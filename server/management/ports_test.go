@@ -0,0 +1,80 @@
+package management
+
+import (
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/fulmenhq/crucible/foundry"
+)
+
+// fakeListen makes the ports in busy unavailable and binds the others to an
+// ephemeral port, reporting the requested one.
+func fakeListen(t *testing.T, busy ...int) {
+	t.Helper()
+	orig := listen
+	t.Cleanup(func() { listen = orig })
+	listen = func(port int) (net.Listener, error) {
+		for _, b := range busy {
+			if b == port {
+				return nil, syscall.EADDRINUSE
+			}
+		}
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, err
+		}
+		return portListener{ln, port}, nil
+	}
+}
+
+type portListener struct {
+	net.Listener
+	port int
+}
+
+func (l portListener) Addr() net.Addr { return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: l.port} }
+
+func TestFindAvailablePort(t *testing.T) {
+	s := &ServerConfig{Class: "test", PreferredPort: 4380, Range: PortRange{4380, 4383}, ExitBehavior: ExitBehavior{PortInUse: 77}}
+
+	fakeListen(t)
+	if port, err := s.FindAvailablePort(); err != nil || port != 4380 {
+		t.Errorf("free preferred port: %d, %v", port, err)
+	}
+
+	fakeListen(t, 4380, 4381)
+	if port, err := s.FindAvailablePort(); err != nil || port != 4382 {
+		t.Errorf("busy preferred port: %d, %v", port, err)
+	}
+
+	fakeListen(t, 4380, 4381, 4382, 4383)
+	_, err := s.FindAvailablePort()
+	var e *Error
+	if !errors.As(err, &e) || e.Code != CodePortRangeExhausted || e.ExitCode() != foundry.ExitPortRangeExhausted {
+		t.Errorf("exhausted range: %v", err)
+	}
+
+	pinned := *s
+	pinned.PreferredPort, pinned.PortPinned = 9000, true
+	fakeListen(t, 9000)
+	_, err = pinned.FindAvailablePort()
+	if !errors.As(err, &e) || e.Code != CodePortInUse || e.ExitCode() != 77 || e.Port != 9000 {
+		t.Errorf("busy pinned port: %v", err)
+	}
+}
+
+func TestListen(t *testing.T) {
+	busy, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Skip("cannot bind:", err)
+	}
+	defer busy.Close()
+	port := busy.Addr().(*net.TCPAddr).Port
+
+	s := &ServerConfig{PreferredPort: port, Range: PortRange{port, port}}
+	if _, err := s.Listen(); !errors.Is(err, ErrPortRangeExhausted) {
+		t.Errorf("expected the bound port to be unavailable, got %v", err)
+	}
+}
@@ -2,14 +2,18 @@ package crucible
 
 import (
 	"fmt"
+	"maps"
+	"os"
+	"slices"
 
 	"gopkg.in/yaml.v3"
 )
 
 type TerminalConfig struct {
-	Name      string          `yaml:"name"`
-	Detection DetectionConfig `yaml:"detection"`
-	Overrides OverrideConfig  `yaml:"overrides"`
+	Name         string             `yaml:"name"`
+	Detection    DetectionConfig    `yaml:"detection"`
+	Capabilities CapabilitiesConfig `yaml:"capabilities,omitempty"`
+	Overrides    OverrideConfig     `yaml:"overrides"`
 }
 
 type DetectionConfig struct {
@@ -17,6 +21,10 @@ type DetectionConfig struct {
 	TermProgram string            `yaml:"term_program,omitempty"`
 }
 
+type CapabilitiesConfig struct {
+	Color bool `yaml:"color,omitempty"`
+}
+
 type OverrideConfig struct {
 	EmojiWidth    int            `yaml:"emoji_width,omitempty"`
 	SpecificChars map[string]int `yaml:"specific_chars,omitempty"`
@@ -54,6 +62,38 @@ func GetTerminalConfig(name string) (*TerminalConfig, error) {
 	return config, nil
 }
 
+// DetectTerminal returns the catalog entry for the terminal the process is
+// running in: the first entry, by name, whose detection TERM_PROGRAM or
+// environment variables all match the environment.
+func DetectTerminal() (*TerminalConfig, error) {
+	catalog, err := LoadTerminalCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(catalog)) {
+		if config := catalog[name]; config.Detection.matches(os.Getenv) {
+			return config, nil
+		}
+	}
+	return nil, fmt.Errorf("terminal config not found for TERM_PROGRAM %q", os.Getenv("TERM_PROGRAM"))
+}
+
+func (d DetectionConfig) matches(getenv func(string) string) bool {
+	if d.TermProgram == "" && len(d.Env) == 0 {
+		return false
+	}
+	if d.TermProgram != "" && getenv("TERM_PROGRAM") != d.TermProgram {
+		return false
+	}
+	for k, v := range d.Env {
+		if getenv(k) != v {
+			return false
+		}
+	}
+	return true
+}
+
 func GetTerminalSchema() ([]byte, error) {
 	return SchemaRegistry.Terminal().V1_0_0()
 }